@@ -0,0 +1,273 @@
+package kml
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// ErrNoGeometry is returned when element has no geometries.
+var ErrNoGeometry = errors.New("no geometry")
+
+// Bounds represents longitude, latitude and altitude envelope. When West
+// is greater than East the bounds cross the antimeridian.
+type Bounds struct {
+	West, East     float64 // Longitudes in range -180 to 180.
+	South, North   float64 // Latitudes.
+	MinAlt, MaxAlt float64 // Altitudes in meters.
+}
+
+// CrossesAntimeridian returns true if bounds cross the antimeridian.
+func (b Bounds) CrossesAntimeridian() bool {
+	return b.West > b.East
+}
+
+// Width returns bounds width in degrees of longitude.
+func (b Bounds) Width() float64 {
+	if b.CrossesAntimeridian() {
+		return b.East - b.West + 360
+	}
+	return b.East - b.West
+}
+
+// Center returns coordinate of the bounds center.
+func (b Bounds) Center() Coord {
+	return Coord{
+		Lon: normLon(b.West + b.Width()/2),
+		Lat: (b.South + b.North) / 2,
+		Alt: (b.MinAlt + b.MaxAlt) / 2,
+	}
+}
+
+// earthRadius is the mean Earth radius in meters.
+const earthRadius = 6371008.8
+
+// LookAt returns LookAt element looking straight down at the bounds center
+// from range at which the bounds fit in 60 degrees field of view
+// increased by the maximum altitude. The range is at least 1000 meters.
+func (b Bounds) LookAt() *Element {
+	c := b.Center()
+	w := b.Width() * rad * earthRadius * math.Cos(c.Lat*rad)
+	h := (b.North - b.South) * rad * earthRadius
+	rng := math.Max(w, h) / (2 * math.Tan(30*rad))
+	return LookAt(
+		Longitude(c.Lon),
+		Latitude(c.Lat),
+		Altitude(0),
+		Heading(0),
+		Tilt(0),
+		Range(math.Max(rng+b.MaxAlt, 1000)),
+	)
+}
+
+// Region returns Region element with LatLonAltBox set to the bounds.
+// Altitudes are set only when they are not zero, the altitudeMode must
+// be added for them to take effect.
+func (b Bounds) Region() *Element {
+	box := LatLonAltBox(
+		North(b.North),
+		South(b.South),
+		East(b.East),
+		West(b.West),
+	)
+	if b.MinAlt != 0 || b.MaxAlt != 0 {
+		_ = box.AddChild(MinAltitude(b.MinAlt))
+		_ = box.AddChild(MaxAltitude(b.MaxAlt))
+	}
+	return Region(box)
+}
+
+// Bounds returns envelope of all geometries in the element tree rooted at
+// e. It uses coordinates elements (including coordinates of
+// gx:LatLonQuad), gx:coord elements of gx:Track, Model locations and
+// LatLonBox elements taking their rotation into account. Coordinates
+// without altitude have altitude of zero.
+//
+// Longitudes are wrapped to range -180 to 180 and the bounds are the
+// narrowest longitude range containing all of them so geometries near
+// the antimeridian produce bounds crossing it (West > East). Returns
+// ErrNoGeometry when there are no geometries.
+func (e *Element) Bounds() (Bounds, error) {
+	bb := &boundsBuilder{}
+	err := e.Walk(func(el *Element, _ int) error {
+		switch el.qualifiedName() {
+		case ElemCoordinates:
+			crs, err := el.Coords()
+			if err != nil {
+				return err
+			}
+			for _, c := range crs {
+				bb.add(c)
+			}
+			return SkipSubtree
+
+		case ElemGxCoord:
+			c, err := el.ContentGxCoord()
+			if err != nil {
+				return err
+			}
+			bb.add(c)
+			return SkipSubtree
+
+		case ElemLatLonBox:
+			return bb.latLonBox(el)
+
+		case ElemModel:
+			return bb.model(el)
+		}
+		return nil
+	})
+	if err != nil {
+		return Bounds{}, err
+	}
+	if len(bb.lons) == 0 {
+		return Bounds{}, ErrNoGeometry
+	}
+	return bb.bounds(), nil
+}
+
+// lonRange represents longitude range. West is not greater than East.
+type lonRange struct {
+	west, east float64
+}
+
+// boundsBuilder computes bounds.
+type boundsBuilder struct {
+	lons           []lonRange
+	south, north   float64
+	minAlt, maxAlt float64
+}
+
+// add extends bounds with coordinate.
+func (bb *boundsBuilder) add(c Coord) {
+	lon := normLon(c.Lon)
+	bb.extend(lon, lon, c.Lat, c.Lat, c.Alt)
+}
+
+// extend extends bounds with longitude, latitude range and altitude. The
+// longitude range crosses the antimeridian when west is greater than east.
+func (bb *boundsBuilder) extend(west, east, south, north, alt float64) {
+	if len(bb.lons) == 0 {
+		bb.south, bb.north = south, north
+		bb.minAlt, bb.maxAlt = alt, alt
+	}
+	bb.south = math.Min(bb.south, south)
+	bb.north = math.Max(bb.north, north)
+	bb.minAlt = math.Min(bb.minAlt, alt)
+	bb.maxAlt = math.Max(bb.maxAlt, alt)
+
+	if west > east {
+		bb.lons = append(bb.lons, lonRange{west, 180}, lonRange{-180, east})
+		return
+	}
+	bb.lons = append(bb.lons, lonRange{west, east})
+}
+
+// latLonBox extends bounds with LatLonBox element.
+func (bb *boundsBuilder) latLonBox(box *Element) error {
+	var vs [4]float64
+	for i, name := range []string{ElemNorth, ElemSouth, ElemEast, ElemWest} {
+		v, err := box.ChildFloat(name)
+		if err != nil {
+			return err
+		}
+		vs[i] = v
+	}
+	north, south, east, west := vs[0], vs[1], normLon(vs[2]), normLon(vs[3])
+
+	var rot float64
+	if box.ChildByName(ElemRotation) != nil {
+		var err error
+		if rot, err = box.ChildFloat(ElemRotation); err != nil {
+			return err
+		}
+	}
+
+	if rot == 0 {
+		bb.extend(west, east, south, north, 0)
+		return SkipSubtree
+	}
+
+	// Rotated box corners.
+	b := Bounds{West: west, East: east, South: south, North: north}
+	c := b.Center()
+	hw, hh := b.Width()/2, (north-south)/2
+	sin, cos := math.Sincos(rot * math.Pi / 180)
+	for _, d := range [][2]float64{{-hw, -hh}, {hw, -hh}, {hw, hh}, {-hw, hh}} {
+		bb.add(Coord{
+			Lon: c.Lon + d[0]*cos - d[1]*sin,
+			Lat: c.Lat + d[0]*sin + d[1]*cos,
+		})
+	}
+	return SkipSubtree
+}
+
+// model extends bounds with Model element location.
+func (bb *boundsBuilder) model(mod *Element) error {
+	loc := mod.ChildByName(ElemLocation)
+	if loc == nil {
+		return SkipSubtree
+	}
+	var c Coord
+	var err error
+	if c.Lon, err = loc.ChildFloat(ElemLongitude); err != nil {
+		return err
+	}
+	if c.Lat, err = loc.ChildFloat(ElemLatitude); err != nil {
+		return err
+	}
+	if loc.ChildByName(ElemAltitude) != nil {
+		if c.Alt, err = loc.ChildFloat(ElemAltitude); err != nil {
+			return err
+		}
+	}
+	bb.add(c)
+	return SkipSubtree
+}
+
+// bounds returns computed bounds. The longitude range is the complement
+// of the largest gap between longitude ranges.
+func (bb *boundsBuilder) bounds() Bounds {
+	b := Bounds{
+		South:  bb.south,
+		North:  bb.north,
+		MinAlt: bb.minAlt,
+		MaxAlt: bb.maxAlt,
+	}
+
+	lons := bb.lons
+	sort.Slice(lons, func(i, j int) bool { return lons[i].west < lons[j].west })
+	merged := lons[:1]
+	for _, r := range lons[1:] {
+		last := &merged[len(merged)-1]
+		if r.west <= last.east {
+			last.east = math.Max(last.east, r.east)
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	// Bounds not crossing the antimeridian are preferred on ties.
+	first, last := merged[0], merged[len(merged)-1]
+	b.West, b.East = first.west, last.east
+	gap := first.west + 360 - last.east
+	for i := 1; i < len(merged); i++ {
+		if g := merged[i].west - merged[i-1].east; g > gap {
+			gap = g
+			b.West, b.East = merged[i].west, merged[i-1].east
+		}
+	}
+	return b
+}
+
+// normLon returns longitude wrapped to range -180 to 180.
+func normLon(lon float64) float64 {
+	if lon >= -180 && lon <= 180 {
+		return lon
+	}
+	lon = math.Mod(lon+180, 360)
+	if lon < 0 {
+		lon += 360
+	}
+	return lon - 180
+}
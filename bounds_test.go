@@ -0,0 +1,151 @@
+package kml
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Element_Bounds(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		el  *Element
+		exp Bounds
+	}{
+		{"point", Point(Coordinates("1,2")),
+			Bounds{West: 1, East: 1, South: 2, North: 2}},
+		{"folder", Folder(
+			Placemark(Point(Coordinates("1,2,30"))),
+			Placemark(LineString(Coordinates("-5,-6,-10 10,20"))),
+		), Bounds{West: -5, East: 10, South: -6, North: 20, MinAlt: -10, MaxAlt: 30}},
+		{"antimeridian", LineString(Coordinates("170,1 179,2 -175,3")),
+			Bounds{West: 170, East: -175, South: 1, North: 3}},
+		{"wrapped", Point(Coordinates("190,1")),
+			Bounds{West: -170, East: -170, South: 1, North: 1}},
+		{"track", GxTrack(GxCoord("1 2 3"), GxCoord("4 5 6")),
+			Bounds{West: 1, East: 4, South: 2, North: 5, MinAlt: 3, MaxAlt: 6}},
+		{"lat lon box", GroundOverlay(LatLonBox(North(10), South(-10), East(-170), West(170))),
+			Bounds{West: 170, East: -170, South: -10, North: 10}},
+		{"rotated box", LatLonBox(North(1), South(-1), East(2), West(-2), Rotation(90)),
+			Bounds{West: -1, East: 1, South: -2, North: 2}},
+		{"lat lon quad", GroundOverlay(GxLatLonQuad(Coordinates("1,2 3,2 3,4 1,4"))),
+			Bounds{West: 1, East: 3, South: 2, North: 4}},
+		{"model", Model(Location(Longitude(1), Latitude(2), Altitude(3))),
+			Bounds{West: 1, East: 1, South: 2, North: 2, MinAlt: 3, MaxAlt: 3}},
+		{"world", Folder(
+			LatLonBox(North(1), South(0), East(10), West(-180)),
+			LatLonBox(North(1), South(0), East(-170), West(0)),
+		), Bounds{West: -180, East: 180, South: 0, North: 1}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			got, err := tc.el.Bounds()
+
+			// --- Then ---
+			require.NoError(t, err)
+			assert.InDelta(t, tc.exp.West, got.West, 1e-9)
+			assert.InDelta(t, tc.exp.East, got.East, 1e-9)
+			assert.InDelta(t, tc.exp.South, got.South, 1e-9)
+			assert.InDelta(t, tc.exp.North, got.North, 1e-9)
+			assert.Exactly(t, tc.exp.MinAlt, got.MinAlt)
+			assert.Exactly(t, tc.exp.MaxAlt, got.MaxAlt)
+		})
+	}
+}
+
+func Test_Element_Bounds_Errors(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		el  *Element
+		err error
+	}{
+		{"no geometry", Folder(Name("empty")), ErrNoGeometry},
+		{"coordinates", Point(Coordinates("1,a")), ErrInvalidCoordinates},
+		{"box", LatLonBox(North(1)), ErrMissingChild},
+		{"foreign coord", Folder(NewElementNS("http://example.com/ns", "coord")), ErrNoGeometry},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			got, err := tc.el.Bounds()
+
+			// --- Then ---
+			assert.Exactly(t, Bounds{}, got)
+			assert.True(t, errors.Is(err, tc.err), err)
+		})
+	}
+}
+
+func Test_Bounds_Center(t *testing.T) {
+	// --- Given ---
+	b := Bounds{West: 170, East: -170, South: 0, North: 10, MinAlt: 0, MaxAlt: 100}
+
+	// --- When ---
+	got := b.Center()
+
+	// --- Then ---
+	assert.True(t, b.CrossesAntimeridian())
+	assert.Exactly(t, 20.0, b.Width())
+	assert.Exactly(t, Coord{Lon: 180, Lat: 5, Alt: 50}, got)
+}
+
+func Test_Bounds_LookAt(t *testing.T) {
+	// --- Given ---
+	b := Bounds{West: -1, East: 1, South: -1, North: 1}
+
+	// --- When ---
+	got := b.LookAt()
+
+	// --- Then ---
+	assert.Nil(t, Validate(KML(Document(got))))
+	lon, err := got.ChildFloat(ElemLongitude)
+	require.NoError(t, err)
+	assert.Exactly(t, 0.0, lon)
+	rng, err := got.ChildFloat(ElemRange)
+	require.NoError(t, err)
+	assert.InDelta(t, 192600, rng, 100)
+
+	rng, err = Bounds{}.LookAt().ChildFloat(ElemRange)
+	require.NoError(t, err)
+	assert.Exactly(t, 1000.0, rng)
+}
+
+func Test_Bounds_Region(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		b   Bounds
+		exp string
+	}{
+		{"flat", Bounds{West: 170, East: -170, South: -1, North: 1},
+			"<Region><LatLonAltBox>" +
+				"<north>1</north><south>-1</south><east>-170</east><west>170</west>" +
+				"</LatLonAltBox></Region>"},
+		{"altitude", Bounds{West: 1, East: 2, South: 3, North: 4, MinAlt: 5, MaxAlt: 6},
+			"<Region><LatLonAltBox>" +
+				"<north>4</north><south>3</south><east>2</east><west>1</west>" +
+				"<minAltitude>5</minAltitude><maxAltitude>6</maxAltitude>" +
+				"</LatLonAltBox></Region>"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			got := tc.b.Region()
+
+			// --- Then ---
+			assert.Nil(t, Validate(KML(Document(got))))
+			data, err := xml.Marshal(got)
+			require.NoError(t, err)
+			assert.Exactly(t, tc.exp, string(data))
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package kml
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+)
+
+// ErrInvalidColor is returned when color cannot be parsed.
+var ErrInvalidColor = errors.New("invalid color")
+
+// KMLColor represents KML color. KML encodes colors as 8 hexadecimal digits
+// in aabbggrr byte order (alpha, blue, green, red). The color is not alpha
+// premultiplied.
+type KMLColor struct {
+	R, G, B, A uint8
+}
+
+// ParseKMLColor parses KML color in aabbggrr format.
+func ParseKMLColor(s string) (KMLColor, error) {
+	if len(s) != 8 {
+		return KMLColor{}, fmt.Errorf("%w: %q must have 8 hex digits", ErrInvalidColor, s)
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return KMLColor{}, fmt.Errorf("%w: %q is not hexadecimal", ErrInvalidColor, s)
+	}
+	return KMLColor{
+		A: uint8(v >> 24),
+		B: uint8(v >> 16),
+		G: uint8(v >> 8),
+		R: uint8(v),
+	}, nil
+}
+
+// MustParseKMLColor is like ParseKMLColor but panics on error.
+func MustParseKMLColor(s string) KMLColor {
+	c, err := ParseKMLColor(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// ParseWebColor parses web color in #rrggbb, #rrggbbaa or #rgb format.
+// The leading hash is optional. Colors without alpha are fully opaque.
+func ParseWebColor(s string) (KMLColor, error) {
+	hex := s
+	if len(hex) > 0 && hex[0] == '#' {
+		hex = hex[1:]
+	}
+
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return KMLColor{}, fmt.Errorf("%w: %q is not a web color", ErrInvalidColor, s)
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return KMLColor{}, fmt.Errorf("%w: %q is not hexadecimal", ErrInvalidColor, s)
+	}
+	return KMLColor{
+		R: uint8(v >> 24),
+		G: uint8(v >> 16),
+		B: uint8(v >> 8),
+		A: uint8(v),
+	}, nil
+}
+
+// KMLColorFrom converts any color to KMLColor. Colors which are not alpha
+// premultiplied (KMLColor and color.NRGBA) are converted without loss.
+func KMLColorFrom(c color.Color) KMLColor {
+	if kc, ok := c.(KMLColor); ok {
+		return kc
+	}
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return KMLColor{R: n.R, G: n.G, B: n.B, A: n.A}
+}
+
+// String returns color in KML aabbggrr format.
+func (c KMLColor) String() string {
+	return fmt.Sprintf("%02x%02x%02x%02x", c.A, c.B, c.G, c.R)
+}
+
+// Web returns color in #rrggbb format. Alpha is ignored.
+func (c KMLColor) Web() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// RGBA implements color.Color interface.
+func (c KMLColor) RGBA() (r, g, b, a uint32) {
+	return c.NRGBA().RGBA()
+}
+
+// NRGBA returns color as color.NRGBA.
+func (c KMLColor) NRGBA() color.NRGBA {
+	return color.NRGBA{R: c.R, G: c.G, B: c.B, A: c.A}
+}
+
+// Opacity returns color opacity in range [0, 1].
+func (c KMLColor) Opacity() float64 {
+	return float64(c.A) / 0xff
+}
+
+// WithAlpha returns copy of the color with alpha set to a.
+func (c KMLColor) WithAlpha(a uint8) KMLColor {
+	c.A = a
+	return c
+}
+
+// WithOpacity returns copy of the color with alpha set from opacity in
+// range [0, 1]. Values outside the range are clamped.
+func (c KMLColor) WithOpacity(opacity float64) KMLColor {
+	opacity = math.Max(0, math.Min(1, opacity))
+	c.A = uint8(math.Round(opacity * 0xff))
+	return c
+}
+
+// ContentColor returns element's content as KMLColor.
+func (e *Element) ContentColor() (KMLColor, error) {
+	s := e.trimmedContent()
+	c, err := ParseKMLColor(s)
+	if err != nil {
+		return KMLColor{}, fmt.Errorf("%s: %s: %w", e.pos, e.Path(), err)
+	}
+	return c, nil
+}
+
+// ChildColor returns content of the first child with local name as KMLColor.
+func (e *Element) ChildColor(name string) (KMLColor, error) {
+	ch, err := e.child(name)
+	if err != nil {
+		return KMLColor{}, err
+	}
+	return ch.ContentColor()
+}
@@ -0,0 +1,156 @@
+package kml
+
+import (
+	"errors"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseKMLColor(t *testing.T) {
+	// --- When ---
+	c, err := ParseKMLColor("7f0000FF")
+
+	// --- Then ---
+	require.NoError(t, err)
+	assert.Exactly(t, KMLColor{R: 0xff, G: 0, B: 0, A: 0x7f}, c)
+	assert.Exactly(t, "7f0000ff", c.String())
+	assert.Exactly(t, "#ff0000", c.Web())
+}
+
+func Test_ParseKMLColor_Errors(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		value string
+		exp   string
+	}{
+		{"too short", "ff0000", `invalid color: "ff0000" must have 8 hex digits`},
+		{"too long", "ff0000ff00", `invalid color: "ff0000ff00" must have 8 hex digits`},
+		{"not hex", "ff0000gg", `invalid color: "ff0000gg" is not hexadecimal`},
+		{"sign", "+f0000ff", `invalid color: "+f0000ff" is not hexadecimal`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			_, err := ParseKMLColor(tc.value)
+
+			// --- Then ---
+			assert.True(t, errors.Is(err, ErrInvalidColor))
+			assert.EqualError(t, err, tc.exp)
+		})
+	}
+}
+
+func Test_ParseWebColor(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		value string
+		exp   KMLColor
+	}{
+		{"rrggbb", "#ff8000", KMLColor{R: 0xff, G: 0x80, B: 0, A: 0xff}},
+		{"no hash", "ff8000", KMLColor{R: 0xff, G: 0x80, B: 0, A: 0xff}},
+		{"rrggbbaa", "#ff800080", KMLColor{R: 0xff, G: 0x80, B: 0, A: 0x80}},
+		{"rgb", "#f80", KMLColor{R: 0xff, G: 0x88, B: 0, A: 0xff}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			c, err := ParseWebColor(tc.value)
+
+			// --- Then ---
+			require.NoError(t, err)
+			assert.Exactly(t, tc.exp, c)
+		})
+	}
+}
+
+func Test_ParseWebColor_Errors(t *testing.T) {
+	// --- When ---
+	_, err := ParseWebColor("#ff80")
+
+	// --- Then ---
+	assert.True(t, errors.Is(err, ErrInvalidColor))
+	assert.EqualError(t, err, `invalid color: "#ff80" is not a web color`)
+}
+
+func Test_KMLColorFrom(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		value color.Color
+		exp   string
+	}{
+		{"RGBA opaque", color.RGBA{R: 0xff, G: 0x80, B: 0x01, A: 0xff}, "ff0180ff"},
+		{"RGBA premultiplied", color.RGBA{R: 0x80, G: 0, B: 0, A: 0x80}, "800000ff"},
+		{"NRGBA", color.NRGBA{R: 0x12, G: 0x34, B: 0x56, A: 0x78}, "78563412"},
+		{"gray", color.Gray{Y: 0x40}, "ff404040"},
+		{"KMLColor", KMLColor{R: 1, G: 2, B: 3, A: 4}, "04030201"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			c := KMLColorFrom(tc.value)
+
+			// --- Then ---
+			assert.Exactly(t, tc.exp, c.String())
+		})
+	}
+}
+
+func Test_KMLColor_RGBA(t *testing.T) {
+	// --- Given ---
+	c := KMLColor{R: 0xff, G: 0, B: 0, A: 0x80}
+
+	// --- When ---
+	rgba := color.RGBAModel.Convert(c).(color.RGBA)
+
+	// --- Then ---
+	assert.Exactly(t, color.RGBA{R: 0x80, G: 0, B: 0, A: 0x80}, rgba)
+	assert.Exactly(t, color.NRGBA{R: 0xff, G: 0, B: 0, A: 0x80}, c.NRGBA())
+}
+
+func Test_KMLColor_Alpha(t *testing.T) {
+	// --- Given ---
+	c := KMLColor{R: 0xff, A: 0xff}
+
+	// --- Then ---
+	assert.Exactly(t, 1.0, c.Opacity())
+	assert.Exactly(t, KMLColor{R: 0xff, A: 0x10}, c.WithAlpha(0x10))
+	assert.Exactly(t, KMLColor{R: 0xff, A: 0x80}, c.WithOpacity(0.5))
+	assert.Exactly(t, KMLColor{R: 0xff, A: 0}, c.WithOpacity(-1))
+	assert.Exactly(t, KMLColor{R: 0xff, A: 0xff}, c.WithOpacity(2))
+	assert.Exactly(t, KMLColor{R: 0xff, A: 0xff}, c, "receiver must not change")
+}
+
+func Test_ColorRGBA(t *testing.T) {
+	// --- When ---
+	el := LineStyle(
+		ColorRGBA(color.NRGBA{R: 0xff, G: 0x80, B: 0x00, A: 0xff}),
+		BgColorRGBA(MustParseKMLColor("80102030")),
+	)
+
+	// --- Then ---
+	assert.Exactly(t, "ff0080ff", string(el.ChildContent(ElemColor)))
+	got, err := el.ChildColor(ElemBgColor)
+	require.NoError(t, err)
+	assert.Exactly(t, KMLColor{R: 0x30, G: 0x20, B: 0x10, A: 0x80}, got)
+}
+
+func Test_Element_ContentColor_Invalid(t *testing.T) {
+	// --- Given ---
+	el := LabelStyle(Color("red"))
+
+	// --- When ---
+	_, err := el.ChildColor(ElemColor)
+
+	// --- Then ---
+	assert.True(t, errors.Is(err, ErrInvalidColor))
+	assert.EqualError(t, err, `0:0: /LabelStyle/color: invalid color: "red" must have 8 hex digits`)
+}
@@ -0,0 +1,382 @@
+package kml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Clone returns deep copy of the element. The copy has no parent and
+// shares no mutable state with the original. Source bytes kept by
+// ParseLossless are dropped when cloning element other than the root
+// because namespace prefixes used in the source may be declared on the
+// element ancestors.
+func (e *Element) Clone() *Element {
+	cl := e.clone()
+	if e.parent != nil {
+		cl.dropRaw()
+	}
+	return cl
+}
+
+// clone returns deep copy of the element.
+func (e *Element) clone() *Element {
+	cl := &Element{}
+	*cl = *e
+	cl.parent = nil
+	cl.se = e.se.Copy()
+	if e.content != nil {
+		cl.content = e.content.Copy()
+	}
+	cl.lead = copyTokens(e.lead)
+	cl.trail = copyTokens(e.trail)
+	cl.tail = copyTokens(e.tail)
+	if e.children != nil {
+		cl.children = make([]*Element, len(e.children))
+		for i, ch := range e.children {
+			cl.children[i] = ch.clone()
+			cl.children[i].parent = cl
+		}
+	}
+	return cl
+}
+
+// EqualOptions configures element comparison. The zero value compares
+// elements strictly.
+type EqualOptions struct {
+	// Ignore order of attributes.
+	IgnoreAttrOrder bool
+
+	// Ignore leading and trailing white space in content and attribute
+	// values and treat any sequence of white space characters as single
+	// space.
+	IgnoreWhitespace bool
+
+	// Compare numbers in content and attribute values by value so 1,
+	// 1.0 and 1e0 are equal. Values are split to numbers on white space
+	// and commas, so it works for coordinates as well.
+	IgnoreFloatFormat bool
+}
+
+// Equal returns true if element and other have the same names, attributes,
+// content and children. Element positions and source document formatting
+// are not compared.
+func (e *Element) Equal(other *Element, opts EqualOptions) bool {
+	if e == nil || other == nil {
+		return e == other
+	}
+	if !sameName(e.se.Name, other.se.Name) {
+		return false
+	}
+	if !opts.attrsEqual(e.se.Attr, other.se.Attr) {
+		return false
+	}
+	if !opts.valueEqual(string(e.content), string(other.content)) {
+		return false
+	}
+	if len(e.children) != len(other.children) {
+		return false
+	}
+	for i, ch := range e.children {
+		if !ch.Equal(other.children[i], opts) {
+			return false
+		}
+	}
+	return true
+}
+
+// attrsEqual returns true if attribute lists are equal.
+func (opts EqualOptions) attrsEqual(a, b []xml.Attr) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	if !opts.IgnoreAttrOrder {
+		for i := range a {
+			if a[i].Name != b[i].Name || !opts.valueEqual(a[i].Value, b[i].Value) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, atr := range a {
+		val, ok := attrByName(b, atr.Name)
+		if !ok || !opts.valueEqual(atr.Value, val) {
+			return false
+		}
+	}
+	return true
+}
+
+// valueEqual returns true if content or attribute values are equal.
+func (opts EqualOptions) valueEqual(a, b string) bool {
+	if opts.IgnoreWhitespace {
+		a = strings.Join(strings.Fields(a), " ")
+		b = strings.Join(strings.Fields(b), " ")
+	}
+	if a == b {
+		return true
+	}
+	if !opts.IgnoreFloatFormat {
+		return false
+	}
+
+	at, bt := splitValues(a), splitValues(b)
+	if len(at) != len(bt) {
+		return false
+	}
+	for i := range at {
+		if at[i] == bt[i] {
+			continue
+		}
+		af, aErr := strconv.ParseFloat(at[i], 64)
+		bf, bErr := strconv.ParseFloat(bt[i], 64)
+		if aErr != nil || bErr != nil || af != bf {
+			return false
+		}
+	}
+	return true
+}
+
+// splitValues splits s to values and separators (commas and white space
+// sequences).
+func splitValues(s string) []string {
+	var out []string
+	for len(s) > 0 {
+		n := 0
+		switch {
+		case s[0] == ',':
+			n = 1
+		case isSpace(s[0]):
+			for n < len(s) && isSpace(s[n]) {
+				n++
+			}
+		default:
+			for n < len(s) && s[n] != ',' && !isSpace(s[n]) {
+				n++
+			}
+		}
+		out = append(out, s[:n])
+		s = s[n:]
+	}
+	return out
+}
+
+// attrByName returns value of attribute with name.
+func attrByName(attrs []xml.Attr, name xml.Name) (string, bool) {
+	for _, atr := range attrs {
+		if atr.Name == name {
+			return atr.Value, true
+		}
+	}
+	return "", false
+}
+
+// DiffKind represents kind of difference reported by Diff.
+type DiffKind int
+
+// Difference kinds.
+const (
+	ElementAdded DiffKind = iota + 1
+	ElementRemoved
+	ContentChanged
+	AttributeAdded
+	AttributeRemoved
+	AttributeChanged
+)
+
+// String returns difference kind name.
+func (k DiffKind) String() string {
+	switch k {
+	case ElementAdded:
+		return "element added"
+	case ElementRemoved:
+		return "element removed"
+	case ContentChanged:
+		return "content changed"
+	case AttributeAdded:
+		return "attribute added"
+	case AttributeRemoved:
+		return "attribute removed"
+	case AttributeChanged:
+		return "attribute changed"
+	default:
+		return "DiffKind(" + strconv.Itoa(int(k)) + ")"
+	}
+}
+
+// Difference describes single difference between two element trees.
+type Difference struct {
+	Kind DiffKind
+
+	// Path of the element. For removed elements it's the path in the old
+	// tree, for other changes it's the path in the new tree.
+	Path string
+
+	// Attribute name for attribute changes.
+	Attr string
+
+	// Old and new content or attribute value. Empty for added and removed
+	// elements.
+	Old, New string
+}
+
+// String returns difference in human readable form.
+func (c Difference) String() string {
+	pth := c.Path
+	if c.Attr != "" {
+		pth += "@" + c.Attr
+	}
+	switch c.Kind {
+	case ElementAdded:
+		return "+ " + pth
+	case ElementRemoved:
+		return "- " + pth
+	case AttributeAdded:
+		return fmt.Sprintf("+ %s: %q", pth, c.New)
+	case AttributeRemoved:
+		return fmt.Sprintf("- %s: %q", pth, c.Old)
+	default:
+		return fmt.Sprintf("~ %s: %q -> %q", pth, c.Old, c.New)
+	}
+}
+
+// Diff returns changes needed to turn element tree a into element tree b
+// in document order. Children are matched by their names and IDs keeping
+// their order, unmatched children are reported as removed from a or added
+// to b. Changes of attribute order are not reported. Diff returns nil when
+// trees are equal. Roots with different names are reported as removed and
+// added.
+func Diff(a, b *Element, opts EqualOptions) []Difference {
+	if !sameName(a.se.Name, b.se.Name) {
+		return []Difference{
+			{Kind: ElementRemoved, Path: a.Path()},
+			{Kind: ElementAdded, Path: b.Path()},
+		}
+	}
+	var chs []Difference
+	diff(a, b, opts, &chs)
+	return chs
+}
+
+// diff appends differences between elements with the same name to chs.
+func diff(a, b *Element, opts EqualOptions, chs *[]Difference) {
+	pth := b.Path()
+
+	for _, atr := range a.se.Attr {
+		val, ok := attrByName(b.se.Attr, atr.Name)
+		switch {
+		case !ok:
+			*chs = append(*chs, Difference{Kind: AttributeRemoved, Path: pth, Attr: attrName(atr.Name), Old: atr.Value})
+		case !opts.valueEqual(atr.Value, val):
+			*chs = append(*chs, Difference{Kind: AttributeChanged, Path: pth, Attr: attrName(atr.Name), Old: atr.Value, New: val})
+		}
+	}
+	for _, atr := range b.se.Attr {
+		if _, ok := attrByName(a.se.Attr, atr.Name); !ok {
+			*chs = append(*chs, Difference{Kind: AttributeAdded, Path: pth, Attr: attrName(atr.Name), New: atr.Value})
+		}
+	}
+
+	if !opts.valueEqual(string(a.content), string(b.content)) {
+		*chs = append(*chs, Difference{Kind: ContentChanged, Path: pth, Old: string(a.content), New: string(b.content)})
+	}
+
+	for _, m := range matchChildren(a.children, b.children) {
+		switch {
+		case m.b == nil:
+			*chs = append(*chs, Difference{Kind: ElementRemoved, Path: m.a.Path()})
+		case m.a == nil:
+			*chs = append(*chs, Difference{Kind: ElementAdded, Path: m.b.Path()})
+		default:
+			diff(m.a, m.b, opts, chs)
+		}
+	}
+}
+
+// attrName returns attribute name for display.
+func attrName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	if p, ok := knownNamespaces[n.Space]; ok {
+		return p + ":" + n.Local
+	}
+	return "{" + n.Space + "}" + n.Local
+}
+
+// childMatch represents pair of matched children. One of the elements is
+// nil for unmatched children.
+type childMatch struct {
+	a, b *Element
+}
+
+// matchChildren matches children of two elements using the longest common
+// subsequence of their keys (name and ID). The result is ordered so
+// removed elements come before added elements at the same position.
+func matchChildren(as, bs []*Element) []childMatch {
+	key := func(el *Element) string {
+		n := el.se.Name
+		if isKMLNamespace(n.Space) {
+			n.Space = ""
+		}
+		return n.Space + " " + n.Local + " " + el.ID()
+	}
+
+	// Skip common prefix and suffix to keep the LCS table small for
+	// typical edits.
+	var pre, suf []childMatch
+	for len(as) > 0 && len(bs) > 0 && key(as[0]) == key(bs[0]) {
+		pre = append(pre, childMatch{as[0], bs[0]})
+		as, bs = as[1:], bs[1:]
+	}
+	for len(as) > 0 && len(bs) > 0 && key(as[len(as)-1]) == key(bs[len(bs)-1]) {
+		suf = append([]childMatch{{as[len(as)-1], bs[len(bs)-1]}}, suf...)
+		as, bs = as[:len(as)-1], bs[:len(bs)-1]
+	}
+
+	ak := make([]string, len(as))
+	for i, el := range as {
+		ak[i] = key(el)
+	}
+	bk := make([]string, len(bs))
+	for i, el := range bs {
+		bk[i] = key(el)
+	}
+
+	// lcs[i][j] is the length of LCS of ak[i:] and bk[j:].
+	lcs := make([][]int, len(ak)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bk)+1)
+	}
+	for i := len(ak) - 1; i >= 0; i-- {
+		for j := len(bk) - 1; j >= 0; j-- {
+			switch {
+			case ak[i] == bk[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	out := pre
+	i, j := 0, 0
+	for i < len(ak) || j < len(bk) {
+		switch {
+		case i < len(ak) && j < len(bk) && ak[i] == bk[j]:
+			out = append(out, childMatch{as[i], bs[j]})
+			i++
+			j++
+		case j == len(bk) || i < len(ak) && lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, childMatch{a: as[i]})
+			i++
+		default:
+			out = append(out, childMatch{b: bs[j]})
+			j++
+		}
+	}
+	return append(out, suf...)
+}
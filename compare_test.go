@@ -0,0 +1,221 @@
+package kml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	kit "github.com/rzajac/testkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// compareDoc returns document used in compare tests.
+func compareDoc() *Element {
+	return KML(
+		Document(
+			Name("doc"),
+			Placemark(
+				AttrID("pm_0"),
+				Name("first"),
+				Point(Coordinates("1.0,2.0,0")),
+			),
+			Placemark(
+				AttrID("pm_1"),
+				Name("second"),
+			),
+		),
+	)
+}
+
+func Test_Element_Clone(t *testing.T) {
+	// --- Given ---
+	src := compareDoc()
+
+	// --- When ---
+	cl := src.ChildAtIdx(0).Clone()
+
+	// --- Then ---
+	assert.Nil(t, cl.Parent())
+	assert.True(t, cl.Equal(src.ChildAtIdx(0), EqualOptions{}))
+	assert.Same(t, cl, cl.ChildAtIdx(1).Parent())
+
+	cl.ChildAtIdx(1).SetAttribute(AttrID("pm_x"))
+	cl.ChildAtIdx(0).SetContent([]byte("changed"))
+	cl.FindFirst(ElemCoordinates).content[0] = '9'
+	require.NoError(t, cl.AddChild(Name("added")))
+
+	assert.Exactly(t, "pm_0", src.FindFirst(ElemPlacemark).ID())
+	assert.Exactly(t, "doc", src.ChildAtIdx(0).ChildAtIdx(0).ContentString())
+	assert.Exactly(t, "1.0,2.0,0", src.FindFirst(ElemCoordinates).ContentString())
+	assert.Exactly(t, 3, src.ChildAtIdx(0).ChildCnt())
+}
+
+func Test_Element_Clone_Lossless(t *testing.T) {
+	// --- Given ---
+	data := kit.ReadAll(t, kit.OpenFile(t, "testdata/lossless.kml"))
+	root, err := ParseLossless(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	// --- When ---
+	cl := root.Clone()
+
+	// --- Then ---
+	got, err := xml.Marshal(cl)
+	require.NoError(t, err)
+	assert.Exactly(t, string(data), string(got))
+}
+
+func Test_Element_Equal(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		a, b string
+		opts EqualOptions
+		exp  bool
+	}{
+		{"1", `<kml x="1" y="2">v</kml>`, `<kml x="1" y="2">v</kml>`, EqualOptions{}, true},
+		{"2", `<kml x="1" y="2">v</kml>`, `<kml y="2" x="1">v</kml>`, EqualOptions{}, false},
+		{"3", `<kml x="1" y="2">v</kml>`, `<kml y="2" x="1">v</kml>`, EqualOptions{IgnoreAttrOrder: true}, true},
+		{"4", `<kml x="1">v</kml>`, `<kml x="1" y="2">v</kml>`, EqualOptions{IgnoreAttrOrder: true}, false},
+		{"5", `<kml><b>1,2 3,4</b></kml>`, "<kml><b>1,2\n\t3,4</b></kml>", EqualOptions{}, false},
+		{"6", `<kml><b>1,2 3,4</b></kml>`, "<kml><b>1,2\n\t3,4</b></kml>", EqualOptions{IgnoreWhitespace: true}, true},
+		{"7", `<kml><b>1,2 3,4</b></kml>`, `<kml><b>1.0,2.00 3e0,4</b></kml>`, EqualOptions{}, false},
+		{"8", `<kml><b>1,2 3,4</b></kml>`, `<kml><b>1.0,2.00 3e0,4</b></kml>`, EqualOptions{IgnoreFloatFormat: true}, true},
+		{"9", `<kml><b>1,2 3,4</b></kml>`, `<kml><b>1,2,3,4</b></kml>`, EqualOptions{IgnoreFloatFormat: true}, false},
+		{"10", `<kml><b>1,2</b></kml>`, `<kml><b>1,2.1</b></kml>`, EqualOptions{IgnoreFloatFormat: true}, false},
+		{"11", `<kml><b>x</b></kml>`, `<kml><b>x</b><c/></kml>`, EqualOptions{}, false},
+		{"12", `<kml><b/><c/></kml>`, `<kml><c/><b/></kml>`, EqualOptions{}, false},
+		{"13", `<kml xmlns="http://www.opengis.net/kml/2.2"><b/></kml>`, `<kml><b/></kml>`, EqualOptions{}, true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			a, err := Parse(strings.NewReader(tc.a))
+			require.NoError(t, err)
+			b, err := Parse(strings.NewReader(tc.b))
+			require.NoError(t, err)
+
+			// --- When ---
+			got := a.Equal(b, tc.opts)
+
+			// --- Then ---
+			assert.Exactly(t, tc.exp, got)
+			assert.Exactly(t, tc.exp, b.Equal(a, tc.opts))
+		})
+	}
+}
+
+func Test_Element_Equal_Nil(t *testing.T) {
+	// --- Given ---
+	var nilEl *Element
+
+	// --- Then ---
+	assert.True(t, nilEl.Equal(nil, EqualOptions{}))
+	assert.False(t, nilEl.Equal(KML(), EqualOptions{}))
+	assert.False(t, KML().Equal(nil, EqualOptions{}))
+}
+
+func Test_Diff(t *testing.T) {
+	// --- Given ---
+	a := compareDoc()
+	b := a.Clone()
+
+	doc := b.ChildAtIdx(0)
+	doc.ChildAtIdx(0).SetContent([]byte("new doc"))
+	pm0 := doc.ChildByID("pm_0")
+	pm0.SetAttribute(Attr("targetId", "t"))
+	pm0.FindFirst(ElemCoordinates).SetContent([]byte("1,2,0"))
+	require.NotNil(t, pm0.RemoveChildAtIdx(1))
+	require.NoError(t, doc.AddChild(Placemark(AttrID("pm_2"))))
+
+	// --- When ---
+	got := Diff(a, b, EqualOptions{IgnoreFloatFormat: true})
+
+	// --- Then ---
+	exp := []Difference{
+		{Kind: ContentChanged, Path: "/kml/Document/name", Old: "doc", New: "new doc"},
+		{Kind: AttributeAdded, Path: "/kml/Document/Placemark[pm_0]", Attr: "targetId", New: "t"},
+		{Kind: ElementRemoved, Path: "/kml/Document/Placemark[pm_0]/Point"},
+		{Kind: ElementAdded, Path: "/kml/Document/Placemark[pm_2]"},
+	}
+	assert.Exactly(t, exp, got)
+}
+
+func Test_Diff_Reorder(t *testing.T) {
+	// --- Given ---
+	a := Document(
+		Placemark(AttrID("pm_0")),
+		Placemark(AttrID("pm_1"), Name("x")),
+		Placemark(AttrID("pm_2")),
+	)
+	b := Document(
+		Placemark(AttrID("pm_1"), Name("y")),
+		Placemark(AttrID("pm_0")),
+		Placemark(AttrID("pm_3")),
+		Placemark(AttrID("pm_2")),
+	)
+
+	// --- When ---
+	got := Diff(a, b, EqualOptions{})
+
+	// --- Then ---
+	exp := []string{
+		"- /Document/Placemark[pm_0]",
+		`~ /Document/Placemark[pm_1]/name: "x" -> "y"`,
+		"+ /Document/Placemark[pm_0]",
+		"+ /Document/Placemark[pm_3]",
+	}
+	var str []string
+	for _, d := range got {
+		str = append(str, d.String())
+	}
+	assert.Exactly(t, exp, str)
+}
+
+func Test_Diff_Attributes(t *testing.T) {
+	// --- Given ---
+	a := Placemark(AttrID("pm_0"), Attr("targetId", "t0"), Attr("xmlns:gx", NsGx))
+	b := Placemark(Attr("targetId", "t1"), AttrID("pm_0"))
+
+	// --- When ---
+	got := Diff(a, b, EqualOptions{})
+
+	// --- Then ---
+	exp := []Difference{
+		{Kind: AttributeChanged, Path: "/Placemark[pm_0]", Attr: "targetId", Old: "t0", New: "t1"},
+		{Kind: AttributeRemoved, Path: "/Placemark[pm_0]", Attr: "xmlns:gx", Old: NsGx},
+	}
+	assert.Exactly(t, exp, got)
+}
+
+func Test_Diff_Equal(t *testing.T) {
+	// --- Given ---
+	a := compareDoc()
+
+	// --- When ---
+	got := Diff(a, a.Clone(), EqualOptions{})
+
+	// --- Then ---
+	assert.Nil(t, got)
+}
+
+func Test_Diff_Root(t *testing.T) {
+	// --- When ---
+	got := Diff(Document(), Folder(), EqualOptions{})
+
+	// --- Then ---
+	exp := []Difference{
+		{Kind: ElementRemoved, Path: "/Document"},
+		{Kind: ElementAdded, Path: "/Folder"},
+	}
+	assert.Exactly(t, exp, got)
+}
+
+func Test_DiffKind_String(t *testing.T) {
+	assert.Exactly(t, "element added", ElementAdded.String())
+	assert.Exactly(t, "attribute changed", AttributeChanged.String())
+	assert.Exactly(t, "DiffKind(0)", DiffKind(0).String())
+}
@@ -0,0 +1,157 @@
+package kml
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidContent is returned when element content cannot be converted
+// to the requested type.
+var ErrInvalidContent = errors.New("invalid content")
+
+// ErrMissingChild is returned when requested child element does not exist.
+var ErrMissingChild = errors.New("missing child element")
+
+// contentError returns error describing invalid content of the element.
+func (e *Element) contentError(format string, args ...interface{}) error {
+	return fmt.Errorf(
+		"%s: %s: %w: %s",
+		e.pos,
+		e.Path(),
+		ErrInvalidContent,
+		fmt.Sprintf(format, args...),
+	)
+}
+
+// trimmedContent returns element's content without leading and trailing
+// white space.
+func (e *Element) trimmedContent() string {
+	return string(bytes.TrimSpace(e.content))
+}
+
+// ContentFloat returns element's content as float64.
+func (e *Element) ContentFloat() (float64, error) {
+	s := e.trimmedContent()
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, e.contentError("%q is not a valid float", s)
+	}
+	return v, nil
+}
+
+// ContentInt returns element's content as int.
+func (e *Element) ContentInt() (int, error) {
+	s := e.trimmedContent()
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, e.contentError("%q is not a valid integer", s)
+	}
+	return v, nil
+}
+
+// ContentBool returns element's content as bool. Valid values are
+// "1", "0", "true" and "false".
+func (e *Element) ContentBool() (bool, error) {
+	switch s := e.trimmedContent(); s {
+	case "1", "true":
+		return true, nil
+	case "0", "false":
+		return false, nil
+	default:
+		return false, e.contentError("%q is not a valid boolean", s)
+	}
+}
+
+// dateTimeLayouts lists supported KML dateTime layouts.
+var dateTimeLayouts = []string{
+	"2006",
+	"2006-01",
+	"2006-01-02",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05",
+}
+
+// ContentTime returns element's content as time. Besides full dateTime
+// values (with or without time zone) it accepts KML partial forms:
+// year (2006), year and month (2006-05) and date (2006-05-04). Partial
+// forms and values without time zone are returned in UTC.
+func (e *Element) ContentTime() (time.Time, error) {
+	s := e.trimmedContent()
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, e.contentError("%q is not a valid dateTime", s)
+}
+
+// ContentEnum returns element's content if it's one of the valid values.
+func (e *Element) ContentEnum(valid ...string) (string, error) {
+	s := e.trimmedContent()
+	for _, v := range valid {
+		if s == v {
+			return s, nil
+		}
+	}
+	return "", e.contentError("%q is not one of %s", s, strings.Join(valid, ", "))
+}
+
+// child returns the first child with local name or error if it doesn't exist.
+func (e *Element) child(name string) (*Element, error) {
+	ch := e.ChildByName(name)
+	if ch == nil {
+		return nil, fmt.Errorf("%s: %s: %w %s", e.pos, e.Path(), ErrMissingChild, name)
+	}
+	return ch, nil
+}
+
+// ChildFloat returns content of the first child with local name as float64.
+func (e *Element) ChildFloat(name string) (float64, error) {
+	ch, err := e.child(name)
+	if err != nil {
+		return 0, err
+	}
+	return ch.ContentFloat()
+}
+
+// ChildInt returns content of the first child with local name as int.
+func (e *Element) ChildInt(name string) (int, error) {
+	ch, err := e.child(name)
+	if err != nil {
+		return 0, err
+	}
+	return ch.ContentInt()
+}
+
+// ChildBool returns content of the first child with local name as bool.
+func (e *Element) ChildBool(name string) (bool, error) {
+	ch, err := e.child(name)
+	if err != nil {
+		return false, err
+	}
+	return ch.ContentBool()
+}
+
+// ChildTime returns content of the first child with local name as time.
+// See ContentTime for supported formats.
+func (e *Element) ChildTime(name string) (time.Time, error) {
+	ch, err := e.child(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return ch.ContentTime()
+}
+
+// ChildEnum returns content of the first child with local name if it's
+// one of the valid values.
+func (e *Element) ChildEnum(name string, valid ...string) (string, error) {
+	ch, err := e.child(name)
+	if err != nil {
+		return "", err
+	}
+	return ch.ContentEnum(valid...)
+}
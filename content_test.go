@@ -0,0 +1,210 @@
+package kml
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Element_ContentFloat(t *testing.T) {
+	// --- Given ---
+	el := Scale(1.25)
+
+	// --- When ---
+	got, err := el.ContentFloat()
+
+	// --- Then ---
+	assert.NoError(t, err)
+	assert.Exactly(t, 1.25, got)
+}
+
+func Test_Element_ContentFloat_Invalid(t *testing.T) {
+	// --- Given ---
+	el := Placemark(StringElement(ElemScale, "abc"))
+
+	// --- When ---
+	got, err := el.ChildAtIdx(0).ContentFloat()
+
+	// --- Then ---
+	assert.True(t, errors.Is(err, ErrInvalidContent))
+	assert.EqualError(t, err, `0:0: /Placemark/scale: invalid content: "abc" is not a valid float`)
+	assert.Exactly(t, 0.0, got)
+}
+
+func Test_Element_ContentInt(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		value string
+		exp   int
+		err   string
+	}{
+		{"positive", "12", 12, ""},
+		{"signed", "+12", 12, ""},
+		{"negative", "-3", -3, ""},
+		{"spaces", " 7\n", 7, ""},
+		{"float", "1.5", 0, `0:0: /drawOrder: invalid content: "1.5" is not a valid integer`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			el := StringElement(ElemDrawOrder, tc.value)
+
+			// --- When ---
+			got, err := el.ContentInt()
+
+			// --- Then ---
+			if tc.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.err)
+			}
+			assert.Exactly(t, tc.exp, got)
+		})
+	}
+}
+
+func Test_Element_ContentBool(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		value string
+		exp   bool
+		err   bool
+	}{
+		{"one", "1", true, false},
+		{"zero", "0", false, false},
+		{"true", "true", true, false},
+		{"false", "false", false, false},
+		{"invalid", "yes", false, true},
+		{"upper case", "TRUE", false, true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			el := StringElement(ElemExtrude, tc.value)
+
+			// --- When ---
+			got, err := el.ContentBool()
+
+			// --- Then ---
+			assert.Exactly(t, tc.err, errors.Is(err, ErrInvalidContent))
+			assert.Exactly(t, tc.exp, got)
+		})
+	}
+}
+
+func Test_Element_ContentTime(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		value string
+		exp   time.Time
+	}{
+		{"year", "2006", time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"year month", "2006-05", time.Date(2006, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{"date", "2006-05-04", time.Date(2006, 5, 4, 0, 0, 0, 0, time.UTC)},
+		{"utc", "2006-05-04T10:20:30Z", time.Date(2006, 5, 4, 10, 20, 30, 0, time.UTC)},
+		{"fraction", "2006-05-04T10:20:30.5Z", time.Date(2006, 5, 4, 10, 20, 30, 5e8, time.UTC)},
+		{"no zone", "2006-05-04T10:20:30", time.Date(2006, 5, 4, 10, 20, 30, 0, time.UTC)},
+		{"offset", "2006-05-04T10:20:30+03:00", time.Date(2006, 5, 4, 7, 20, 30, 0, time.UTC)},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			el := StringElement(ElemWhen, tc.value)
+
+			// --- When ---
+			got, err := el.ContentTime()
+
+			// --- Then ---
+			require.NoError(t, err)
+			assert.True(t, tc.exp.Equal(got), "got %s", got)
+		})
+	}
+}
+
+func Test_Element_ContentTime_Invalid(t *testing.T) {
+	// --- Given ---
+	el := StringElement(ElemWhen, "2006-13")
+
+	// --- When ---
+	got, err := el.ContentTime()
+
+	// --- Then ---
+	assert.EqualError(t, err, `0:0: /when: invalid content: "2006-13" is not a valid dateTime`)
+	assert.True(t, got.IsZero())
+}
+
+func Test_Element_ContentEnum(t *testing.T) {
+	// --- Given ---
+	el := AltitudeMode(AltitudeModeAbsolute)
+
+	// --- When ---
+	got, err := el.ContentEnum(AltitudeModeClampToGround, AltitudeModeAbsolute)
+
+	// --- Then ---
+	assert.NoError(t, err)
+	assert.Exactly(t, AltitudeModeAbsolute, got)
+}
+
+func Test_Element_ContentEnum_Invalid(t *testing.T) {
+	// --- Given ---
+	el := AltitudeMode("floating")
+
+	// --- When ---
+	got, err := el.ContentEnum(AltitudeModeClampToGround, AltitudeModeAbsolute)
+
+	// --- Then ---
+	assert.EqualError(t, err, `0:0: /altitudeMode: invalid content: "floating" is not one of clampToGround, absolute`)
+	assert.Exactly(t, "", got)
+}
+
+func Test_Element_ChildAccessors(t *testing.T) {
+	// --- Given ---
+	el := LookAt(
+		Heading(12.5),
+		Range(1000),
+		AltitudeMode(AltitudeModeAbsolute),
+		GxTimeStamp(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)),
+	)
+	el.AddChild(DrawOrder(3), Visibility(true), When("2020-01"))
+
+	// --- When ---
+	heading, errH := el.ChildFloat(ElemHeading)
+	order, errO := el.ChildInt(ElemDrawOrder)
+	vis, errV := el.ChildBool(ElemVisibility)
+	when, errW := el.ChildTime(ElemWhen)
+	mode, errM := el.ChildEnum(ElemAltitudeMode, AltitudeModeAbsolute)
+
+	// --- Then ---
+	assert.NoError(t, errH)
+	assert.Exactly(t, 12.5, heading)
+	assert.NoError(t, errO)
+	assert.Exactly(t, 3, order)
+	assert.NoError(t, errV)
+	assert.True(t, vis)
+	assert.NoError(t, errW)
+	assert.Exactly(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), when)
+	assert.NoError(t, errM)
+	assert.Exactly(t, AltitudeModeAbsolute, mode)
+}
+
+func Test_Element_ChildFloat_Missing(t *testing.T) {
+	// --- Given ---
+	el := LookAt()
+
+	// --- When ---
+	got, err := el.ChildFloat(ElemTilt)
+
+	// --- Then ---
+	assert.True(t, errors.Is(err, ErrMissingChild))
+	assert.EqualError(t, err, "0:0: /LookAt: missing child element tilt")
+	assert.Exactly(t, 0.0, got)
+}
@@ -0,0 +1,163 @@
+package kml
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidCoordinates is returned when coordinates cannot be parsed.
+var ErrInvalidCoordinates = errors.New("invalid coordinates")
+
+// Coord represents single KML coordinate tuple.
+type Coord struct {
+	Lon float64 // Longitude in decimal degrees.
+	Lat float64 // Latitude in decimal degrees.
+	Alt float64 // Altitude in meters. Zero when not provided.
+}
+
+// String returns coordinate tuple in KML format. Altitude is omitted
+// when it's zero.
+func (c Coord) String() string {
+	return string(c.appendTo(nil))
+}
+
+// appendTo appends KML representation of the coordinate to buf.
+func (c Coord) appendTo(buf []byte) []byte {
+	buf = strconv.AppendFloat(buf, c.Lon, 'f', -1, 64)
+	buf = append(buf, ',')
+	buf = strconv.AppendFloat(buf, c.Lat, 'f', -1, 64)
+	if c.Alt != 0 {
+		buf = append(buf, ',')
+		buf = strconv.AppendFloat(buf, c.Alt, 'f', -1, 64)
+	}
+	return buf
+}
+
+// ParseCoords parses KML coordinates string. Tuples may be separated by
+// any whitespace (spaces, tabs, new lines), values in a tuple may have
+// whitespace around commas, trailing commas are ignored and altitude is
+// optional. A tuple ends after its third value, so "1,2,3, 4,5,6," is
+// parsed as two tuples, while "1,2,3,4,5,6" is invalid. Values must be
+// finite decimal numbers, NaN, Inf, hexadecimal values and values with
+// underscores are invalid.
+func ParseCoords(s string) ([]Coord, error) {
+	var crs []Coord
+	i := skipSpace(s, 0)
+	for i < len(s) {
+		start := i
+		var vs [3]float64
+		var n int
+		for {
+			j := i
+			for j < len(s) && s[j] != ',' && !isSpace(s[j]) {
+				j++
+			}
+			v, err := parseCoordValue(s[i:j])
+			if err != nil {
+				end := j
+				for end < len(s) && !isSpace(s[end]) {
+					end++
+				}
+				return nil, coordsError(len(crs), s[start:end], fmt.Sprintf("invalid value %q", s[i:j]))
+			}
+			vs[n] = v
+			n++
+
+			// Tuple continues only when values are separated by comma.
+			k := skipSpace(s, j)
+			if k == len(s) || s[k] != ',' {
+				i = k
+				break
+			}
+			i = skipSpace(s, k+1)
+			if n == 3 {
+				// Value directly after the comma would be the fourth one.
+				if i == k+1 && i < len(s) {
+					end := i
+					for end < len(s) && !isSpace(s[end]) {
+						end++
+					}
+					return nil, coordsError(len(crs), s[start:end], "expected 2 or 3 values")
+				}
+				break
+			}
+			if i == len(s) {
+				break
+			}
+		}
+		if n < 2 {
+			tuple := strings.TrimRight(strings.TrimSpace(s[start:i]), ",")
+			return nil, coordsError(len(crs), tuple, "expected 2 or 3 values")
+		}
+		crs = append(crs, Coord{Lon: vs[0], Lat: vs[1], Alt: vs[2]})
+	}
+	return crs, nil
+}
+
+// parseCoordValue parses single coordinate value. Unlike strconv.ParseFloat
+// it rejects NaN, infinities, hexadecimal floating point values and
+// underscores between digits.
+func parseCoordValue(s string) (float64, error) {
+	d := strings.TrimLeft(s, "+-")
+	if len(d) > 1 && d[0] == '0' && (d[1] == 'x' || d[1] == 'X') || strings.IndexByte(d, '_') >= 0 {
+		return 0, strconv.ErrSyntax
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, strconv.ErrSyntax
+	}
+	return v, nil
+}
+
+// coordsError returns ErrInvalidCoordinates wrapped with tuple details.
+func coordsError(idx int, tuple, msg string) error {
+	return fmt.Errorf("%w: tuple %d %q: %s", ErrInvalidCoordinates, idx, tuple, msg)
+}
+
+// skipSpace returns index of the first non whitespace character in s
+// starting at i.
+func skipSpace(s string, i int) int {
+	for i < len(s) && isSpace(s[i]) {
+		i++
+	}
+	return i
+}
+
+// isSpace returns true for XML whitespace characters.
+func isSpace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
+}
+
+// FormatCoords returns coordinates in KML format with tuples separated
+// by a single space.
+func FormatCoords(crs []Coord) string {
+	buf := make([]byte, 0, len(crs)*24)
+	for i, c := range crs {
+		if i > 0 {
+			buf = append(buf, ' ')
+		}
+		buf = c.appendTo(buf)
+	}
+	return string(buf)
+}
+
+// CoordinatesFrom returns new coordinates element with crs as its value.
+func CoordinatesFrom(crs []Coord, xes ...interface{}) *Element {
+	return Coordinates(FormatCoords(crs), xes...)
+}
+
+// Coords parses element's content as KML coordinates. Returned error
+// describes malformed tuple and the element's position, offset and path.
+func (e *Element) Coords() ([]Coord, error) {
+	crs, err := ParseCoords(string(e.content))
+	if err != nil {
+		return nil, fmt.Errorf("%s (offset %d): %s: %w", e.pos, e.pos.Offset, e.Path(), err)
+	}
+	return crs, nil
+}
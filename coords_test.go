@@ -0,0 +1,127 @@
+package kml
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	kit "github.com/rzajac/testkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseCoords(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		value string
+		exp   []Coord
+	}{
+		{"empty", "", nil},
+		{"whitespace", " \n\t ", nil},
+		{"single", "1.1,2.2,3.3", []Coord{{1.1, 2.2, 3.3}}},
+		{"no altitude", "1.1,2.2", []Coord{{1.1, 2.2, 0}}},
+		{"multiple", "1,2,3 4,5,6", []Coord{{1, 2, 3}, {4, 5, 6}}},
+		{"new lines and tabs", "\n\t1,2,3\n\t4,5,6\r\n", []Coord{{1, 2, 3}, {4, 5, 6}}},
+		{"trailing comma", "1,2,3, 4,5,6,", []Coord{{1, 2, 3}, {4, 5, 6}}},
+		{"trailing comma no altitude", "1,2,", []Coord{{1, 2, 0}}},
+		{"space after comma", "1, 2, 3  4,\t5", []Coord{{1, 2, 3}, {4, 5, 0}}},
+		{"space before comma", "1 ,2 ,3 4,5", []Coord{{1, 2, 3}, {4, 5, 0}}},
+		{"negative", "-122.08,-37.42,-10", []Coord{{-122.08, -37.42, -10}}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			got, err := ParseCoords(tc.value)
+
+			// --- Then ---
+			assert.NoError(t, err)
+			assert.Exactly(t, tc.exp, got)
+		})
+	}
+}
+
+func Test_ParseCoords_Errors(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		value string
+		exp   string
+	}{
+		{"single value", "1", `invalid coordinates: tuple 0 "1": expected 2 or 3 values`},
+		{"too many values", "1,2,3 1,2,3,4", `invalid coordinates: tuple 1 "1,2,3,4": expected 2 or 3 values`},
+		{"six values", "1,2,3,4,5,6", `invalid coordinates: tuple 0 "1,2,3,4,5,6": expected 2 or 3 values`},
+		{"space after comma", "1,2, 3,4,5,", `invalid coordinates: tuple 0 "1,2, 3,4,5,": expected 2 or 3 values`},
+		{"space before fourth value", "1 ,2 ,3 ,4", `invalid coordinates: tuple 0 "1 ,2 ,3 ,4": expected 2 or 3 values`},
+		{"not a number", "1,a,3", `invalid coordinates: tuple 0 "1,a,3": invalid value "a"`},
+		{"empty value", "1,,3", `invalid coordinates: tuple 0 "1,,3": invalid value ""`},
+		{"nan", "1,NaN", `invalid coordinates: tuple 0 "1,NaN": invalid value "NaN"`},
+		{"inf", "1,2 -Inf,2", `invalid coordinates: tuple 1 "-Inf,2": invalid value "-Inf"`},
+		{"infinity", "+infinity,2", `invalid coordinates: tuple 0 "+infinity,2": invalid value "+infinity"`},
+		{"overflow", "1e400,2", `invalid coordinates: tuple 0 "1e400,2": invalid value "1e400"`},
+		{"hex", "0x1p-2,2", `invalid coordinates: tuple 0 "0x1p-2,2": invalid value "0x1p-2"`},
+		{"signed hex", "1,-0X1P2", `invalid coordinates: tuple 0 "1,-0X1P2": invalid value "-0X1P2"`},
+		{"underscore", "1_0,2", `invalid coordinates: tuple 0 "1_0,2": invalid value "1_0"`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			got, err := ParseCoords(tc.value)
+
+			// --- Then ---
+			assert.True(t, errors.Is(err, ErrInvalidCoordinates))
+			assert.EqualError(t, err, tc.exp)
+			assert.Nil(t, got)
+		})
+	}
+}
+
+func Test_Coord_String(t *testing.T) {
+	assert.Exactly(t, "1.5,-2.25,3", Coord{1.5, -2.25, 3}.String())
+	assert.Exactly(t, "1.5,-2.25", Coord{1.5, -2.25, 0}.String())
+}
+
+func Test_CoordinatesFrom(t *testing.T) {
+	// --- Given ---
+	crs := []Coord{{0.1, 0.2, 0.3}, {1.1, 1.2, 0}}
+
+	// --- When ---
+	data, err := xml.Marshal(CoordinatesFrom(crs))
+
+	// --- Then ---
+	assert.NoError(t, err)
+	assert.Exactly(t, `<coordinates>0.1,0.2,0.3 1.1,1.2</coordinates>`, string(data))
+}
+
+func Test_Element_Coords(t *testing.T) {
+	// --- Given ---
+	root, err := Parse(kit.OpenFile(t, "testdata/example.kml"))
+	require.NoError(t, err)
+	cor := root.ChildAtIdx(0).ChildByName(ElemFolder).
+		ChildByName(ElemPlacemark).ChildByName(ElemMultiGeometry).
+		ChildByName(ElemLineString).ChildByName(ElemCoordinates)
+
+	// --- When ---
+	got, err := cor.Coords()
+
+	// --- Then ---
+	assert.NoError(t, err)
+	assert.Exactly(t, []Coord{{0.1, 0.2, 0.3}, {1.1, 1.2, 1.3}}, got)
+}
+
+func Test_Element_Coords_Error(t *testing.T) {
+	// --- Given ---
+	cor := Coordinates("1,2,3 4")
+	cor.pos = Position{Offset: 42, Line: 3, Column: 7}
+
+	// --- When ---
+	got, err := cor.Coords()
+
+	// --- Then ---
+	assert.Nil(t, got)
+	assert.True(t, errors.Is(err, ErrInvalidCoordinates))
+	exp := `3:7 (offset 42): /coordinates: invalid coordinates: tuple 1 "4": expected 2 or 3 values`
+	assert.EqualError(t, err, exp)
+}
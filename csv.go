@@ -0,0 +1,372 @@
+package kml
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidCSV is returned when CSV cannot be converted to KML.
+var ErrInvalidCSV = errors.New("invalid CSV")
+
+// CSVOptions configures CSV export and import. Empty column names have
+// default values: name, description, folder, longitude, latitude and wkt.
+type CSVOptions struct {
+	// Field delimiter. When zero comma is used, use '\t' for TSV.
+	Comma rune
+
+	Name        string // Placemark name column.
+	Description string // Placemark description column.
+	Folder      string // Folder path column, folder names separated by "/".
+	Longitude   string // Point longitude column.
+	Latitude    string // Point latitude column.
+	WKT         string // Column with WKT of non Point geometries.
+}
+
+// withDefaults returns options with default values set.
+func (opts CSVOptions) withDefaults() CSVOptions {
+	set := func(v *string, def string) {
+		if *v == "" {
+			*v = def
+		}
+	}
+	if opts.Comma == 0 {
+		opts.Comma = ','
+	}
+	set(&opts.Name, ElemName)
+	set(&opts.Description, ElemDescription)
+	set(&opts.Folder, "folder")
+	set(&opts.Longitude, "longitude")
+	set(&opts.Latitude, "latitude")
+	set(&opts.WKT, "wkt")
+	return opts
+}
+
+// ToCSV writes Placemarks in the element tree rooted at el to w as CSV
+// with a header row. Placemarks are looked up the same way ToGeoJSON does.
+//
+// Each Placemark is written as a row with name, description, folder path,
+// longitude and latitude of Point geometry or WKT of other geometries,
+// followed by one column per Data and SimpleData field. Field columns are
+// SimpleFields declared by Schemas in the document followed by other
+// fields in order of appearance. Point altitudes are not written. Columns
+// of Placemarks without geometry or with geometries WKT can't represent
+// (Model, gx:Track, gx:MultiTrack) are left empty.
+func ToCSV(w io.Writer, el *Element, opts CSVOptions) error {
+	opts = opts.withDefaults()
+
+	root := el
+	for root.parent != nil {
+		root = root.parent
+	}
+
+	var fields []string
+	index := make(map[string]int) // Field column indexes by name.
+	addField := func(name string) int {
+		idx, ok := index[name]
+		if !ok {
+			idx = len(fields)
+			index[name] = idx
+			fields = append(fields, name)
+		}
+		return idx
+	}
+
+	_ = root.Walk(func(el *Element, _ int) error {
+		if el.LocalName() != ElemSchema {
+			return nil
+		}
+		for _, sf := range el.children {
+			if sf.LocalName() == ElemSimpleField {
+				if name := sf.Attribute("name").Value; name != "" {
+					addField(name)
+				}
+			}
+		}
+		return SkipSubtree
+	})
+
+	var rows [][]string
+	err := eachPlacemark(el, func(pm *Element, path []string) error {
+		row, err := csvRow(pm, path, addField)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = opts.Comma
+
+	header := []string{
+		opts.Name,
+		opts.Description,
+		opts.Folder,
+		opts.Longitude,
+		opts.Latitude,
+		opts.WKT,
+	}
+	cols := len(header) + len(fields)
+	if err := cw.Write(append(header, fields...)); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		for len(row) < cols {
+			row = append(row, "")
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvRow returns CSV row for Placemark. The addField is used to get column
+// index of Data and SimpleData fields relative to the first field column.
+func csvRow(pm *Element, path []string, addField func(name string) int) ([]string, error) {
+	const fixed = 6 // Number of columns before field columns.
+	row := make([]string, fixed)
+	var names []string
+	for _, name := range path {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	row[2] = strings.Join(names, "/")
+
+	set := func(name, val string) {
+		if name == "" {
+			return
+		}
+		idx := fixed + addField(name)
+		for len(row) <= idx {
+			row = append(row, "")
+		}
+		if row[idx] == "" {
+			row[idx] = val
+		}
+	}
+
+	var geom bool
+	for _, ch := range pm.children {
+		switch ch.LocalName() {
+		case ElemName:
+			row[0] = ch.ContentString()
+
+		case ElemDescription:
+			row[1] = ch.ContentString()
+
+		case ElemExtendedData:
+			for _, dat := range ch.children {
+				switch dat.LocalName() {
+				case ElemData:
+					var val string
+					if v := dat.ChildByName(ElemValue); v != nil {
+						val = v.ContentString()
+					}
+					set(dat.Attribute("name").Value, val)
+
+				case ElemSchemaData:
+					for _, sd := range dat.children {
+						if sd.LocalName() == ElemSimpleData {
+							set(sd.Attribute("name").Value, sd.ContentString())
+						}
+					}
+				}
+			}
+
+		default:
+			if geom || !isGeometry(ch) {
+				continue
+			}
+			geom = true
+
+			if ch.LocalName() == ElemPoint {
+				crs, err := geometryCoords(ch)
+				if err != nil {
+					return nil, err
+				}
+				if len(crs) == 0 {
+					return nil, newGeometryError(ch, geometryErr("missing coordinates"))
+				}
+				row[3] = strconv.FormatFloat(crs[0].Lon, 'f', -1, 64)
+				row[4] = strconv.FormatFloat(crs[0].Lat, 'f', -1, 64)
+				continue
+			}
+
+			wkt, err := WKT(ch)
+			if err != nil && !errors.Is(err, ErrUnsupportedGeometry) {
+				return nil, err
+			}
+			row[5] = wkt
+		}
+	}
+	return row, nil
+}
+
+// ParseCSV reads CSV with a header row from r and builds KML(Document(...))
+// element tree with a Placemark for each row.
+//
+// Columns are mapped to Placemark name, description and geometry by
+// names in opts. Rows with longitude and latitude have Point geometry,
+// other rows with WKT have geometry parsed with ParseWKT. Rows with folder
+// path are placed in nested Folders created in order of appearance. Values
+// of other columns are stored as ExtendedData Data elements, empty values
+// are skipped. The header must have either longitude and latitude columns
+// or WKT column. Errors for invalid WKT values wrap ErrInvalidWKT.
+func ParseCSV(r io.Reader, opts CSVOptions) (*Element, error) {
+	opts = opts.withDefaults()
+
+	cr := csv.NewReader(r)
+	cr.Comma = opts.Comma
+
+	header, err := cr.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("%w: missing header", ErrInvalidCSV)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCSV, err)
+	}
+
+	cols := make(map[string]int)
+	for i := len(header) - 1; i >= 0; i-- {
+		cols[header[i]] = i
+	}
+	_, hasLon := cols[opts.Longitude]
+	_, hasLat := cols[opts.Latitude]
+	_, hasWKT := cols[opts.WKT]
+	if hasLon != hasLat || !hasLon && !hasWKT {
+		return nil, fmt.Errorf(
+			"%w: missing %s and %s or %s column",
+			ErrInvalidCSV,
+			opts.Longitude,
+			opts.Latitude,
+			opts.WKT,
+		)
+	}
+
+	mapped := map[string]bool{
+		opts.Name:        true,
+		opts.Description: true,
+		opts.Folder:      true,
+		opts.Longitude:   true,
+		opts.Latitude:    true,
+		opts.WKT:         true,
+	}
+
+	doc := Document()
+	folders := make(map[string]*Element) // Folders by path.
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCSV, err)
+		}
+		line, _ := cr.FieldPos(0)
+
+		value := func(col string) string {
+			if i, ok := cols[col]; ok {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+
+		pm, err := csvPlacemark(rec, header, mapped, value, opts)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		parent := doc
+		if path := value(opts.Folder); path != "" {
+			parent = csvFolder(doc, folders, path)
+		}
+		_ = parent.AddChild(pm)
+	}
+	return KML(doc), nil
+}
+
+// csvPlacemark returns Placemark for CSV record. Columns in mapped are not
+// stored as ExtendedData, value returns trimmed value of a column.
+func csvPlacemark(rec, header []string, mapped map[string]bool, value func(col string) string, opts CSVOptions) (*Element, error) {
+	pm := Placemark()
+	if v := value(opts.Name); v != "" {
+		_ = pm.AddChild(Name(v))
+	}
+	if v := value(opts.Description); v != "" {
+		_ = pm.AddChild(Description(v))
+	}
+
+	ed := ExtendedData()
+	for i, col := range header {
+		if mapped[col] || rec[i] == "" {
+			continue
+		}
+		_ = ed.AddChild(Data(col, Value(rec[i])))
+	}
+	if ed.ChildCnt() > 0 {
+		_ = pm.AddChild(ed)
+	}
+
+	lon, lat := value(opts.Longitude), value(opts.Latitude)
+	switch {
+	case lon != "" || lat != "":
+		c, err := csvCoord(lon, lat)
+		if err != nil {
+			return nil, err
+		}
+		_ = pm.AddChild(Point(CoordinatesFrom([]Coord{c})))
+
+	case value(opts.WKT) != "":
+		geom, err := ParseWKT(value(opts.WKT))
+		if err != nil {
+			return nil, err
+		}
+		_ = pm.AddChild(geom)
+	}
+	return pm, nil
+}
+
+// csvCoord returns coordinate for longitude and latitude strings.
+func csvCoord(lon, lat string) (Coord, error) {
+	var c Coord
+	var err error
+	if c.Lon, err = strconv.ParseFloat(lon, 64); err != nil {
+		return c, fmt.Errorf("%w: invalid longitude %q", ErrInvalidCSV, lon)
+	}
+	if c.Lat, err = strconv.ParseFloat(lat, 64); err != nil {
+		return c, fmt.Errorf("%w: invalid latitude %q", ErrInvalidCSV, lat)
+	}
+	if c.Lon < -180 || c.Lon > 180 {
+		return c, fmt.Errorf("%w: longitude %s out of range", ErrInvalidCSV, lon)
+	}
+	if c.Lat < -90 || c.Lat > 90 {
+		return c, fmt.Errorf("%w: latitude %s out of range", ErrInvalidCSV, lat)
+	}
+	return c, nil
+}
+
+// csvFolder returns Folder for path creating it and its parent Folders
+// in doc when they don't exist.
+func csvFolder(doc *Element, folders map[string]*Element, path string) *Element {
+	if fld, ok := folders[path]; ok {
+		return fld
+	}
+	parent, name := doc, path
+	if i := strings.LastIndexByte(path, '/'); i >= 0 {
+		parent, name = csvFolder(doc, folders, path[:i]), path[i+1:]
+	}
+	fld := Folder(Name(name))
+	_ = parent.AddChild(fld)
+	folders[path] = fld
+	return fld
+}
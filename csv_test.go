@@ -0,0 +1,171 @@
+package kml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+
+	kit "github.com/rzajac/testkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ToCSV(t *testing.T) {
+	// --- Given ---
+	root, err := Parse(kit.OpenFile(t, "testdata/geojson.kml"))
+	require.NoError(t, err)
+	buf := &bytes.Buffer{}
+
+	// --- When ---
+	err = ToCSV(buf, root, CSVOptions{})
+
+	// --- Then ---
+	require.NoError(t, err)
+	exp := "name,description,folder,longitude,latitude,wkt,rating,cost,open,note,kind\n" +
+		"Start,Trip start,Trip,1.5,2.5,,4,12.5,1,quiet,camp\n" +
+		"Road,,Trip/Day 1,,,\"LINESTRING (1 2, 3 4)\",,,,,\n" +
+		",,Trip/Day 1,,,\"POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0), (2 2, 4 2, 4 4, 2 2))\",,,,,\n" +
+		",,Trip/Day 1/Tracks,,,,,,,,\n" +
+		",,Trip,,,\"MULTIPOINT ((1 1), (2 2))\",,,,,\n" +
+		",,Trip,,,\"GEOMETRYCOLLECTION (POINT (1 1), LINESTRING (1 1, 2 2))\",,,,,\n" +
+		"No geometry,,Trip,,,,,,,,\n"
+	assert.Exactly(t, exp, buf.String())
+}
+
+func Test_ToCSV_TSV(t *testing.T) {
+	// --- Given ---
+	pm := Placemark(
+		Name("a\tb"),
+		ExtendedData(Data("x", Value("1"))),
+		Point(Coordinates("1,2,3")),
+	)
+	buf := &bytes.Buffer{}
+	opts := CSVOptions{Comma: '\t', Longitude: "lon", Latitude: "lat"}
+
+	// --- When ---
+	err := ToCSV(buf, pm, opts)
+
+	// --- Then ---
+	require.NoError(t, err)
+	exp := "name\tdescription\tfolder\tlon\tlat\twkt\tx\n" +
+		"\"a\tb\"\t\t\t1\t2\t\t1\n"
+	assert.Exactly(t, exp, buf.String())
+}
+
+func Test_ToCSV_Errors(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		pm  *Element
+		err error
+	}{
+		{"coordinates", Placemark(Point(Coordinates("1,a"))), ErrInvalidCoordinates},
+		{"empty coordinates", Placemark(Point(Coordinates(" "))), ErrInvalidGeometry},
+		{"missing coordinates", Placemark(Point()), ErrInvalidGeometry},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			err := ToCSV(&bytes.Buffer{}, tc.pm, CSVOptions{})
+
+			// --- Then ---
+			assert.True(t, errors.Is(err, tc.err), err)
+		})
+	}
+}
+
+func Test_ToCSV_EmptyPointCoordinates(t *testing.T) {
+	// --- Given ---
+	data := `<kml><Placemark><Point><coordinates> </coordinates></Point></Placemark></kml>`
+	root, err := Parse(strings.NewReader(data))
+	require.NoError(t, err)
+
+	// --- When ---
+	err = ToCSV(&bytes.Buffer{}, root, CSVOptions{})
+
+	// --- Then ---
+	var ge *GeometryError
+	require.True(t, errors.As(err, &ge), err)
+	assert.Exactly(t, ElemPoint, ge.Name)
+	assert.ErrorIs(t, err, ErrInvalidGeometry)
+}
+
+func Test_ParseCSV(t *testing.T) {
+	// --- Given ---
+	data := "id,title,y,x,folder,wkt,note\n" +
+		"1,Start, 2.5 ,1.5,,,quiet\n" +
+		"2,Road,,,Day 1/Roads,\"LINESTRING (1 2, 3 4)\",\n" +
+		"3,,,,Day 1,,\n"
+	opts := CSVOptions{Name: "title", Longitude: "x", Latitude: "y"}
+
+	// --- When ---
+	root, err := ParseCSV(strings.NewReader(data), opts)
+
+	// --- Then ---
+	require.NoError(t, err)
+	got, err := xml.Marshal(root.ChildAtIdx(0))
+	require.NoError(t, err)
+
+	exp := "<Document>" +
+		"<Placemark><name>Start</name><ExtendedData>" +
+		"<Data name=\"id\"><value>1</value></Data>" +
+		"<Data name=\"note\"><value>quiet</value></Data>" +
+		"</ExtendedData><Point><coordinates>1.5,2.5</coordinates></Point></Placemark>" +
+		"<Folder><name>Day 1</name>" +
+		"<Folder><name>Roads</name>" +
+		"<Placemark><name>Road</name><ExtendedData><Data name=\"id\"><value>2</value></Data></ExtendedData>" +
+		"<LineString><coordinates>1,2 3,4</coordinates></LineString></Placemark>" +
+		"</Folder>" +
+		"<Placemark><ExtendedData><Data name=\"id\"><value>3</value></Data></ExtendedData></Placemark>" +
+		"</Folder>" +
+		"</Document>"
+	assert.Exactly(t, exp, string(got))
+}
+
+func Test_ParseCSV_Errors(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		csv string
+		err error
+	}{
+		{"empty", "", ErrInvalidCSV},
+		{"columns", "name,longitude\nx,1\n", ErrInvalidCSV},
+		{"fields", "longitude,latitude\n1,2,3\n", ErrInvalidCSV},
+		{"longitude", "longitude,latitude\nx,2\n", ErrInvalidCSV},
+		{"latitude", "longitude,latitude\n1,\n", ErrInvalidCSV},
+		{"range", "longitude,latitude\n1,91\n", ErrInvalidCSV},
+		{"wkt", "wkt\nPOINT (1\n", ErrInvalidWKT},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			root, err := ParseCSV(strings.NewReader(tc.csv), CSVOptions{})
+
+			// --- Then ---
+			assert.Nil(t, root)
+			assert.True(t, errors.Is(err, tc.err), err)
+		})
+	}
+}
+
+func Test_CSV_RoundTrip(t *testing.T) {
+	// --- Given ---
+	root, err := Parse(kit.OpenFile(t, "testdata/geojson.kml"))
+	require.NoError(t, err)
+	exp := &bytes.Buffer{}
+	require.NoError(t, ToCSV(exp, root, CSVOptions{}))
+
+	// --- When ---
+	imp, err := ParseCSV(bytes.NewReader(exp.Bytes()), CSVOptions{})
+	require.NoError(t, err)
+	got := &bytes.Buffer{}
+	require.NoError(t, ToCSV(got, imp, CSVOptions{}))
+
+	// --- Then ---
+	assert.Exactly(t, exp.String(), got.String())
+}
@@ -4,14 +4,33 @@ import (
 	"bytes"
 	"encoding/xml"
 	"errors"
+	"strconv"
+	"strings"
 )
 
+// ErrNotChild is returned when element is not a child of the element.
+var ErrNotChild = errors.New("not a child element")
+
+// ErrInvalidIndex is returned when child index is out of bounds.
+var ErrInvalidIndex = errors.New("invalid child index")
+
+// ErrInvalidMove is returned when element is nil or would become its own
+// descendant.
+var ErrInvalidMove = errors.New("element cannot be moved to its descendant")
+
+// ErrHasParent is returned when element added to another element already
+// has a parent. Use Detach or MoveTo to move elements between parents.
+var ErrHasParent = errors.New("element already has a parent")
+
 // Element represents KML element and provides set of methods for easy
 // exploration of the KML structure.
 type Element struct {
 	// Start element.
 	se xml.StartElement
 
+	// Element's parent. Nil for root element.
+	parent *Element
+
 	// Element's children.
 	children []*Element
 
@@ -19,17 +38,48 @@ type Element struct {
 	// If element has children it is nil.
 	content xml.CharData
 
-	// Offset of the element from the the start of the file.
-	offset int64
+	// Position of the element in the source document.
+	pos Position
+
+	// Comments, processing instructions, directives and character data
+	// around child elements. See Leading and Trailing methods.
+	lead  []xml.Token // Tokens between previous sibling and the element.
+	trail []xml.Token // Tokens between last child and the end tag.
+	tail  []xml.Token // Tokens after the root element end tag.
+
+	// Source bytes of elements returned by ParseLossless.
+	raw   []byte // Source bytes of the element, nil when modified.
+	inner [2]int // Start and end offsets of the inner content in raw.
+	stag  []byte // Source start tag, nil when attributes were modified.
+	etag  []byte // Source end tag, nil when attributes were modified.
+}
+
+// Position describes location of the element in the source document.
+// Elements which were not parsed have zero Position.
+type Position struct {
+	Offset    int64 // Byte offset of the element start tag.
+	EndOffset int64 // Byte offset right after the element end tag.
+	Line      int   // Line of the element start tag (1 based).
+	Column    int   // Byte column of the element start tag (1 based).
+}
+
+// String returns position in "line:column" format.
+func (p Position) String() string {
+	return strconv.Itoa(p.Line) + ":" + strconv.Itoa(p.Column)
 }
 
 // NewElement returns new instance of Element with name and adds child
 // elements or attributes to it. NewElement panics if variadic argument
 // is not one of xml.Attr or *Element
+//
+// The name may be prefixed with one of the conventional prefixes gx, atom
+// or kml in which case the element is created in the corresponding
+// namespace. Elements created with names without prefix have no namespace
+// and belong to the default namespace of the document.
 func NewElement(name string, els ...interface{}) *Element {
 	xel := &Element{
 		se: xml.StartElement{
-			Name: xml.Name{Local: name},
+			Name: parseName(name),
 		},
 	}
 	if err := xel.AddChild(els...); err != nil {
@@ -44,14 +94,19 @@ func (e *Element) ID() string {
 	return e.Attribute("id").Value
 }
 
-// LocalName returns XML element name.
+// LocalName returns XML element local name (without namespace prefix).
 func (e *Element) LocalName() string {
 	return e.se.Name.Local
 }
 
 // Offset returns byte offset the element starts.
 func (e *Element) Offset() int64 {
-	return e.offset
+	return e.pos.Offset
+}
+
+// Position returns element's position in the source document.
+func (e *Element) Position() Position {
+	return e.pos
 }
 
 // HasAttribute returns true if element has attribute.
@@ -83,6 +138,8 @@ func (e *Element) Attribute(name string) xml.Attr {
 // SetAttribute sets element's attribute. If attribute already exists it
 // will be overwritten.
 func (e *Element) SetAttribute(a xml.Attr) {
+	e.modified()
+	e.stag, e.etag = nil, nil
 	// Set if already present.
 	for i := range e.se.Attr {
 		if e.se.Attr[i].Name.Local == a.Name.Local {
@@ -94,7 +151,22 @@ func (e *Element) SetAttribute(a xml.Attr) {
 	e.se.Attr = append(e.se.Attr, a)
 }
 
-// HasChild returns true if element has a child with local name.
+// RemoveAttribute removes attribute by name. Returns false if attribute
+// does not exist.
+func (e *Element) RemoveAttribute(name string) bool {
+	for i, atr := range e.se.Attr {
+		if atr.Name.Local == name {
+			e.modified()
+			e.stag, e.etag = nil, nil
+			e.se.Attr = append(e.se.Attr[:i], e.se.Attr[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// HasChild returns true if element has a child with name. See MatchName
+// for supported name forms.
 func (e *Element) HasChild(name string) bool {
 	return e.ChildByName(name) != nil
 }
@@ -113,11 +185,12 @@ func (e *Element) ChildAtIdx(index int) *Element {
 	return e.children[index]
 }
 
-// ChildByName returns first child element by local name. Returns nil if
-// child does not exist.
+// ChildByName returns first child element by name. Returns nil if child
+// does not exist. See MatchName for supported name forms.
 func (e *Element) ChildByName(name string) *Element {
+	qn := parseName(name)
 	for _, ch := range e.children {
-		if ch.se.Name.Local == name {
+		if sameName(ch.se.Name, qn) {
 			return ch
 		}
 	}
@@ -134,41 +207,258 @@ func (e *Element) ChildByID(id string) *Element {
 	return nil
 }
 
-// AddChild adds child element(s) to the element.
+// AddChild adds child element(s) to the element. Returns ErrInvalidMove if
+// any of the children is the element or its ancestor and ErrHasParent if
+// any of the children already has a parent.
 func (e *Element) AddChild(els ...interface{}) error {
-	for _, elm := range els {
-		switch el := elm.(type) {
-		case xml.Attr:
-			e.SetAttribute(el)
-		case *Element:
-			e.children = append(e.children, el)
-		default:
-			return errors.New("expected xml.Attr or *Element")
-		}
+	chs, err := e.splitChildren(els)
+	if err != nil {
+		return err
+	}
+	if err := e.canAdd(chs); err != nil {
+		return err
+	}
+	for _, ch := range chs {
+		ch.dropRaw()
+		ch.parent = e
+		e.children = append(e.children, ch)
+	}
+	if len(chs) > 0 {
+		e.modified()
 	}
 	return nil
 }
 
-// PrependChild prepends one or more child elements.
+// PrependChild prepends one or more child elements. Returns ErrInvalidMove
+// if any of the children is the element or its ancestor and ErrHasParent
+// if any of the children already has a parent.
 func (e *Element) PrependChild(els ...interface{}) error {
-	p := len(els)
-	if p == 0 {
+	chs, err := e.splitChildren(els)
+	if err != nil {
+		return err
+	}
+	if err := e.canAdd(chs); err != nil {
+		return err
+	}
+	for _, ch := range chs {
+		ch.dropRaw()
+		ch.parent = e
+	}
+	if len(chs) > 0 {
+		e.modified()
+	}
+	e.children = append(chs, e.children...)
+	return nil
+}
+
+// InsertChildAt inserts one or more child elements at index. The index
+// must be in range [0, ChildCnt()]. Returns ErrHasParent if any of the
+// children already has a parent.
+func (e *Element) InsertChildAt(index int, els ...interface{}) error {
+	if index < 0 || index > len(e.children) {
+		return ErrInvalidIndex
+	}
+	chs, err := e.splitChildren(els)
+	if err != nil {
+		return err
+	}
+	if err := e.canAdd(chs); err != nil {
+		return err
+	}
+	for _, ch := range chs {
+		ch.dropRaw()
+		ch.parent = e
+	}
+	if len(chs) == 0 {
+		return nil
+	}
+	e.modified()
+	tail := append(chs, e.children[index:]...)
+	e.children = append(e.children[:index], tail...)
+	return nil
+}
+
+// InsertChild inserts one or more child elements at positions required by
+// the KML schema. Each child is inserted after existing children which
+// must precede it, so name is placed before styleUrl in a Placemark no
+// matter the order children are added. Children not allowed in the element
+// by the schema and children of elements without known content model are
+// appended. Returns ErrHasParent if any of the children already has a
+// parent.
+func (e *Element) InsertChild(els ...interface{}) error {
+	chs, err := e.splitChildren(els)
+	if err != nil {
+		return err
+	}
+	if err := e.canAdd(chs); err != nil {
+		return err
+	}
+	for _, ch := range chs {
+		idx := len(e.children)
+		if slot := schemaSlot(e, ch); slot >= 0 {
+			for i, sib := range e.children {
+				if schemaSlot(e, sib) > slot {
+					idx = i
+					break
+				}
+			}
+		}
+		if err := e.InsertChildAt(idx, ch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReplaceChild replaces child element old with el. Returns ErrNotChild if
+// old is nil or not a child of the element, ErrInvalidMove if el is nil
+// and ErrHasParent if el already has a parent.
+func (e *Element) ReplaceChild(old, el *Element) error {
+	if old == nil || old.parent != e {
+		return ErrNotChild
+	}
+	if old == el {
 		return nil
 	}
-	l := len(e.children)
-	tmp := make([]*Element, l+p)
-	copy(tmp[p:], e.children)
-	e.children = tmp[:0]
-	if err := e.AddChild(els...); err != nil {
-		e.children = tmp[p:]
+	if err := e.canAdd([]*Element{el}); err != nil {
+		return err
+	}
+	el.dropRaw()
+	idx := old.Index()
+	old.parent = nil
+	el.parent = e
+	e.children[idx] = el
+	e.modified()
+	return nil
+}
+
+// RemoveChild removes child element. Returns false if el is nil or not a
+// child of the element.
+func (e *Element) RemoveChild(el *Element) bool {
+	if el == nil || el.parent != e {
+		return false
+	}
+	return e.RemoveChildAtIdx(el.Index()) != nil
+}
+
+// RemoveChildrenFunc removes all child elements for which pred returns
+// true and returns number of removed elements.
+func (e *Element) RemoveChildrenFunc(pred func(el *Element) bool) int {
+	var keep []*Element
+	for _, ch := range e.children {
+		if pred(ch) {
+			ch.parent = nil
+			continue
+		}
+		keep = append(keep, ch)
+	}
+	cnt := len(e.children) - len(keep)
+	if cnt > 0 {
+		e.modified()
+		e.children = keep
+	}
+	return cnt
+}
+
+// MoveTo moves element to parent at index. Unlike AddChild and other
+// methods adding children, it accepts elements which already have a
+// parent. The index must be in range
+// [0, parent.ChildCnt()] where ChildCnt is counted after the element is
+// removed from its current parent. Returns ErrInvalidMove if parent is
+// nil.
+func (e *Element) MoveTo(parent *Element, index int) error {
+	if parent == nil {
+		return ErrInvalidMove
+	}
+	if err := parent.canAdopt([]*Element{e}); err != nil {
+		return err
+	}
+	cnt := parent.ChildCnt()
+	if e.parent == parent {
+		cnt--
+	}
+	if index < 0 || index > cnt {
+		return ErrInvalidIndex
+	}
+	return parent.InsertChildAt(index, e.Detach())
+}
+
+// Detach removes element from its parent and returns it. Element without
+// parent is returned unchanged.
+func (e *Element) Detach() *Element {
+	if e.parent != nil {
+		e.parent.RemoveChildAtIdx(e.Index())
+	}
+	return e
+}
+
+// canAdopt returns ErrInvalidMove if any of els is nil, the element or its
+// ancestor.
+func (e *Element) canAdopt(els []*Element) error {
+	for _, el := range els {
+		if el == nil {
+			return ErrInvalidMove
+		}
+		for p := e; p != nil; p = p.parent {
+			if p == el {
+				return ErrInvalidMove
+			}
+		}
+	}
+	return nil
+}
+
+// canAdd returns error if els cannot be adopted by the element or any of
+// els already has a parent or is repeated in els.
+func (e *Element) canAdd(els []*Element) error {
+	if err := e.canAdopt(els); err != nil {
 		return err
 	}
-	e.children = tmp
+	for i, el := range els {
+		if el.parent != nil {
+			return ErrHasParent
+		}
+		for _, prev := range els[:i] {
+			if prev == el {
+				return ErrHasParent
+			}
+		}
+	}
 	return nil
 }
 
+// splitChildren sets attributes from els and returns child elements.
+// It returns error without changing the element if any of els is not
+// xml.Attr or *Element.
+func (e *Element) splitChildren(els []interface{}) ([]*Element, error) {
+	for _, elm := range els {
+		switch elm.(type) {
+		case xml.Attr, *Element:
+		default:
+			return nil, errors.New("expected xml.Attr or *Element")
+		}
+	}
+
+	var chs []*Element
+	for _, elm := range els {
+		switch el := elm.(type) {
+		case xml.Attr:
+			e.SetAttribute(el)
+		case *Element:
+			chs = append(chs, el)
+		}
+	}
+	return chs, nil
+}
+
 // RemoveChildren removes all child elements.
 func (e *Element) RemoveChildren() {
+	for _, ch := range e.children {
+		ch.parent = nil
+	}
+	if len(e.children) > 0 {
+		e.modified()
+	}
 	e.children = nil
 }
 
@@ -178,10 +468,83 @@ func (e *Element) RemoveChildAtIdx(index int) *Element {
 	if elm == nil {
 		return nil
 	}
+	e.modified()
 	e.children = append(e.children[:index], e.children[index+1:]...)
+	elm.parent = nil
 	return elm
 }
 
+// Parent returns element's parent or nil for the root element.
+func (e *Element) Parent() *Element {
+	return e.parent
+}
+
+// Ancestors returns element's ancestors starting from its parent and
+// ending with the root element.
+func (e *Element) Ancestors() []*Element {
+	var els []*Element
+	for p := e.parent; p != nil; p = p.parent {
+		els = append(els, p)
+	}
+	return els
+}
+
+// Index returns element's position within its parent or -1 if element
+// has no parent.
+func (e *Element) Index() int {
+	if e.parent == nil {
+		return -1
+	}
+	for i, ch := range e.parent.children {
+		if ch == e {
+			return i
+		}
+	}
+	return -1
+}
+
+// Path returns element's absolute path. Path segments are element names
+// (prefixed the way MatchName accepts them) followed by the element ID in
+// brackets or, when element has no ID and there are siblings with the same
+// name, by the element's one based position among them. Example:
+//
+//	/kml/Document/Folder[fld_0]/Placemark[2]
+func (e *Element) Path() string {
+	els := append([]*Element{e}, e.Ancestors()...)
+	var b strings.Builder
+	for i := len(els) - 1; i >= 0; i-- {
+		b.WriteByte('/')
+		b.WriteString(els[i].pathSegment())
+	}
+	return b.String()
+}
+
+// pathSegment returns element's path segment.
+func (e *Element) pathSegment() string {
+	name := e.qualifiedName()
+	if id := e.ID(); id != "" {
+		return name + "[" + id + "]"
+	}
+	if e.parent == nil {
+		return name
+	}
+
+	var pos, cnt int
+	for _, ch := range e.parent.children {
+		if !sameName(ch.se.Name, e.se.Name) {
+			continue
+		}
+		cnt++
+		if ch == e {
+			pos = cnt
+		}
+	}
+	if cnt < 2 {
+		return name
+	}
+	return name + "[" + strconv.Itoa(pos) + "]"
+}
+
 // Content returns element's content. It returns empty nil slice if
 // element's content is empty or if element is a container for other
 // elements.
@@ -198,11 +561,20 @@ func (e *Element) ContentString() string {
 
 // SetContent sets element's content. It will not set the content if the
 // element is a container for other elements (has one or more child elements).
+// Character data tokens returned by Trailing are removed.
 func (e *Element) SetContent(content []byte) {
 	if len(e.children) > 0 {
 		return
 	}
+	e.modified()
 	e.content = content
+	var trail []xml.Token
+	for _, tok := range e.trail {
+		if _, ok := tok.(xml.CharData); !ok {
+			trail = append(trail, tok)
+		}
+	}
+	e.trail = trail
 }
 
 // ChildContent is a convenience method returning content of the first child
@@ -216,66 +588,173 @@ func (e *Element) ChildContent(name string) []byte {
 	return nil
 }
 
+// UnmarshalXML implements xml.Unmarshaler interface. All returned errors
+// are of type *ParseError. Comments, processing instructions, directives
+// and white space between elements are dropped.
 func (e *Element) UnmarshalXML(dec *xml.Decoder, se xml.StartElement) error {
 	if se.Name.Local != e.se.Name.Local {
-		return ErrUnexpectedElement
+		return newParseError(e, decoderPos(dec), startTag(se), ErrUnexpectedElement)
 	}
+	return e.decode(&decoder{Decoder: dec}, se)
+}
 
-	// Set XML element attributes unless KML root element
-	// in which case we already did it when creating the element.
-	if e.se.Name.Local != ElemKML {
-		e.se.Attr = se.Attr
-	}
+// decode decodes element from the decoder. In lossless mode comments,
+// processing instructions, directives and character data between child
+// elements are kept in the element tree along with the source bytes so
+// unmodified elements are encoded exactly as they appear in the source.
+func (e *Element) decode(d *decoder, se xml.StartElement) error {
+	e.se.Name = decodeName(se.Name)
+	e.se.Attr = decodeAttrs(se.Attr)
+	e.inner[0] = int(d.InputOffset() - e.pos.Offset)
 
-	off := dec.InputOffset()
+	var toks []xml.Token // Tokens since the last child element.
 	for {
-		tok, err := dec.Token()
+		pos := decoderPos(d.Decoder)
+		tok, err := d.Token()
 		if err != nil {
-			return err
+			return newParseError(e, pos, d.syntaxToken(pos, err), err)
 		}
 		switch el := tok.(type) {
 		case xml.StartElement:
-			ch := NewElement(el.Name.Local)
-			ch.offset = off
-			if err := ch.UnmarshalXML(dec, el); err != nil {
-				return err
+			ch := &Element{pos: pos}
+			ch.se.Name = el.Name
+			ch.parent = e
+			if d.lossless() {
+				ch.lead = toks
 			}
+			toks = nil
 			e.children = append(e.children, ch)
-		case xml.CharData:
-			e.content = bytes.TrimSpace(el.Copy())
-			if e.content == nil {
-				off = dec.InputOffset()
+			if err := ch.decode(d, el); err != nil {
+				return err
 			}
 
 		case xml.EndElement:
-			if el == se.End() {
+			if el != se.End() {
+				continue
+			}
+			e.setTrail(toks)
+			e.pos.EndOffset = d.InputOffset()
+			if !d.lossless() {
+				e.trail = nil
 				return nil
 			}
+			e.raw = d.src[e.pos.Offset:e.pos.EndOffset]
+			e.inner[1] = int(pos.Offset - e.pos.Offset)
+			if e.inner[1] < len(e.raw) {
+				e.stag = e.raw[:e.inner[0]]
+				e.etag = e.raw[e.inner[1]:]
+			}
+			return nil
+
+		case xml.CharData:
+			toks = append(toks, el.Copy())
+
+		default:
+			if d.lossless() {
+				toks = append(toks, xml.CopyToken(tok))
+			}
+		}
+	}
+}
+
+// setTrail sets decoded tokens found after the last child element. For
+// elements without children character data becomes element's content and
+// the other tokens are kept unless the content is white space only.
+func (e *Element) setTrail(toks []xml.Token) {
+	if len(e.children) > 0 {
+		e.trail = toks
+		return
+	}
+
+	var other []xml.Token
+	for _, tok := range toks {
+		if cd, ok := tok.(xml.CharData); ok {
+			e.content = append(e.content, cd...)
+			continue
 		}
+		other = append(other, tok)
+	}
+	e.content = bytes.TrimSpace(e.content)
+
+	switch {
+	case len(e.content) == 0:
+		e.trail = toks
+	case len(other) > 0:
+		e.trail = other
+	}
+}
+
+// decoderPos returns current decoder position.
+func decoderPos(dec *xml.Decoder) Position {
+	line, col := dec.InputPos()
+	return Position{
+		Offset: dec.InputOffset(),
+		Line:   line,
+		Column: col,
 	}
 }
 
 const cdataStart = "<![CDATA["
 const cdataEnd = "]]>"
 
+// MarshalXML implements xml.Marshaler interface. Element names and
+// attributes in namespaces are prefixed with prefixes declared on the
+// element or its ancestors. Namespaces without declared prefix are
+// declared on the element using the conventional prefix (gx, atom, kml)
+// or the first free prefix from ns1, ns2, etc. Elements in gx and atom
+// namespaces use gx and atom prefixes without declaration unless other
+// prefixes were declared for them. Prefixes declared on ancestors of the
+// element and bound to namespaces used in its tree are declared on the
+// element.
+//
+// Comments, processing instructions, directives and character data kept
+// in the element tree are encoded in their positions, unmodified elements
+// returned by ParseLossless are encoded exactly as they appear in the
+// source. The encoder indentation is not applied to such elements. When
+// encoding the root element the tokens before and after it are encoded as
+// well.
 func (e *Element) MarshalXML(enc *xml.Encoder, _ xml.StartElement) error {
+	root := e.parent == nil
+
 	// Special case when encoding KLM root element.
 	// It adds XML prolog as a first line.
-	if e.se.Name.Local == ElemKML {
-		proc := xml.ProcInst{
-			Target: "xml",
-			Inst:   []byte(`version="1.0" encoding="UTF-8"`),
-		}
-
-		if err := enc.EncodeToken(proc); err != nil {
+	if e.se.Name.Local == ElemKML && !(root && hasXMLDecl(e.lead)) {
+		if err := encodeProlog(enc); err != nil {
 			return err
 		}
-
-		if err := enc.EncodeToken(xml.CharData{'\n'}); err != nil {
+	}
+	if root {
+		if err := encodeTokens(enc, e.lead); err != nil {
 			return err
 		}
 	}
 
+	if err := e.encodeKept(enc, newNSScope()); err != nil || !root {
+		return err
+	}
+	return encodeTokens(enc, e.tail)
+}
+
+// encodeKept encodes element with namespace prefixes in scope and with
+// tokens and source bytes kept in the element tree. Prefixes the element
+// inherits from its ancestors are declared on the element.
+func (e *Element) encodeKept(enc *xml.Encoder, scope *nsScope) error {
+	el := e
+	if decl := e.inheritedNS(); len(decl) > 0 {
+		cp := *e
+		cp.se.Attr = append(append([]xml.Attr{}, e.se.Attr...), decl...)
+		el = &cp
+	}
+	if el.hasSource() {
+		return el.encodeSource(enc, scope)
+	}
+	return el.encode(enc, scope)
+}
+
+// encode encodes element with namespace prefixes in scope.
+func (e *Element) encode(enc *xml.Encoder, scope *nsScope) error {
+	se, scope := scope.start(e)
+
 	// Use CDATA directive for content that need it.
 	if len(e.content) > 0 && needsCDATA(e.content) {
 		value := make([]byte, len(cdataStart)+len(cdataEnd)+len(e.content))
@@ -287,13 +766,10 @@ func (e *Element) MarshalXML(enc *xml.Encoder, _ xml.StartElement) error {
 		}{
 			Value: value,
 		}
-		if err := enc.EncodeElement(cdataWrap, e.se); err != nil {
-			return err
-		}
-		return nil
+		return enc.EncodeElement(cdataWrap, se)
 	}
 
-	if err := enc.EncodeToken(e.se); err != nil {
+	if err := enc.EncodeToken(se); err != nil {
 		return err
 	}
 
@@ -302,9 +778,23 @@ func (e *Element) MarshalXML(enc *xml.Encoder, _ xml.StartElement) error {
 	}
 
 	for _, c := range e.children {
-		if err := enc.EncodeElement(c, e.se); err != nil {
+		if err := c.encode(enc, scope); err != nil {
 			return err
 		}
 	}
-	return enc.EncodeToken(e.se.End())
+	return enc.EncodeToken(se.End())
+}
+
+// encodeProlog encodes XML prolog followed by a new line.
+func encodeProlog(enc *xml.Encoder) error {
+	proc := xml.ProcInst{
+		Target: "xml",
+		Inst:   []byte(`version="1.0" encoding="UTF-8"`),
+	}
+
+	if err := enc.EncodeToken(proc); err != nil {
+		return err
+	}
+
+	return enc.EncodeToken(xml.CharData{'\n'})
 }
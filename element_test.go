@@ -189,3 +189,432 @@ func Test_Element_ChildByID(t *testing.T) {
 	require.NotNil(t, ch)
 	assert.Exactly(t, "f2", ch.ID())
 }
+
+func Test_Element_Parent(t *testing.T) {
+	// --- Given ---
+	root, err := Parse(kit.OpenFile(t, "testdata/example.kml"))
+	require.NoError(t, err)
+
+	// --- When ---
+	cor := root.FindFirst(ElemCoordinates)
+
+	// --- Then ---
+	require.NotNil(t, cor)
+	assert.Nil(t, root.Parent())
+	assert.Exactly(t, ElemLineString, cor.Parent().LocalName())
+
+	var names []string
+	for _, el := range cor.Ancestors() {
+		names = append(names, el.LocalName())
+	}
+	exp := []string{ElemLineString, ElemMultiGeometry, ElemPlacemark, ElemFolder, ElemDocument, ElemKML}
+	assert.Exactly(t, exp, names)
+	assert.Nil(t, root.Ancestors())
+}
+
+func Test_Element_Index(t *testing.T) {
+	// --- Given ---
+	nam := Name("name")
+	fld := Folder()
+	doc := Document(nam, Description("desc"), fld)
+
+	// --- Then ---
+	assert.Exactly(t, -1, doc.Index())
+	assert.Exactly(t, 0, nam.Index())
+	assert.Exactly(t, 2, fld.Index())
+}
+
+func Test_Element_Path(t *testing.T) {
+	// --- Given ---
+	pm0 := Placemark(Name("pm 0"))
+	pm1 := Placemark(Name("pm 1"))
+	cor := Coordinates("1,2")
+	KML(
+		Document(
+			Folder(
+				AttrID("fld_0"),
+				pm0,
+				pm1,
+				Placemark(AttrID("pm_2"), Point(cor)),
+			),
+		),
+	)
+
+	// --- Then ---
+	assert.Exactly(t, "/kml/Document/Folder[fld_0]/Placemark[1]", pm0.Path())
+	assert.Exactly(t, "/kml/Document/Folder[fld_0]/Placemark[2]", pm1.Path())
+	assert.Exactly(t, "/kml/Document/Folder[fld_0]/Placemark[pm_2]/Point/coordinates", cor.Path())
+	assert.Exactly(t, "/Placemark", Placemark().Path())
+}
+
+func Test_Element_Parent_ChildManipulation(t *testing.T) {
+	// --- Given ---
+	nam := Name("name")
+	dsc := Description("desc")
+	fld := Folder()
+	doc := Document()
+
+	// --- When ---
+	require.NoError(t, doc.AddChild(dsc))
+	require.NoError(t, doc.PrependChild(nam))
+	assert.ErrorIs(t, fld.AddChild(dsc), ErrHasParent)
+	require.NoError(t, fld.AddChild(dsc.Detach()))
+
+	// --- Then ---
+	assert.Exactly(t, doc, nam.Parent())
+	assert.Exactly(t, fld, dsc.Parent())
+	assert.Exactly(t, 1, doc.ChildCnt())
+	assert.Exactly(t, 0, dsc.Index())
+
+	// --- When ---
+	got := doc.RemoveChildAtIdx(0)
+
+	// --- Then ---
+	assert.Exactly(t, nam, got)
+	assert.Nil(t, nam.Parent())
+	assert.Exactly(t, -1, nam.Index())
+
+	// --- When ---
+	fld.RemoveChildren()
+
+	// --- Then ---
+	assert.Nil(t, dsc.Parent())
+}
+
+func Test_Element_AddChild_InvalidType(t *testing.T) {
+	// --- Given ---
+	nam := Name("name")
+	doc := Document()
+
+	// --- When ---
+	err := doc.AddChild(nam, AttrID("id"), "invalid")
+
+	// --- Then ---
+	assert.Error(t, err)
+	assert.Exactly(t, 0, doc.ChildCnt())
+	assert.Exactly(t, 0, doc.AttributeCnt())
+	assert.Nil(t, nam.Parent())
+}
+
+func Test_Element_AddChild_HasParent(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		add func(el, ch *Element) error
+	}{
+		{"AddChild", func(el, ch *Element) error { return el.AddChild(ch) }},
+		{"PrependChild", func(el, ch *Element) error { return el.PrependChild(ch) }},
+		{"InsertChildAt", func(el, ch *Element) error { return el.InsertChildAt(0, ch) }},
+		{"InsertChild", func(el, ch *Element) error { return el.InsertChild(ch) }},
+		{"ReplaceChild", func(el, ch *Element) error { return el.ReplaceChild(el.ChildAtIdx(0), ch) }},
+		{"same parent", func(el, ch *Element) error { return ch.Parent().AddChild(ch) }},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			sty := StyleURL("#x")
+			pm0 := Placemark(sty)
+			pm1 := Placemark(Name("name"))
+
+			// --- When ---
+			err := tc.add(pm1, sty)
+
+			// --- Then ---
+			assert.ErrorIs(t, err, ErrHasParent)
+			assert.Exactly(t, pm0, sty.Parent())
+			assert.Exactly(t, 1, pm0.ChildCnt())
+			assert.Exactly(t, 1, pm1.ChildCnt())
+		})
+	}
+}
+
+func Test_Element_AddChild_Repeated(t *testing.T) {
+	// --- Given ---
+	nam := Name("name")
+	pm := Placemark()
+
+	// --- When ---
+	err := pm.AddChild(nam, nam)
+
+	// --- Then ---
+	assert.ErrorIs(t, err, ErrHasParent)
+	assert.Exactly(t, 0, pm.ChildCnt())
+	assert.Nil(t, nam.Parent())
+}
+
+func Test_NewElement_HasParent(t *testing.T) {
+	// --- Given ---
+	sty := StyleURL("#x")
+	Placemark(sty)
+
+	// --- Then ---
+	assert.PanicsWithValue(t, ErrHasParent, func() { Placemark(sty) })
+}
+
+func Test_Element_AddChild_Cycle(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		add func(parent, ch *Element) error
+	}{
+		{"AddChild", func(parent, ch *Element) error { return parent.AddChild(ch) }},
+		{"PrependChild", func(parent, ch *Element) error { return parent.PrependChild(ch) }},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			pm := Placemark(Name("name"))
+			fld := Folder(pm)
+			doc := Document(fld)
+
+			// --- When ---
+			errSelf := tc.add(doc, doc)
+			errAnc := tc.add(pm, doc)
+
+			// --- Then ---
+			assert.ErrorIs(t, errSelf, ErrInvalidMove)
+			assert.ErrorIs(t, errAnc, ErrInvalidMove)
+			assert.Nil(t, doc.Parent())
+			assert.Exactly(t, 1, doc.ChildCnt())
+			assert.Exactly(t, 1, pm.ChildCnt())
+			assert.Exactly(t, "/Document/Folder/Placemark", pm.Path())
+		})
+	}
+}
+
+func Test_Element_InsertChildAt(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		idx int
+		exp string
+	}{
+		{"start", 0, `<Document><Folder id="f1"></Folder><name>name</name><description>desc</description></Document>`},
+		{"middle", 1, `<Document><name>name</name><Folder id="f1"></Folder><description>desc</description></Document>`},
+		{"end", 2, `<Document><name>name</name><description>desc</description><Folder id="f1"></Folder></Document>`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			doc := Document(Name("name"), Description("desc"))
+			fld := Folder(AttrID("f1"))
+
+			// --- When ---
+			err := doc.InsertChildAt(tc.idx, fld)
+
+			// --- Then ---
+			require.NoError(t, err)
+			assert.Exactly(t, doc, fld.Parent())
+
+			data, err := xml.Marshal(doc)
+			require.NoError(t, err)
+			assert.Exactly(t, tc.exp, string(data))
+		})
+	}
+}
+
+func Test_Element_InsertChildAt_InvalidIndex(t *testing.T) {
+	// --- Given ---
+	doc := Document(Name("name"))
+
+	// --- Then ---
+	assert.ErrorIs(t, doc.InsertChildAt(-1, Folder()), ErrInvalidIndex)
+	assert.ErrorIs(t, doc.InsertChildAt(2, Folder()), ErrInvalidIndex)
+	assert.ErrorIs(t, doc.ChildAtIdx(0).InsertChildAt(0, doc), ErrInvalidMove)
+	assert.Exactly(t, 1, doc.ChildCnt())
+}
+
+func Test_Element_InsertChild_SchemaOrder(t *testing.T) {
+	// --- Given ---
+	pm := Placemark(
+		AttrID("pm_0"),
+		StyleURL("#sty_0"),
+		Point(Coordinates("1,2")),
+	)
+
+	// --- When ---
+	err := pm.InsertChild(Name("name"), ExtendedData(), Visibility(true), GxBalloonVisibility(true))
+
+	// --- Then ---
+	require.NoError(t, err)
+
+	var got []string
+	for _, ch := range pm.children {
+		got = append(got, ch.qualifiedName())
+	}
+	exp := []string{"name", "visibility", "styleUrl", "ExtendedData", "gx:balloonVisibility", "Point"}
+	assert.Exactly(t, exp, got)
+	assert.Nil(t, Validate(pm))
+}
+
+func Test_Element_InsertChild_Unknown(t *testing.T) {
+	// --- Given ---
+	doc := Document(Name("name"), Folder())
+	custom := NewElementNS("http://campsites.example.com", "number")
+
+	// --- When ---
+	err := doc.InsertChild(custom)
+
+	// --- Then ---
+	require.NoError(t, err)
+	assert.Exactly(t, 2, custom.Index())
+}
+
+func Test_Element_ReplaceChild(t *testing.T) {
+	// --- Given ---
+	doc := Document(Name("name"), Description("desc"))
+	old := doc.ChildAtIdx(0)
+	nam := Name("new")
+
+	// --- When ---
+	err := doc.ReplaceChild(old, nam)
+
+	// --- Then ---
+	require.NoError(t, err)
+	assert.Nil(t, old.Parent())
+	assert.Exactly(t, doc, nam.Parent())
+	assert.Exactly(t, 0, nam.Index())
+	assert.Exactly(t, 2, doc.ChildCnt())
+
+	assert.ErrorIs(t, doc.ReplaceChild(old, Name("x")), ErrNotChild)
+}
+
+func Test_Element_NilArguments(t *testing.T) {
+	// --- Given ---
+	doc := Document(Name("name"))
+	nam := doc.ChildAtIdx(0)
+	var nilEl *Element
+
+	// --- Then ---
+	assert.ErrorIs(t, doc.ReplaceChild(nil, Name("x")), ErrNotChild)
+	assert.ErrorIs(t, doc.ReplaceChild(nam, nil), ErrInvalidMove)
+	assert.False(t, doc.RemoveChild(nil))
+	assert.ErrorIs(t, nam.MoveTo(nil, 0), ErrInvalidMove)
+	assert.ErrorIs(t, nilEl.MoveTo(doc, 0), ErrInvalidMove)
+	assert.ErrorIs(t, doc.InsertChildAt(0, nilEl), ErrInvalidMove)
+	assert.ErrorIs(t, doc.InsertChild(nilEl), ErrInvalidMove)
+	assert.ErrorIs(t, doc.AddChild(nilEl), ErrInvalidMove)
+	assert.ErrorIs(t, doc.PrependChild(nilEl), ErrInvalidMove)
+	assert.Exactly(t, 1, doc.ChildCnt())
+	assert.Exactly(t, doc, nam.Parent())
+}
+
+func Test_Element_RemoveChild(t *testing.T) {
+	// --- Given ---
+	doc := Document(Name("name"), Description("desc"))
+	dsc := doc.ChildAtIdx(1)
+
+	// --- When ---
+	got := doc.RemoveChild(dsc)
+
+	// --- Then ---
+	assert.True(t, got)
+	assert.Nil(t, dsc.Parent())
+	assert.Exactly(t, 1, doc.ChildCnt())
+	assert.False(t, doc.RemoveChild(dsc))
+}
+
+func Test_Element_RemoveChildrenFunc(t *testing.T) {
+	// --- Given ---
+	doc := Document(
+		Name("name"),
+		Placemark(AttrID("pm_0")),
+		Placemark(AttrID("pm_1")),
+		Folder(AttrID("pm_2")),
+	)
+	pm0 := doc.ChildAtIdx(1)
+
+	// --- When ---
+	got := doc.RemoveChildrenFunc(func(el *Element) bool {
+		return el.MatchName(ElemPlacemark) || el.ID() == "pm_2"
+	})
+
+	// --- Then ---
+	assert.Exactly(t, 3, got)
+	assert.Exactly(t, 1, doc.ChildCnt())
+	assert.Nil(t, pm0.Parent())
+	assert.Exactly(t, 0, doc.RemoveChildrenFunc(func(el *Element) bool { return false }))
+}
+
+func Test_Element_RemoveAttribute(t *testing.T) {
+	// --- Given ---
+	pm := Placemark(AttrID("pm_0"), Attr("targetId", "t"))
+
+	// --- When ---
+	got := pm.RemoveAttribute("id")
+
+	// --- Then ---
+	assert.True(t, got)
+	assert.Exactly(t, 1, pm.AttributeCnt())
+	assert.False(t, pm.HasAttribute("id"))
+	assert.False(t, pm.RemoveAttribute("id"))
+}
+
+func Test_Element_MoveTo(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		from int
+		to   int
+		exp  string
+	}{
+		{"forward", 0, 2, "bcad"},
+		{"backward", 3, 1, "adbc"},
+		{"same", 1, 1, "abcd"},
+		{"end", 0, 3, "bcda"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			fld := Folder(Name("a"), Name("b"), Name("c"), Name("d"))
+
+			// --- When ---
+			err := fld.ChildAtIdx(tc.from).MoveTo(fld, tc.to)
+
+			// --- Then ---
+			require.NoError(t, err)
+			var got string
+			for _, ch := range fld.children {
+				got += ch.ContentString()
+			}
+			assert.Exactly(t, tc.exp, got)
+		})
+	}
+}
+
+func Test_Element_MoveTo_OtherParent(t *testing.T) {
+	// --- Given ---
+	doc := Document(Folder(AttrID("f1"), Name("a")), Folder(AttrID("f2")))
+	nam := doc.FindFirst(ElemName)
+	f2 := doc.ChildByID("f2")
+
+	// --- When ---
+	err := nam.MoveTo(f2, 0)
+
+	// --- Then ---
+	require.NoError(t, err)
+	assert.Exactly(t, f2, nam.Parent())
+	assert.Exactly(t, 0, doc.ChildByID("f1").ChildCnt())
+
+	assert.ErrorIs(t, nam.MoveTo(f2, 2), ErrInvalidIndex)
+	assert.ErrorIs(t, doc.MoveTo(f2, 0), ErrInvalidMove)
+}
+
+func Test_Element_Detach(t *testing.T) {
+	// --- Given ---
+	doc := Document(Name("name"))
+	nam := doc.ChildAtIdx(0)
+
+	// --- When ---
+	got := nam.Detach()
+
+	// --- Then ---
+	assert.Same(t, nam, got)
+	assert.Nil(t, nam.Parent())
+	assert.Exactly(t, 0, doc.ChildCnt())
+	assert.Same(t, doc, doc.Detach())
+}
@@ -2,12 +2,17 @@ package kml
 
 import (
 	"encoding/xml"
+	"image/color"
 	"strconv"
 	"time"
 )
 
 // Reference: https://developers.google.com/kml/documentation/kmlreference
 
+// Builders for elements not defined in this file are generated from the
+// bundled XSD schemas to elements_gen.go.
+//go:generate go run ./internal/genbuilders
+
 // KML element names.
 const (
 	ElemAltitude        = "altitude"
@@ -17,28 +22,36 @@ const (
 	ElemCamera          = "Camera"
 	ElemColor           = "color"
 	ElemCoordinates     = "coordinates"
-	ElemData            = "data"
+	ElemData            = "Data"
 	ElemDescription     = "description"
 	ElemDisplayMode     = "displayMode"
 	ElemDisplayName     = "displayName"
 	ElemDocument        = "Document"
 	ElemExtendedData    = "ExtendedData"
+	ElemExtrude         = "extrude"
 	ElemFolder          = "Folder"
 	ElemGxTimeStamp     = "gx:TimeStamp"
 	ElemGxViewerOptions = "gx:ViewerOptions"
 	ElemGxOption        = "gx:option"
 	ElemHeading         = "heading"
+	ElemHref            = "href"
+	ElemInnerBoundaryIs = "innerBoundaryIs"
 	ElemKML             = "kml"
 	ElemLabelStyle      = "LabelStyle"
 	ElemLatitude        = "latitude"
 	ElemLineStyle       = "LineStyle"
 	ElemLineString      = "LineString"
+	ElemLinearRing      = "LinearRing"
 	ElemLongitude       = "longitude"
+	ElemModel           = "Model"
 	ElemMultiGeometry   = "MultiGeometry"
 	ElemName            = "name"
+	ElemOuterBoundaryIs = "outerBoundaryIs"
 	ElemOutline         = "outline"
 	ElemPlacemark       = "Placemark"
+	ElemPoint           = "Point"
 	ElemPolyStyle       = "PolyStyle"
+	ElemPolygon         = "Polygon"
 	ElemRoll            = "roll"
 	ElemScale           = "scale"
 	ElemSchema          = "Schema"
@@ -62,6 +75,9 @@ func Altitude(value float64, xes ...interface{}) *Element {
 }
 
 // AltitudeMode mode valid values.
+//
+// Deprecated: Use AltitudeModeRelativeToGround, AltitudeModeClampToGround
+// and AltitudeModeAbsolute.
 const (
 	AltitudeMoreRel = "relativeToGround"
 	AltitudeMoreCla = "clampToGround"
@@ -85,6 +101,11 @@ func BgColor(value string, xes ...interface{}) *Element {
 	return StringElement(ElemBgColor, value, xes...)
 }
 
+// BgColorRGBA returns new bgColor element with value converted from c.
+func BgColorRGBA(c color.Color, xes ...interface{}) *Element {
+	return BgColor(KMLColorFrom(c).String(), xes...)
+}
+
 // ----------------------------------- C ---------------------------------------
 
 // Camera returns new camera element.
@@ -97,6 +118,11 @@ func Color(value string, xes ...interface{}) *Element {
 	return StringElement(ElemColor, value, xes...)
 }
 
+// ColorRGBA returns new color element with value converted from c.
+func ColorRGBA(c color.Color, xes ...interface{}) *Element {
+	return Color(KMLColorFrom(c).String(), xes...)
+}
+
 // Coordinates returns new coordinates element.
 func Coordinates(value string, xes ...interface{}) *Element {
 	return StringElement(ElemCoordinates, value, xes...)
@@ -148,6 +174,11 @@ func ExtendedData(xes ...interface{}) *Element {
 	return NewElement(ElemExtendedData, xes...)
 }
 
+// Extrude returns new extrude element.
+func Extrude(value bool, xes ...interface{}) *Element {
+	return BoolElement(ElemExtrude, value, xes...)
+}
+
 // ----------------------------------- F ---------------------------------------
 
 // Folder returns new Folder element.
@@ -166,6 +197,12 @@ func GxOption(name string, enabled bool) *Element {
 	)
 }
 
+// GxOuterColorRGBA returns new gx:outerColor element with value converted
+// from c.
+func GxOuterColorRGBA(c color.Color, xes ...interface{}) *Element {
+	return GxOuterColor(KMLColorFrom(c).String(), xes...)
+}
+
 // GxTimeStamp returns new gx:TimeStamp element.
 func GxTimeStamp(when time.Time, xes ...interface{}) *Element {
 	return NewElement(
@@ -186,28 +223,40 @@ func Heading(value float64, xes ...interface{}) *Element {
 	return FloatElement(ElemHeading, value, xes...)
 }
 
+// Href returns new href element.
+func Href(value string, xes ...interface{}) *Element {
+	return StringElement(ElemHref, value, xes...)
+}
+
 // ----------------------------------- I ---------------------------------------
+
+// InnerBoundaryIs returns new innerBoundaryIs element.
+func InnerBoundaryIs(xes ...interface{}) *Element {
+	return NewElement(ElemInnerBoundaryIs, xes...)
+}
+
 // ----------------------------------- J ---------------------------------------
 // ----------------------------------- K ---------------------------------------
 
-// KML returns a new kml element.
+// KML returns a new kml element. The element declares KML as the default
+// namespace and gx, kml and atom namespace prefixes.
 func KML(xes ...interface{}) *Element {
 	xel := NewElement("kml", xes...)
-	xel.se.Name.Space = "http://www.opengis.net/kml/2.2"
+	xel.se.Name.Space = NsKML
 
 	xel.SetAttribute(xml.Attr{
 		Name:  xml.Name{Local: "xmlns:gx"},
-		Value: "http://www.google.com/kml/ext/2.2",
+		Value: NsGx,
 	})
 
 	xel.SetAttribute(xml.Attr{
 		Name:  xml.Name{Local: "xmlns:kml"},
-		Value: "http://www.opengis.net/kml/2.2",
+		Value: NsKML,
 	})
 
 	xel.SetAttribute(xml.Attr{
 		Name:  xml.Name{Local: "xmlns:atom"},
-		Value: "http://www.w3.org/2005/Atom",
+		Value: NsAtom,
 	})
 
 	return xel
@@ -230,6 +279,11 @@ func LineString(xes ...interface{}) *Element {
 	return NewElement(ElemLineString, xes...)
 }
 
+// LinearRing returns new LinearRing element.
+func LinearRing(xes ...interface{}) *Element {
+	return NewElement(ElemLinearRing, xes...)
+}
+
 // LineStyle returns new LineStyle element.
 func LineStyle(xes ...interface{}) *Element {
 	return NewElement(ElemLineStyle, xes...)
@@ -242,6 +296,11 @@ func Longitude(value float64, xes ...interface{}) *Element {
 
 // ----------------------------------- M ---------------------------------------
 
+// Model returns new Model element.
+func Model(xes ...interface{}) *Element {
+	return NewElement(ElemModel, xes...)
+}
+
 // MultiGeometry returns new MultiGeometry element.
 func MultiGeometry(xes ...interface{}) *Element {
 	return NewElement(ElemMultiGeometry, xes...)
@@ -256,6 +315,11 @@ func Name(value string, xes ...interface{}) *Element {
 
 // ----------------------------------- O ---------------------------------------
 
+// OuterBoundaryIs returns new outerBoundaryIs element.
+func OuterBoundaryIs(xes ...interface{}) *Element {
+	return NewElement(ElemOuterBoundaryIs, xes...)
+}
+
 // Outline returns new outline element.
 func Outline(value bool, xes ...interface{}) *Element {
 	return BoolElement(ElemOutline, value, xes...)
@@ -268,11 +332,21 @@ func Placemark(xes ...interface{}) *Element {
 	return NewElement(ElemPlacemark, xes...)
 }
 
+// Point returns new Point element.
+func Point(xes ...interface{}) *Element {
+	return NewElement(ElemPoint, xes...)
+}
+
 // PolyStyle returns new PolyStyle element.
 func PolyStyle(xes ...interface{}) *Element {
 	return NewElement(ElemPolyStyle, xes...)
 }
 
+// Polygon returns new Polygon element.
+func Polygon(xes ...interface{}) *Element {
+	return NewElement(ElemPolygon, xes...)
+}
+
 // ----------------------------------- Q ---------------------------------------
 // ----------------------------------- R ---------------------------------------
 
@@ -380,6 +454,11 @@ func Text(value string, xes ...interface{}) *Element {
 	return StringElement(ElemText, value, xes...)
 }
 
+// TextColorRGBA returns new textColor element with value converted from c.
+func TextColorRGBA(c color.Color, xes ...interface{}) *Element {
+	return TextColor(KMLColorFrom(c).String(), xes...)
+}
+
 // Tilt returns new tilt element.
 func Tilt(value float64, xes ...interface{}) *Element {
 	return FloatElement(ElemTilt, value, xes...)
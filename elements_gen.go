@@ -0,0 +1,907 @@
+// Code generated by genbuilders from bundled XSD schemas. DO NOT EDIT.
+
+package kml
+
+// KML element names.
+const (
+	ElemAddress             = "address"
+	ElemAlias               = "Alias"
+	ElemAtomAuthor          = "atom:author"
+	ElemAtomEmail           = "atom:email"
+	ElemAtomLink            = "atom:link"
+	ElemAtomName            = "atom:name"
+	ElemAtomURI             = "atom:uri"
+	ElemBegin               = "begin"
+	ElemBottomFov           = "bottomFov"
+	ElemChange              = "Change"
+	ElemColorMode           = "colorMode"
+	ElemCookie              = "cookie"
+	ElemCreate              = "Create"
+	ElemDelete              = "Delete"
+	ElemDrawOrder           = "drawOrder"
+	ElemEast                = "east"
+	ElemEnd                 = "end"
+	ElemExpires             = "expires"
+	ElemFill                = "fill"
+	ElemFlyToView           = "flyToView"
+	ElemGridOrigin          = "gridOrigin"
+	ElemGroundOverlay       = "GroundOverlay"
+	ElemGxAltitudeMode      = "gx:altitudeMode"
+	ElemGxAltitudeOffset    = "gx:altitudeOffset"
+	ElemGxAngles            = "gx:angles"
+	ElemGxAnimatedUpdate    = "gx:AnimatedUpdate"
+	ElemGxBalloonVisibility = "gx:balloonVisibility"
+	ElemGxCoord             = "gx:coord"
+	ElemGxDelayedStart      = "gx:delayedStart"
+	ElemGxDrawOrder         = "gx:drawOrder"
+	ElemGxDuration          = "gx:duration"
+	ElemGxFlyTo             = "gx:FlyTo"
+	ElemGxFlyToMode         = "gx:flyToMode"
+	ElemGxH                 = "gx:h"
+	ElemGxHorizFov          = "gx:horizFov"
+	ElemGxInterpolate       = "gx:interpolate"
+	ElemGxLabelVisibility   = "gx:labelVisibility"
+	ElemGxLatLonQuad        = "gx:LatLonQuad"
+	ElemGxMultiTrack        = "gx:MultiTrack"
+	ElemGxOuterColor        = "gx:outerColor"
+	ElemGxOuterWidth        = "gx:outerWidth"
+	ElemGxPhysicalWidth     = "gx:physicalWidth"
+	ElemGxPlayMode          = "gx:playMode"
+	ElemGxPlaylist          = "gx:Playlist"
+	ElemGxRank              = "gx:rank"
+	ElemGxSimpleArrayData   = "gx:SimpleArrayData"
+	ElemGxSimpleArrayField  = "gx:SimpleArrayField"
+	ElemGxSoundCue          = "gx:SoundCue"
+	ElemGxTimeSpan          = "gx:TimeSpan"
+	ElemGxTour              = "gx:Tour"
+	ElemGxTourControl       = "gx:TourControl"
+	ElemGxTrack             = "gx:Track"
+	ElemGxValue             = "gx:value"
+	ElemGxW                 = "gx:w"
+	ElemGxWait              = "gx:Wait"
+	ElemGxX                 = "gx:x"
+	ElemGxY                 = "gx:y"
+	ElemHTTPQuery           = "httpQuery"
+	ElemHotSpot             = "hotSpot"
+	ElemIcon                = "Icon"
+	ElemIconStyle           = "IconStyle"
+	ElemImagePyramid        = "ImagePyramid"
+	ElemItemIcon            = "ItemIcon"
+	ElemKey                 = "key"
+	ElemLatLonAltBox        = "LatLonAltBox"
+	ElemLatLonBox           = "LatLonBox"
+	ElemLeftFov             = "leftFov"
+	ElemLink                = "Link"
+	ElemLinkDescription     = "linkDescription"
+	ElemLinkName            = "linkName"
+	ElemLinkSnippet         = "linkSnippet"
+	ElemListItemType        = "listItemType"
+	ElemListStyle           = "ListStyle"
+	ElemLocation            = "Location"
+	ElemLod                 = "Lod"
+	ElemLookAt              = "LookAt"
+	ElemMaxAltitude         = "maxAltitude"
+	ElemMaxFadeExtent       = "maxFadeExtent"
+	ElemMaxHeight           = "maxHeight"
+	ElemMaxLodPixels        = "maxLodPixels"
+	ElemMaxSessionLength    = "maxSessionLength"
+	ElemMaxSnippetLines     = "maxSnippetLines"
+	ElemMaxWidth            = "maxWidth"
+	ElemMessage             = "message"
+	ElemMetadata            = "Metadata"
+	ElemMinAltitude         = "minAltitude"
+	ElemMinFadeExtent       = "minFadeExtent"
+	ElemMinLodPixels        = "minLodPixels"
+	ElemMinRefreshPeriod    = "minRefreshPeriod"
+	ElemModelScale          = "Scale"
+	ElemNear                = "near"
+	ElemNetworkLink         = "NetworkLink"
+	ElemNetworkLinkControl  = "NetworkLinkControl"
+	ElemNorth               = "north"
+	ElemOpen                = "open"
+	ElemOrientation         = "Orientation"
+	ElemOverlayXY           = "overlayXY"
+	ElemPair                = "Pair"
+	ElemPhoneNumber         = "phoneNumber"
+	ElemPhotoOverlay        = "PhotoOverlay"
+	ElemRange               = "range"
+	ElemRefreshInterval     = "refreshInterval"
+	ElemRefreshMode         = "refreshMode"
+	ElemRefreshVisibility   = "refreshVisibility"
+	ElemRegion              = "Region"
+	ElemResourceMap         = "ResourceMap"
+	ElemRightFov            = "rightFov"
+	ElemRotation            = "rotation"
+	ElemRotationXY          = "rotationXY"
+	ElemScreenOverlay       = "ScreenOverlay"
+	ElemScreenXY            = "screenXY"
+	ElemShape               = "shape"
+	ElemSize                = "size"
+	ElemSnippetDeprecated   = "snippet"
+	ElemSourceHref          = "sourceHref"
+	ElemSouth               = "south"
+	ElemState               = "state"
+	ElemStyleMap            = "StyleMap"
+	ElemTargetHref          = "targetHref"
+	ElemTextColor           = "textColor"
+	ElemTileSize            = "tileSize"
+	ElemTimeSpan            = "TimeSpan"
+	ElemTimeStamp           = "TimeStamp"
+	ElemTopFov              = "topFov"
+	ElemURL                 = "Url"
+	ElemUpdate              = "Update"
+	ElemValue               = "value"
+	ElemViewBoundScale      = "viewBoundScale"
+	ElemViewFormat          = "viewFormat"
+	ElemViewRefreshMode     = "viewRefreshMode"
+	ElemViewRefreshTime     = "viewRefreshTime"
+	ElemViewVolume          = "ViewVolume"
+	ElemVisibility          = "visibility"
+	ElemWest                = "west"
+	ElemWhen                = "when"
+	ElemX                   = "x"
+	ElemY                   = "y"
+	ElemZ                   = "z"
+)
+
+// AltitudeMode valid values.
+const (
+	AltitudeModeClampToGround    = "clampToGround"
+	AltitudeModeRelativeToGround = "relativeToGround"
+	AltitudeModeAbsolute         = "absolute"
+)
+
+// ColorMode valid values.
+const (
+	ColorModeNormal = "normal"
+	ColorModeRandom = "random"
+)
+
+// GridOrigin valid values.
+const (
+	GridOriginLowerLeft = "lowerLeft"
+	GridOriginUpperLeft = "upperLeft"
+)
+
+// GxAltitudeMode valid values.
+const (
+	GxAltitudeModeClampToSeaFloor    = "clampToSeaFloor"
+	GxAltitudeModeRelativeToSeaFloor = "relativeToSeaFloor"
+)
+
+// GxFlyToMode valid values.
+const (
+	GxFlyToModeBounce = "bounce"
+	GxFlyToModeSmooth = "smooth"
+)
+
+// GxPlayMode valid values.
+const (
+	GxPlayModePause = "pause"
+)
+
+// ListItemType valid values.
+const (
+	ListItemTypeRadioFolder       = "radioFolder"
+	ListItemTypeCheck             = "check"
+	ListItemTypeCheckHideChildren = "checkHideChildren"
+	ListItemTypeCheckOffOnly      = "checkOffOnly"
+)
+
+// RefreshMode valid values.
+const (
+	RefreshModeOnChange   = "onChange"
+	RefreshModeOnInterval = "onInterval"
+	RefreshModeOnExpire   = "onExpire"
+)
+
+// Shape valid values.
+const (
+	ShapeRectangle = "rectangle"
+	ShapeCylinder  = "cylinder"
+	ShapeSphere    = "sphere"
+)
+
+// StyleState valid values.
+const (
+	StyleStateNormal    = "normal"
+	StyleStateHighlight = "highlight"
+)
+
+// ViewRefreshMode valid values.
+const (
+	ViewRefreshModeNever     = "never"
+	ViewRefreshModeOnRequest = "onRequest"
+	ViewRefreshModeOnStop    = "onStop"
+	ViewRefreshModeOnRegion  = "onRegion"
+)
+
+// Address returns new address element.
+func Address(value string, xes ...interface{}) *Element {
+	return StringElement(ElemAddress, value, xes...)
+}
+
+// Alias returns new Alias element.
+func Alias(xes ...interface{}) *Element {
+	return NewElement(ElemAlias, xes...)
+}
+
+// AtomAuthor returns new atom:author element.
+func AtomAuthor(xes ...interface{}) *Element {
+	return NewElement(ElemAtomAuthor, xes...)
+}
+
+// AtomEmail returns new atom:email element.
+func AtomEmail(value string, xes ...interface{}) *Element {
+	return StringElement(ElemAtomEmail, value, xes...)
+}
+
+// AtomLink returns new atom:link element.
+func AtomLink(xes ...interface{}) *Element {
+	return NewElement(ElemAtomLink, xes...)
+}
+
+// AtomName returns new atom:name element.
+func AtomName(value string, xes ...interface{}) *Element {
+	return StringElement(ElemAtomName, value, xes...)
+}
+
+// AtomURI returns new atom:uri element.
+func AtomURI(value string, xes ...interface{}) *Element {
+	return StringElement(ElemAtomURI, value, xes...)
+}
+
+// Begin returns new begin element.
+func Begin(value string, xes ...interface{}) *Element {
+	return StringElement(ElemBegin, value, xes...)
+}
+
+// BottomFov returns new bottomFov element.
+func BottomFov(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemBottomFov, value, xes...)
+}
+
+// Change returns new Change element.
+func Change(xes ...interface{}) *Element {
+	return NewElement(ElemChange, xes...)
+}
+
+// ColorMode returns new colorMode element.
+func ColorMode(value string, xes ...interface{}) *Element {
+	return StringElement(ElemColorMode, value, xes...)
+}
+
+// Cookie returns new cookie element.
+func Cookie(value string, xes ...interface{}) *Element {
+	return StringElement(ElemCookie, value, xes...)
+}
+
+// Create returns new Create element.
+func Create(xes ...interface{}) *Element {
+	return NewElement(ElemCreate, xes...)
+}
+
+// Delete returns new Delete element.
+func Delete(xes ...interface{}) *Element {
+	return NewElement(ElemDelete, xes...)
+}
+
+// DrawOrder returns new drawOrder element.
+func DrawOrder(value int, xes ...interface{}) *Element {
+	return IntElement(ElemDrawOrder, value, xes...)
+}
+
+// East returns new east element.
+func East(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemEast, value, xes...)
+}
+
+// End returns new end element.
+func End(value string, xes ...interface{}) *Element {
+	return StringElement(ElemEnd, value, xes...)
+}
+
+// Expires returns new expires element.
+func Expires(value string, xes ...interface{}) *Element {
+	return StringElement(ElemExpires, value, xes...)
+}
+
+// Fill returns new fill element.
+func Fill(value bool, xes ...interface{}) *Element {
+	return BoolElement(ElemFill, value, xes...)
+}
+
+// FlyToView returns new flyToView element.
+func FlyToView(value bool, xes ...interface{}) *Element {
+	return BoolElement(ElemFlyToView, value, xes...)
+}
+
+// GridOrigin returns new gridOrigin element.
+func GridOrigin(value string, xes ...interface{}) *Element {
+	return StringElement(ElemGridOrigin, value, xes...)
+}
+
+// GroundOverlay returns new GroundOverlay element.
+func GroundOverlay(xes ...interface{}) *Element {
+	return NewElement(ElemGroundOverlay, xes...)
+}
+
+// GxAltitudeMode returns new gx:altitudeMode element.
+func GxAltitudeMode(value string, xes ...interface{}) *Element {
+	return StringElement(ElemGxAltitudeMode, value, xes...)
+}
+
+// GxAltitudeOffset returns new gx:altitudeOffset element.
+func GxAltitudeOffset(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemGxAltitudeOffset, value, xes...)
+}
+
+// GxAngles returns new gx:angles element.
+func GxAngles(value string, xes ...interface{}) *Element {
+	return StringElement(ElemGxAngles, value, xes...)
+}
+
+// GxAnimatedUpdate returns new gx:AnimatedUpdate element.
+func GxAnimatedUpdate(xes ...interface{}) *Element {
+	return NewElement(ElemGxAnimatedUpdate, xes...)
+}
+
+// GxBalloonVisibility returns new gx:balloonVisibility element.
+func GxBalloonVisibility(value bool, xes ...interface{}) *Element {
+	return BoolElement(ElemGxBalloonVisibility, value, xes...)
+}
+
+// GxCoord returns new gx:coord element.
+func GxCoord(value string, xes ...interface{}) *Element {
+	return StringElement(ElemGxCoord, value, xes...)
+}
+
+// GxDelayedStart returns new gx:delayedStart element.
+func GxDelayedStart(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemGxDelayedStart, value, xes...)
+}
+
+// GxDrawOrder returns new gx:drawOrder element.
+func GxDrawOrder(value int, xes ...interface{}) *Element {
+	return IntElement(ElemGxDrawOrder, value, xes...)
+}
+
+// GxDuration returns new gx:duration element.
+func GxDuration(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemGxDuration, value, xes...)
+}
+
+// GxFlyTo returns new gx:FlyTo element.
+func GxFlyTo(xes ...interface{}) *Element {
+	return NewElement(ElemGxFlyTo, xes...)
+}
+
+// GxFlyToMode returns new gx:flyToMode element.
+func GxFlyToMode(value string, xes ...interface{}) *Element {
+	return StringElement(ElemGxFlyToMode, value, xes...)
+}
+
+// GxH returns new gx:h element.
+func GxH(value int, xes ...interface{}) *Element {
+	return IntElement(ElemGxH, value, xes...)
+}
+
+// GxHorizFov returns new gx:horizFov element.
+func GxHorizFov(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemGxHorizFov, value, xes...)
+}
+
+// GxInterpolate returns new gx:interpolate element.
+func GxInterpolate(value bool, xes ...interface{}) *Element {
+	return BoolElement(ElemGxInterpolate, value, xes...)
+}
+
+// GxLabelVisibility returns new gx:labelVisibility element.
+func GxLabelVisibility(value bool, xes ...interface{}) *Element {
+	return BoolElement(ElemGxLabelVisibility, value, xes...)
+}
+
+// GxLatLonQuad returns new gx:LatLonQuad element.
+func GxLatLonQuad(xes ...interface{}) *Element {
+	return NewElement(ElemGxLatLonQuad, xes...)
+}
+
+// GxMultiTrack returns new gx:MultiTrack element.
+func GxMultiTrack(xes ...interface{}) *Element {
+	return NewElement(ElemGxMultiTrack, xes...)
+}
+
+// GxOuterColor returns new gx:outerColor element.
+func GxOuterColor(value string, xes ...interface{}) *Element {
+	return StringElement(ElemGxOuterColor, value, xes...)
+}
+
+// GxOuterWidth returns new gx:outerWidth element.
+func GxOuterWidth(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemGxOuterWidth, value, xes...)
+}
+
+// GxPhysicalWidth returns new gx:physicalWidth element.
+func GxPhysicalWidth(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemGxPhysicalWidth, value, xes...)
+}
+
+// GxPlayMode returns new gx:playMode element.
+func GxPlayMode(value string, xes ...interface{}) *Element {
+	return StringElement(ElemGxPlayMode, value, xes...)
+}
+
+// GxPlaylist returns new gx:Playlist element.
+func GxPlaylist(xes ...interface{}) *Element {
+	return NewElement(ElemGxPlaylist, xes...)
+}
+
+// GxRank returns new gx:rank element.
+func GxRank(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemGxRank, value, xes...)
+}
+
+// GxSimpleArrayData returns new gx:SimpleArrayData element.
+func GxSimpleArrayData(xes ...interface{}) *Element {
+	return NewElement(ElemGxSimpleArrayData, xes...)
+}
+
+// GxSimpleArrayField returns new gx:SimpleArrayField element.
+func GxSimpleArrayField(xes ...interface{}) *Element {
+	return NewElement(ElemGxSimpleArrayField, xes...)
+}
+
+// GxSoundCue returns new gx:SoundCue element.
+func GxSoundCue(xes ...interface{}) *Element {
+	return NewElement(ElemGxSoundCue, xes...)
+}
+
+// GxTimeSpan returns new gx:TimeSpan element.
+func GxTimeSpan(xes ...interface{}) *Element {
+	return NewElement(ElemGxTimeSpan, xes...)
+}
+
+// GxTour returns new gx:Tour element.
+func GxTour(xes ...interface{}) *Element {
+	return NewElement(ElemGxTour, xes...)
+}
+
+// GxTourControl returns new gx:TourControl element.
+func GxTourControl(xes ...interface{}) *Element {
+	return NewElement(ElemGxTourControl, xes...)
+}
+
+// GxTrack returns new gx:Track element.
+func GxTrack(xes ...interface{}) *Element {
+	return NewElement(ElemGxTrack, xes...)
+}
+
+// GxValue returns new gx:value element.
+func GxValue(value string, xes ...interface{}) *Element {
+	return StringElement(ElemGxValue, value, xes...)
+}
+
+// GxW returns new gx:w element.
+func GxW(value int, xes ...interface{}) *Element {
+	return IntElement(ElemGxW, value, xes...)
+}
+
+// GxWait returns new gx:Wait element.
+func GxWait(xes ...interface{}) *Element {
+	return NewElement(ElemGxWait, xes...)
+}
+
+// GxX returns new gx:x element.
+func GxX(value int, xes ...interface{}) *Element {
+	return IntElement(ElemGxX, value, xes...)
+}
+
+// GxY returns new gx:y element.
+func GxY(value int, xes ...interface{}) *Element {
+	return IntElement(ElemGxY, value, xes...)
+}
+
+// HTTPQuery returns new httpQuery element.
+func HTTPQuery(value string, xes ...interface{}) *Element {
+	return StringElement(ElemHTTPQuery, value, xes...)
+}
+
+// HotSpot returns new hotSpot element.
+func HotSpot(xes ...interface{}) *Element {
+	return NewElement(ElemHotSpot, xes...)
+}
+
+// Icon returns new Icon element.
+func Icon(xes ...interface{}) *Element {
+	return NewElement(ElemIcon, xes...)
+}
+
+// IconStyle returns new IconStyle element.
+func IconStyle(xes ...interface{}) *Element {
+	return NewElement(ElemIconStyle, xes...)
+}
+
+// ImagePyramid returns new ImagePyramid element.
+func ImagePyramid(xes ...interface{}) *Element {
+	return NewElement(ElemImagePyramid, xes...)
+}
+
+// ItemIcon returns new ItemIcon element.
+func ItemIcon(xes ...interface{}) *Element {
+	return NewElement(ElemItemIcon, xes...)
+}
+
+// Key returns new key element.
+func Key(value string, xes ...interface{}) *Element {
+	return StringElement(ElemKey, value, xes...)
+}
+
+// LatLonAltBox returns new LatLonAltBox element.
+func LatLonAltBox(xes ...interface{}) *Element {
+	return NewElement(ElemLatLonAltBox, xes...)
+}
+
+// LatLonBox returns new LatLonBox element.
+func LatLonBox(xes ...interface{}) *Element {
+	return NewElement(ElemLatLonBox, xes...)
+}
+
+// LeftFov returns new leftFov element.
+func LeftFov(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemLeftFov, value, xes...)
+}
+
+// Link returns new Link element.
+func Link(xes ...interface{}) *Element {
+	return NewElement(ElemLink, xes...)
+}
+
+// LinkDescription returns new linkDescription element.
+func LinkDescription(value string, xes ...interface{}) *Element {
+	return StringElement(ElemLinkDescription, value, xes...)
+}
+
+// LinkName returns new linkName element.
+func LinkName(value string, xes ...interface{}) *Element {
+	return StringElement(ElemLinkName, value, xes...)
+}
+
+// LinkSnippet returns new linkSnippet element.
+func LinkSnippet(value string, xes ...interface{}) *Element {
+	return StringElement(ElemLinkSnippet, value, xes...)
+}
+
+// ListItemType returns new listItemType element.
+func ListItemType(value string, xes ...interface{}) *Element {
+	return StringElement(ElemListItemType, value, xes...)
+}
+
+// ListStyle returns new ListStyle element.
+func ListStyle(xes ...interface{}) *Element {
+	return NewElement(ElemListStyle, xes...)
+}
+
+// Location returns new Location element.
+func Location(xes ...interface{}) *Element {
+	return NewElement(ElemLocation, xes...)
+}
+
+// Lod returns new Lod element.
+func Lod(xes ...interface{}) *Element {
+	return NewElement(ElemLod, xes...)
+}
+
+// LookAt returns new LookAt element.
+func LookAt(xes ...interface{}) *Element {
+	return NewElement(ElemLookAt, xes...)
+}
+
+// MaxAltitude returns new maxAltitude element.
+func MaxAltitude(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemMaxAltitude, value, xes...)
+}
+
+// MaxFadeExtent returns new maxFadeExtent element.
+func MaxFadeExtent(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemMaxFadeExtent, value, xes...)
+}
+
+// MaxHeight returns new maxHeight element.
+func MaxHeight(value int, xes ...interface{}) *Element {
+	return IntElement(ElemMaxHeight, value, xes...)
+}
+
+// MaxLodPixels returns new maxLodPixels element.
+func MaxLodPixels(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemMaxLodPixels, value, xes...)
+}
+
+// MaxSessionLength returns new maxSessionLength element.
+func MaxSessionLength(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemMaxSessionLength, value, xes...)
+}
+
+// MaxSnippetLines returns new maxSnippetLines element.
+func MaxSnippetLines(value int, xes ...interface{}) *Element {
+	return IntElement(ElemMaxSnippetLines, value, xes...)
+}
+
+// MaxWidth returns new maxWidth element.
+func MaxWidth(value int, xes ...interface{}) *Element {
+	return IntElement(ElemMaxWidth, value, xes...)
+}
+
+// Message returns new message element.
+func Message(value string, xes ...interface{}) *Element {
+	return StringElement(ElemMessage, value, xes...)
+}
+
+// Metadata returns new Metadata element.
+func Metadata(xes ...interface{}) *Element {
+	return NewElement(ElemMetadata, xes...)
+}
+
+// MinAltitude returns new minAltitude element.
+func MinAltitude(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemMinAltitude, value, xes...)
+}
+
+// MinFadeExtent returns new minFadeExtent element.
+func MinFadeExtent(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemMinFadeExtent, value, xes...)
+}
+
+// MinLodPixels returns new minLodPixels element.
+func MinLodPixels(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemMinLodPixels, value, xes...)
+}
+
+// MinRefreshPeriod returns new minRefreshPeriod element.
+func MinRefreshPeriod(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemMinRefreshPeriod, value, xes...)
+}
+
+// ModelScale returns new Scale element.
+func ModelScale(xes ...interface{}) *Element {
+	return NewElement(ElemModelScale, xes...)
+}
+
+// Near returns new near element.
+func Near(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemNear, value, xes...)
+}
+
+// NetworkLink returns new NetworkLink element.
+func NetworkLink(xes ...interface{}) *Element {
+	return NewElement(ElemNetworkLink, xes...)
+}
+
+// NetworkLinkControl returns new NetworkLinkControl element.
+func NetworkLinkControl(xes ...interface{}) *Element {
+	return NewElement(ElemNetworkLinkControl, xes...)
+}
+
+// North returns new north element.
+func North(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemNorth, value, xes...)
+}
+
+// Open returns new open element.
+func Open(value bool, xes ...interface{}) *Element {
+	return BoolElement(ElemOpen, value, xes...)
+}
+
+// Orientation returns new Orientation element.
+func Orientation(xes ...interface{}) *Element {
+	return NewElement(ElemOrientation, xes...)
+}
+
+// OverlayXY returns new overlayXY element.
+func OverlayXY(xes ...interface{}) *Element {
+	return NewElement(ElemOverlayXY, xes...)
+}
+
+// Pair returns new Pair element.
+func Pair(xes ...interface{}) *Element {
+	return NewElement(ElemPair, xes...)
+}
+
+// PhoneNumber returns new phoneNumber element.
+func PhoneNumber(value string, xes ...interface{}) *Element {
+	return StringElement(ElemPhoneNumber, value, xes...)
+}
+
+// PhotoOverlay returns new PhotoOverlay element.
+func PhotoOverlay(xes ...interface{}) *Element {
+	return NewElement(ElemPhotoOverlay, xes...)
+}
+
+// Range returns new range element.
+func Range(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemRange, value, xes...)
+}
+
+// RefreshInterval returns new refreshInterval element.
+func RefreshInterval(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemRefreshInterval, value, xes...)
+}
+
+// RefreshMode returns new refreshMode element.
+func RefreshMode(value string, xes ...interface{}) *Element {
+	return StringElement(ElemRefreshMode, value, xes...)
+}
+
+// RefreshVisibility returns new refreshVisibility element.
+func RefreshVisibility(value bool, xes ...interface{}) *Element {
+	return BoolElement(ElemRefreshVisibility, value, xes...)
+}
+
+// Region returns new Region element.
+func Region(xes ...interface{}) *Element {
+	return NewElement(ElemRegion, xes...)
+}
+
+// ResourceMap returns new ResourceMap element.
+func ResourceMap(xes ...interface{}) *Element {
+	return NewElement(ElemResourceMap, xes...)
+}
+
+// RightFov returns new rightFov element.
+func RightFov(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemRightFov, value, xes...)
+}
+
+// Rotation returns new rotation element.
+func Rotation(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemRotation, value, xes...)
+}
+
+// RotationXY returns new rotationXY element.
+func RotationXY(xes ...interface{}) *Element {
+	return NewElement(ElemRotationXY, xes...)
+}
+
+// ScreenOverlay returns new ScreenOverlay element.
+func ScreenOverlay(xes ...interface{}) *Element {
+	return NewElement(ElemScreenOverlay, xes...)
+}
+
+// ScreenXY returns new screenXY element.
+func ScreenXY(xes ...interface{}) *Element {
+	return NewElement(ElemScreenXY, xes...)
+}
+
+// Shape returns new shape element.
+func Shape(value string, xes ...interface{}) *Element {
+	return StringElement(ElemShape, value, xes...)
+}
+
+// Size returns new size element.
+func Size(xes ...interface{}) *Element {
+	return NewElement(ElemSize, xes...)
+}
+
+// SnippetDeprecated returns new snippet element.
+func SnippetDeprecated(value string, xes ...interface{}) *Element {
+	return StringElement(ElemSnippetDeprecated, value, xes...)
+}
+
+// SourceHref returns new sourceHref element.
+func SourceHref(value string, xes ...interface{}) *Element {
+	return StringElement(ElemSourceHref, value, xes...)
+}
+
+// South returns new south element.
+func South(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemSouth, value, xes...)
+}
+
+// State returns new state element.
+func State(value string, xes ...interface{}) *Element {
+	return StringElement(ElemState, value, xes...)
+}
+
+// StyleMap returns new StyleMap element.
+func StyleMap(xes ...interface{}) *Element {
+	return NewElement(ElemStyleMap, xes...)
+}
+
+// TargetHref returns new targetHref element.
+func TargetHref(value string, xes ...interface{}) *Element {
+	return StringElement(ElemTargetHref, value, xes...)
+}
+
+// TextColor returns new textColor element.
+func TextColor(value string, xes ...interface{}) *Element {
+	return StringElement(ElemTextColor, value, xes...)
+}
+
+// TileSize returns new tileSize element.
+func TileSize(value int, xes ...interface{}) *Element {
+	return IntElement(ElemTileSize, value, xes...)
+}
+
+// TimeSpan returns new TimeSpan element.
+func TimeSpan(xes ...interface{}) *Element {
+	return NewElement(ElemTimeSpan, xes...)
+}
+
+// TimeStamp returns new TimeStamp element.
+func TimeStamp(xes ...interface{}) *Element {
+	return NewElement(ElemTimeStamp, xes...)
+}
+
+// TopFov returns new topFov element.
+func TopFov(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemTopFov, value, xes...)
+}
+
+// URL returns new Url element.
+func URL(xes ...interface{}) *Element {
+	return NewElement(ElemURL, xes...)
+}
+
+// Update returns new Update element.
+func Update(xes ...interface{}) *Element {
+	return NewElement(ElemUpdate, xes...)
+}
+
+// Value returns new value element.
+func Value(value string, xes ...interface{}) *Element {
+	return StringElement(ElemValue, value, xes...)
+}
+
+// ViewBoundScale returns new viewBoundScale element.
+func ViewBoundScale(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemViewBoundScale, value, xes...)
+}
+
+// ViewFormat returns new viewFormat element.
+func ViewFormat(value string, xes ...interface{}) *Element {
+	return StringElement(ElemViewFormat, value, xes...)
+}
+
+// ViewRefreshMode returns new viewRefreshMode element.
+func ViewRefreshMode(value string, xes ...interface{}) *Element {
+	return StringElement(ElemViewRefreshMode, value, xes...)
+}
+
+// ViewRefreshTime returns new viewRefreshTime element.
+func ViewRefreshTime(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemViewRefreshTime, value, xes...)
+}
+
+// ViewVolume returns new ViewVolume element.
+func ViewVolume(xes ...interface{}) *Element {
+	return NewElement(ElemViewVolume, xes...)
+}
+
+// Visibility returns new visibility element.
+func Visibility(value bool, xes ...interface{}) *Element {
+	return BoolElement(ElemVisibility, value, xes...)
+}
+
+// West returns new west element.
+func West(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemWest, value, xes...)
+}
+
+// When returns new when element.
+func When(value string, xes ...interface{}) *Element {
+	return StringElement(ElemWhen, value, xes...)
+}
+
+// X returns new x element.
+func X(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemX, value, xes...)
+}
+
+// Y returns new y element.
+func Y(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemY, value, xes...)
+}
+
+// Z returns new z element.
+func Z(value float64, xes ...interface{}) *Element {
+	return FloatElement(ElemZ, value, xes...)
+}
@@ -23,25 +23,38 @@ func Test_InPlaceElement_Rendering(t *testing.T) {
 		{kml.Camera(), `<Camera></Camera>`},
 		{kml.Color("ffffffff"), `<color>ffffffff</color>`},
 		{kml.Coordinates("0.1,0.2,0.3 1.1,1.2,1.3"), `<coordinates>0.1,0.2,0.3 1.1,1.2,1.3</coordinates>`},
-		{kml.Data("name"), `<data name="name"></data>`},
+		{kml.Data("name"), `<Data name="name"></Data>`},
+		{kml.AtomLink(kml.Attr("href", "http://example.com")), `<atom:link href="http://example.com"></atom:link>`},
 		{kml.Description("desc"), `<description>desc</description>`},
 		{kml.DisplayName("name"), `<displayName>name</displayName>`},
 		{kml.Document(), `<Document></Document>`},
 		{kml.ExtendedData(), `<ExtendedData></ExtendedData>`},
+		{kml.DrawOrder(2), `<drawOrder>2</drawOrder>`},
+		{kml.Extrude(true), `<extrude>1</extrude>`},
 		{kml.Folder(), `<Folder></Folder>`},
 		{kml.GxOption("sunlight", true), `<gx:option name="sunlight" enabled="1"></gx:option>`},
 		{kml.GxTimeStamp(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)), `<gx:TimeStamp><when>2020-01-01T00:00:00Z</when></gx:TimeStamp>`},
+		{kml.GxTrack(kml.GxAltitudeMode(kml.GxAltitudeModeClampToSeaFloor)), `<gx:Track><gx:altitudeMode>clampToSeaFloor</gx:altitudeMode></gx:Track>`},
 		{kml.GxViewerOptions(), `<gx:ViewerOptions></gx:ViewerOptions>`},
 		{kml.Heading(1.234), `<heading>1.234</heading>`},
+		{kml.Href("files/icon.png"), `<href>files/icon.png</href>`},
+		{kml.InnerBoundaryIs(), `<innerBoundaryIs></innerBoundaryIs>`},
 		{kml.LabelStyle(), `<LabelStyle></LabelStyle>`},
 		{kml.Latitude(1.234), `<latitude>1.234</latitude>`},
 		{kml.LineString(), `<LineString></LineString>`},
+		{kml.LinearRing(), `<LinearRing></LinearRing>`},
 		{kml.Longitude(1.234), `<longitude>1.234</longitude>`},
+		{kml.Model(), `<Model></Model>`},
+		{kml.ModelScale(kml.X(1), kml.Y(2), kml.Z(3)), `<Scale><x>1</x><y>2</y><z>3</z></Scale>`},
 		{kml.MultiGeometry(), `<MultiGeometry></MultiGeometry>`},
 		{kml.Name("value"), `<name>value</name>`},
+		{kml.OuterBoundaryIs(), `<outerBoundaryIs></outerBoundaryIs>`},
 		{kml.Outline(true), `<outline>1</outline>`},
 		{kml.Placemark(), `<Placemark></Placemark>`},
+		{kml.Point(), `<Point></Point>`},
 		{kml.PolyStyle(), `<PolyStyle></PolyStyle>`},
+		{kml.Polygon(), `<Polygon></Polygon>`},
+		{kml.RefreshMode(kml.RefreshModeOnExpire), `<refreshMode>onExpire</refreshMode>`},
 		{kml.Roll(1.234), `<roll>1.234</roll>`},
 		{kml.Scale(1.234), `<scale>1.234</scale>`},
 		{kml.Schema("id", "name"), `<Schema name="name" id="id"></Schema>`},
@@ -54,6 +67,7 @@ func Test_InPlaceElement_Rendering(t *testing.T) {
 		{kml.Tessellate(false), `<tessellate>0</tessellate>`},
 		{kml.Text("value"), `<text>value</text>`},
 		{kml.Tilt(1.234), `<tilt>1.234</tilt>`},
+		{kml.Visibility(false), `<visibility>0</visibility>`},
 		{kml.Width(1.234), `<width>1.234</width>`},
 	}
 
@@ -0,0 +1,803 @@
+package kml
+
+import (
+	"math"
+)
+
+// This file implements the inverse geodesic problem and geodesic polygon
+// areas on the WGS84 ellipsoid following C. F. F. Karney, Algorithms for
+// geodesics, J. Geodesy 87, 43–55 (2013). The implementation is a port of
+// the GeographicLib C library with series expanded to the sixth order.
+
+// geodOrder is the order of the series expansions.
+const geodOrder = 6
+
+// Tolerances used by the inverse method.
+const (
+	geodTol0    = 0x1p-52              // Machine epsilon.
+	geodTol1    = 200 * geodTol0       // Tolerance of the strip near cut.
+	geodTol2    = 0x1p-26              // Square root of machine epsilon.
+	geodTolB    = geodTol0             // Tolerance of the bisection.
+	geodXThresh = 1000 * geodTol2      // Threshold of the strip near cut.
+	geodTiny    = 0x1p-511             // Square root of the smallest normal.
+	geodMaxIt1  = 20                   // Newton iterations.
+	geodMaxIt2  = geodMaxIt1 + 53 + 10 // Newton and bisection iterations.
+)
+
+// WGS84 derived parameters.
+var (
+	geodF1  = 1 - wgs84F                 // One minus flattening.
+	geodE2  = wgs84F * (2 - wgs84F)      // Eccentricity squared.
+	geodEp2 = geodE2 / (geodF1 * geodF1) // Second eccentricity squared.
+	geodN   = wgs84F / (2 - wgs84F)      // Third flattening.
+	// Authalic radius squared.
+	geodC2 = (wgs84A*wgs84A + wgs84B*wgs84B*math.Atanh(math.Sqrt(geodE2))/math.Sqrt(geodE2)) / 2
+	// Tolerance of really short lines.
+	geodEtol2 = 0.1 * geodTol2 / math.Sqrt(math.Max(0.001, wgs84F)*math.Min(1, 1-wgs84F/2)/2)
+	geodA3x   = geodA3Coeff()
+	geodC3x   = geodC3Coeff()
+	geodC4x   = geodC4Coeff()
+)
+
+// geodesicInverse solves the inverse geodesic problem between points at
+// lat1, lon1 and lat2, lon2 given in degrees. Returns length of the
+// geodesic in meters and signed area in square meters between the
+// geodesic from point 1 to point 2 and the equator.
+func geodesicInverse(lat1, lon1, lat2, lon2 float64) (s12, area float64) {
+	// Bring the points to canonical form 0 <= lon12 <= 180,
+	// -90 <= lat1 <= -0, lat1 <= lat2 <= -lat1.
+	lon12, lon12s := angDiff(lon1, lon2)
+	lonSign := 1.0
+	if math.Signbit(lon12) {
+		lonSign = -1
+	}
+	lon12 *= lonSign
+	lon12s *= lonSign
+	lam12 := lon12 * rad
+	slam12, clam12 := sincosde(lon12, lon12s)
+	lon12s = (180 - lon12) - lon12s // Supplementary longitude difference.
+
+	lat1 = angRound(latFix(lat1))
+	lat2 = angRound(latFix(lat2))
+	swap := 1.0
+	if math.Abs(lat1) < math.Abs(lat2) || math.IsNaN(lat2) {
+		swap = -1
+		lonSign *= -1
+		lat1, lat2 = lat2, lat1
+	}
+	latSign := -1.0
+	if math.Signbit(lat1) {
+		latSign = 1
+	}
+	lat1 *= latSign
+	lat2 *= latSign
+
+	sbet1, cbet1 := sincosd(lat1)
+	sbet1 *= geodF1
+	sbet1, cbet1 = norm2(sbet1, cbet1)
+	cbet1 = math.Max(geodTiny, cbet1)
+
+	sbet2, cbet2 := sincosd(lat2)
+	sbet2 *= geodF1
+	sbet2, cbet2 = norm2(sbet2, cbet2)
+	cbet2 = math.Max(geodTiny, cbet2)
+
+	// Force bet2 = ±bet1 when the difference vanishes.
+	if cbet1 < -sbet1 {
+		if cbet2 == cbet1 {
+			sbet2 = math.Copysign(sbet1, sbet2)
+		}
+	} else if math.Abs(sbet2) == -sbet1 {
+		cbet2 = cbet1
+	}
+
+	dn1 := math.Sqrt(1 + geodEp2*sbet1*sbet1)
+	dn2 := math.Sqrt(1 + geodEp2*sbet2*sbet2)
+
+	var (
+		sig12, s12x, m12x          float64
+		salp1, calp1, salp2, calp2 float64
+		ca                         [geodOrder + 1]float64
+	)
+	// somg12 = 2 marks that it needs to be calculated.
+	omg12, somg12, comg12 := 0.0, 2.0, 0.0
+
+	meridian := lat1 == -90 || slam12 == 0
+	if meridian {
+		// Endpoints are on a single full meridian.
+		calp1, salp1 = clam12, slam12
+		calp2, salp2 = 1, 0
+
+		ssig1, csig1 := sbet1, calp1*cbet1
+		ssig2, csig2 := sbet2, calp2*cbet2
+		sig12 = math.Atan2(math.Max(0, csig1*ssig2-ssig1*csig2)+0, csig1*csig2+ssig1*ssig2)
+		s12x, m12x, _ = geodLengths(geodN, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2)
+		if sig12 < 1 || m12x >= 0 {
+			if sig12 < 3*geodTiny || (sig12 < geodTol0 && (s12x < 0 || m12x < 0)) {
+				sig12, m12x, s12x = 0, 0, 0
+			}
+			m12x *= wgs84B
+			s12x *= wgs84B
+		} else {
+			// Prolate and too close to antipodal.
+			meridian = false
+		}
+	}
+
+	if !meridian && sbet1 == 0 && lon12s >= wgs84F*180 {
+		// Geodesic runs along the equator.
+		calp1, calp2, salp1, salp2 = 0, 0, 1, 1
+		s12x = wgs84A * lam12
+		sig12 = lam12 / geodF1
+		omg12 = sig12
+	} else if !meridian {
+		var dnm float64
+		sig12, salp1, calp1, salp2, calp2, dnm = geodInverseStart(sbet1, cbet1, dn1, sbet2, cbet2, dn2, lam12, slam12, clam12)
+
+		if sig12 >= 0 {
+			// Short lines.
+			s12x = sig12 * wgs84B * dnm
+			omg12 = lam12 / (geodF1 * dnm)
+		} else {
+			// Newton's method with bracketing of the root.
+			var ssig1, csig1, ssig2, csig2, eps, domg12 float64
+			salp1a, calp1a, salp1b, calp1b := geodTiny, 1.0, geodTiny, -1.0
+			tripN, tripB := false, false
+			for numit := 0; ; numit++ {
+				var v, dv float64
+				v, salp2, calp2, sig12, ssig1, csig1, ssig2, csig2, eps, domg12, dv = geodLambda12(
+					sbet1, cbet1, dn1, sbet2, cbet2, dn2, salp1, calp1, slam12, clam12, numit < geodMaxIt1)
+				tol := geodTol0
+				if tripN {
+					tol *= 8
+				}
+				if tripB || !(math.Abs(v) >= tol) || numit == geodMaxIt2 {
+					break
+				}
+				if v > 0 && (numit > geodMaxIt1 || calp1/salp1 > calp1b/salp1b) {
+					salp1b, calp1b = salp1, calp1
+				} else if v < 0 && (numit > geodMaxIt1 || calp1/salp1 < calp1a/salp1a) {
+					salp1a, calp1a = salp1, calp1
+				}
+				if numit < geodMaxIt1 && dv > 0 {
+					dalp1 := -v / dv
+					if math.Abs(dalp1) < math.Pi {
+						sdalp1, cdalp1 := math.Sincos(dalp1)
+						nsalp1 := salp1*cdalp1 + calp1*sdalp1
+						if nsalp1 > 0 {
+							calp1 = calp1*cdalp1 - salp1*sdalp1
+							salp1, calp1 = norm2(nsalp1, calp1)
+							tripN = math.Abs(v) <= 16*geodTol0
+							continue
+						}
+					}
+				}
+				// Bisect when Newton's step is not usable.
+				salp1, calp1 = norm2((salp1a+salp1b)/2, (calp1a+calp1b)/2)
+				tripN = false
+				tripB = math.Abs(salp1a-salp1)+(calp1a-calp1) < geodTolB ||
+					math.Abs(salp1-salp1b)+(calp1-calp1b) < geodTolB
+			}
+			s12x, _, _ = geodLengths(eps, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2)
+			s12x *= wgs84B
+			sdomg12, cdomg12 := math.Sincos(domg12)
+			somg12 = slam12*cdomg12 - clam12*sdomg12
+			comg12 = clam12*cdomg12 + slam12*sdomg12
+		}
+	}
+
+	// Area between the geodesic and the equator.
+	salp0 := salp1 * cbet1
+	calp0 := math.Hypot(calp1, salp1*sbet1)
+	if calp0 != 0 && salp0 != 0 {
+		ssig1, csig1 := norm2(sbet1, calp1*cbet1)
+		ssig2, csig2 := norm2(sbet2, calp2*cbet2)
+		k2 := calp0 * calp0 * geodEp2
+		eps := k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+		a4 := wgs84A * wgs84A * calp0 * salp0 * geodE2
+		geodC4f(eps, ca[:])
+		area = a4 * (sinCosSeries(false, ssig2, csig2, ca[:geodOrder]) -
+			sinCosSeries(false, ssig1, csig1, ca[:geodOrder]))
+	}
+
+	if !meridian && somg12 == 2 {
+		somg12, comg12 = math.Sincos(omg12)
+	}
+
+	var alp12 float64
+	if !meridian && comg12 > -0.7071 && sbet2-sbet1 < 1.75 {
+		// Long and latitude differences are not too big.
+		domg12, dbet1, dbet2 := 1+comg12, 1+cbet1, 1+cbet2
+		alp12 = 2 * math.Atan2(somg12*(sbet1*dbet2+sbet2*dbet1), domg12*(sbet1*sbet2+dbet1*dbet2))
+	} else {
+		salp12 := salp2*calp1 - calp2*salp1
+		calp12 := calp2*calp1 + salp2*salp1
+		if salp12 == 0 && calp12 < 0 {
+			salp12 = geodTiny * calp1
+			calp12 = -1
+		}
+		alp12 = math.Atan2(salp12, calp12)
+	}
+	area += geodC2 * alp12
+	area *= swap * lonSign * latSign
+
+	return 0 + s12x, 0 + area
+}
+
+// geodInverseStart returns starting point for Newton's method in salp1
+// and calp1 with sig12 set to -1. If Newton's method doesn't need to be
+// used it returns non negative sig12 and salp2, calp2 and dnm as well.
+func geodInverseStart(
+	sbet1, cbet1, dn1, sbet2, cbet2, dn2, lam12, slam12, clam12 float64,
+) (sig12, salp1, calp1, salp2, calp2, dnm float64) {
+
+	sig12 = -1
+	sbet12 := sbet2*cbet1 - cbet2*sbet1
+	cbet12 := cbet2*cbet1 + sbet2*sbet1
+	sbet12a := sbet2*cbet1 + cbet2*sbet1
+	shortLine := cbet12 >= 0 && sbet12 < 0.5 && cbet2*lam12 < 0.5
+
+	var somg12, comg12 float64
+	if shortLine {
+		sbetm2 := (sbet1 + sbet2) * (sbet1 + sbet2)
+		sbetm2 /= sbetm2 + (cbet1+cbet2)*(cbet1+cbet2)
+		dnm = math.Sqrt(1 + geodEp2*sbetm2)
+		somg12, comg12 = math.Sincos(lam12 / (geodF1 * dnm))
+	} else {
+		somg12, comg12 = slam12, clam12
+	}
+
+	salp1 = cbet2 * somg12
+	if comg12 >= 0 {
+		calp1 = sbet12 + cbet2*sbet1*somg12*somg12/(1+comg12)
+	} else {
+		calp1 = sbet12a - cbet2*sbet1*somg12*somg12/(1-comg12)
+	}
+
+	ssig12 := math.Hypot(salp1, calp1)
+	csig12 := sbet1*sbet2 + cbet1*cbet2*comg12
+
+	switch {
+	case shortLine && ssig12 < geodEtol2:
+		// Really short lines.
+		salp2 = cbet1 * somg12
+		if comg12 >= 0 {
+			calp2 = sbet12 - cbet1*sbet2*somg12*somg12/(1+comg12)
+		} else {
+			calp2 = sbet12 - cbet1*sbet2*(1-comg12)
+		}
+		salp2, calp2 = norm2(salp2, calp2)
+		sig12 = math.Atan2(ssig12, csig12)
+
+	case math.Abs(geodN) > 0.1 || csig12 >= 0 || ssig12 >= 6*math.Abs(geodN)*math.Pi*cbet1*cbet1:
+		// Zeroth order spherical approximation is OK.
+
+	default:
+		// Scale lam12 and bet2 to x, y coordinate system where antipodal
+		// point is at origin and singular point is at y = 0, x = -1.
+		lam12x := math.Atan2(-slam12, -clam12)
+		k2 := sbet1 * sbet1 * geodEp2
+		eps := k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+		lamScale := wgs84F * cbet1 * geodA3f(eps) * math.Pi
+		betScale := lamScale * cbet1
+		x := lam12x / lamScale
+		y := sbet12a / betScale
+
+		if y > -geodTol1 && x > -1-geodXThresh {
+			// Strip near cut.
+			salp1 = math.Min(1, -x)
+			calp1 = -math.Sqrt(1 - salp1*salp1)
+		} else {
+			// Estimate alp1 by solving the astroid problem.
+			k := astroid(x, y)
+			omg12a := lamScale * (-x * k / (1 + k))
+			somg12, comg12 = math.Sincos(omg12a)
+			comg12 = -comg12
+			salp1 = cbet2 * somg12
+			calp1 = sbet12a - cbet2*sbet1*somg12*somg12/(1-comg12)
+		}
+	}
+
+	// Sanity check on starting guess, backwards check allows NaN through.
+	if !(salp1 <= 0) {
+		salp1, calp1 = norm2(salp1, calp1)
+	} else {
+		salp1, calp1 = 1, 0
+	}
+	return sig12, salp1, calp1, salp2, calp2, dnm
+}
+
+// geodLambda12 returns difference between the longitude reached by the
+// geodesic starting at bet1 with azimuth alp1 and the target longitude
+// lam12 along with quantities describing the geodesic. When diffp is true
+// derivative of the difference with respect to alp1 is also returned.
+func geodLambda12(
+	sbet1, cbet1, dn1, sbet2, cbet2, dn2, salp1, calp1, slam120, clam120 float64,
+	diffp bool,
+) (lam12, salp2, calp2, sig12, ssig1, csig1, ssig2, csig2, eps, domg12, dlam12 float64) {
+
+	if sbet1 == 0 && calp1 == 0 {
+		// Break degeneracy of equatorial line.
+		calp1 = -geodTiny
+	}
+
+	salp0 := salp1 * cbet1
+	calp0 := math.Hypot(calp1, salp1*sbet1)
+
+	ssig1 = sbet1
+	somg1 := salp0 * sbet1
+	csig1 = calp1 * cbet1
+	comg1 := csig1
+	ssig1, csig1 = norm2(ssig1, csig1)
+
+	// Enforce symmetries in the case |bet2| = -bet1.
+	if cbet2 != cbet1 {
+		salp2 = salp0 / cbet2
+	} else {
+		salp2 = salp1
+	}
+	if cbet2 != cbet1 || math.Abs(sbet2) != -sbet1 {
+		var d float64
+		if cbet1 < -sbet1 {
+			d = (cbet2 - cbet1) * (cbet1 + cbet2)
+		} else {
+			d = (sbet1 - sbet2) * (sbet1 + sbet2)
+		}
+		calp2 = math.Sqrt(calp1*cbet1*calp1*cbet1+d) / cbet2
+	} else {
+		calp2 = math.Abs(calp1)
+	}
+
+	ssig2 = sbet2
+	somg2 := salp0 * sbet2
+	csig2 = calp2 * cbet2
+	comg2 := csig2
+	ssig2, csig2 = norm2(ssig2, csig2)
+
+	sig12 = math.Atan2(math.Max(0, csig1*ssig2-ssig1*csig2)+0, csig1*csig2+ssig1*ssig2)
+	somg12 := math.Max(0, comg1*somg2-somg1*comg2) + 0
+	comg12 := comg1*comg2 + somg1*somg2
+	eta := math.Atan2(somg12*clam120-comg12*slam120, comg12*clam120+somg12*slam120)
+
+	k2 := calp0 * calp0 * geodEp2
+	eps = k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+	var ca [geodOrder]float64
+	geodC3f(eps, ca[:])
+	b312 := sinCosSeries(true, ssig2, csig2, ca[:]) - sinCosSeries(true, ssig1, csig1, ca[:])
+	domg12 = -wgs84F * geodA3f(eps) * salp0 * (sig12 + b312)
+	lam12 = eta + domg12
+
+	if diffp {
+		if calp2 == 0 {
+			dlam12 = -2 * geodF1 * dn1 / sbet1
+		} else {
+			_, dlam12, _ = geodLengths(eps, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2)
+			dlam12 *= geodF1 / (calp2 * cbet2)
+		}
+	}
+	return lam12, salp2, calp2, sig12, ssig1, csig1, ssig2, csig2, eps, domg12, dlam12
+}
+
+// geodLengths returns distance s12b and reduced length m12b divided by
+// the semi-minor axis and the coefficient m0 of the secular term of the
+// reduced length.
+func geodLengths(eps, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2 float64) (s12b, m12b, m0 float64) {
+	var c1, c2 [geodOrder + 1]float64
+	a1 := geodA1m1f(eps)
+	geodC1f(eps, c1[:])
+	a2 := geodA2m1f(eps)
+	geodC2f(eps, c2[:])
+	m0 = a1 - a2
+	a1++
+	a2++
+
+	b1 := sinCosSeries(true, ssig2, csig2, c1[:]) - sinCosSeries(true, ssig1, csig1, c1[:])
+	b2 := sinCosSeries(true, ssig2, csig2, c2[:]) - sinCosSeries(true, ssig1, csig1, c2[:])
+	s12b = a1 * (sig12 + b1)
+	j12 := m0*sig12 + (a1*b1 - a2*b2)
+	m12b = dn2*(csig1*ssig2) - dn1*(ssig1*csig2) - csig1*csig2*j12
+	return s12b, m12b, m0
+}
+
+// astroid returns positive root k of
+// k^4 + 2k^3 - (x^2 + y^2 - 1)k^2 - 2y^2k - y^2 = 0.
+func astroid(x, y float64) float64 {
+	p, q := x*x, y*y
+	r := (p + q - 1) / 6
+	if q == 0 && r <= 0 {
+		return 0
+	}
+
+	s := p * q / 4
+	r2 := r * r
+	r3 := r * r2
+	disc := s * (s + 2*r3)
+	u := r
+	if disc >= 0 {
+		t3 := s + r3
+		// Pick the sign on the sqrt to maximize |t3|.
+		if t3 < 0 {
+			t3 -= math.Sqrt(disc)
+		} else {
+			t3 += math.Sqrt(disc)
+		}
+		t := math.Cbrt(t3)
+		u += t
+		if t != 0 {
+			u += r2 / t
+		}
+	} else {
+		ang := math.Atan2(math.Sqrt(-disc), -(s + r3))
+		u += 2 * r * math.Cos(ang/3)
+	}
+	v := math.Sqrt(u*u + q)
+	uv := u + v
+	if u < 0 {
+		uv = q / (v - u)
+	}
+	w := (uv - q) / (2 * v)
+	return uv / (math.Sqrt(uv+w*w) + w)
+}
+
+// sinCosSeries evaluates sum of c[l] * sin(2lx) for l = 1..len(c)-1 when
+// sinp is true, otherwise sum of c[l] * cos((2l+1)x) for l = 0..len(c)-1,
+// using Clenshaw summation.
+func sinCosSeries(sinp bool, sinx, cosx float64, c []float64) float64 {
+	k := len(c)
+	n := k
+	if sinp {
+		n--
+	}
+	ar := 2 * (cosx - sinx) * (cosx + sinx) // 2 * cos(2x)
+	var y0, y1 float64
+	if n&1 != 0 {
+		k--
+		y0 = c[k]
+	}
+	for n /= 2; n > 0; n-- {
+		k--
+		y1 = ar*y0 - y1 + c[k]
+		k--
+		y0 = ar*y1 - y0 + c[k]
+	}
+	if sinp {
+		return 2 * sinx * cosx * y0 // sin(2x) * y0
+	}
+	return cosx * (y0 - y1) // cos(x) * (y0 - y1)
+}
+
+// polyval evaluates polynomial of order n with coefficients p, highest
+// order first, at x.
+func polyval(n int, p []float64, x float64) float64 {
+	if n < 0 {
+		return 0
+	}
+	y := p[0]
+	for i := 1; i <= n; i++ {
+		y = y*x + p[i]
+	}
+	return y
+}
+
+// geodA1m1f returns A1 - 1.
+func geodA1m1f(eps float64) float64 {
+	coeff := []float64{1, 4, 64, 0, 256}
+	m := geodOrder / 2
+	t := polyval(m, coeff, eps*eps) / coeff[m+1]
+	return (t + eps) / (1 - eps)
+}
+
+// geodC1f sets c[1..geodOrder] to C1 coefficients.
+func geodC1f(eps float64, c []float64) {
+	coeff := []float64{
+		-1, 6, -16, 32,
+		-9, 64, -128, 2048,
+		9, -16, 768,
+		3, -5, 512,
+		-7, 1280,
+		-7, 2048,
+	}
+	geodSeries(eps, coeff, c)
+}
+
+// geodA2m1f returns A2 - 1.
+func geodA2m1f(eps float64) float64 {
+	coeff := []float64{-11, -28, -192, 0, 256}
+	m := geodOrder / 2
+	t := polyval(m, coeff, eps*eps) / coeff[m+1]
+	return (t - eps) / (1 + eps)
+}
+
+// geodC2f sets c[1..geodOrder] to C2 coefficients.
+func geodC2f(eps float64, c []float64) {
+	coeff := []float64{
+		1, 2, 16, 32,
+		35, 64, 384, 2048,
+		15, 80, 768,
+		7, 35, 512,
+		63, 1280,
+		77, 2048,
+	}
+	geodSeries(eps, coeff, c)
+}
+
+// geodSeries sets c[1..geodOrder] to coefficients of C1 or C2 series
+// with polynomials in eps^2 given in coeff.
+func geodSeries(eps float64, coeff, c []float64) {
+	eps2 := eps * eps
+	d := eps
+	o := 0
+	for l := 1; l <= geodOrder; l++ {
+		m := (geodOrder - l) / 2
+		c[l] = d * polyval(m, coeff[o:], eps2) / coeff[o+m+1]
+		o += m + 2
+		d *= eps
+	}
+}
+
+// geodA3f returns A3.
+func geodA3f(eps float64) float64 {
+	return polyval(geodOrder-1, geodA3x[:], eps)
+}
+
+// geodC3f sets c[1..geodOrder-1] to C3 coefficients.
+func geodC3f(eps float64, c []float64) {
+	mult := 1.0
+	o := 0
+	for l := 1; l < geodOrder; l++ {
+		m := geodOrder - l - 1
+		mult *= eps
+		c[l] = mult * polyval(m, geodC3x[o:], eps)
+		o += m + 1
+	}
+}
+
+// geodC4f sets c[0..geodOrder-1] to C4 coefficients.
+func geodC4f(eps float64, c []float64) {
+	mult := 1.0
+	o := 0
+	for l := 0; l < geodOrder; l++ {
+		m := geodOrder - l - 1
+		c[l] = mult * polyval(m, geodC4x[o:], eps)
+		o += m + 1
+		mult *= eps
+	}
+}
+
+// geodA3Coeff returns coefficients of A3 polynomial in eps.
+func geodA3Coeff() [geodOrder]float64 {
+	coeff := []float64{
+		-3, 128,
+		-2, -3, 64,
+		-1, -3, -1, 16,
+		3, -1, -2, 8,
+		1, -1, 2,
+		1, 1,
+	}
+	var x [geodOrder]float64
+	o, k := 0, 0
+	for j := geodOrder - 1; j >= 0; j-- {
+		m := geodOrder - j - 1
+		if j < m {
+			m = j
+		}
+		x[k] = polyval(m, coeff[o:], geodN) / coeff[o+m+1]
+		k++
+		o += m + 2
+	}
+	return x
+}
+
+// geodC3Coeff returns coefficients of C3 polynomials in eps.
+func geodC3Coeff() [geodOrder * (geodOrder - 1) / 2]float64 {
+	coeff := []float64{
+		3, 128,
+		2, 5, 128,
+		-1, 3, 3, 64,
+		-1, 0, 1, 8,
+		-1, 1, 4,
+		5, 256,
+		1, 3, 128,
+		-3, -2, 3, 64,
+		1, -3, 2, 32,
+		7, 512,
+		-10, 9, 384,
+		5, -9, 5, 192,
+		7, 512,
+		-14, 7, 512,
+		21, 2560,
+	}
+	var x [geodOrder * (geodOrder - 1) / 2]float64
+	o, k := 0, 0
+	for l := 1; l < geodOrder; l++ {
+		for j := geodOrder - 1; j >= l; j-- {
+			m := geodOrder - j - 1
+			if j < m {
+				m = j
+			}
+			x[k] = polyval(m, coeff[o:], geodN) / coeff[o+m+1]
+			k++
+			o += m + 2
+		}
+	}
+	return x
+}
+
+// geodC4Coeff returns coefficients of C4 polynomials in eps.
+func geodC4Coeff() [geodOrder * (geodOrder + 1) / 2]float64 {
+	coeff := []float64{
+		97, 15015,
+		1088, 156, 45045,
+		-224, -4784, 1573, 45045,
+		-10656, 14144, -4576, -858, 45045,
+		64, 624, -4576, 6864, -3003, 15015,
+		100, 208, 572, 3432, -12012, 30030, 45045,
+		1, 9009,
+		-2944, 468, 135135,
+		5792, 1040, -1287, 135135,
+		5952, -11648, 9152, -2574, 135135,
+		-64, -624, 4576, -6864, 3003, 135135,
+		8, 10725,
+		1856, -936, 225225,
+		-8448, 4992, -1144, 225225,
+		-1440, 4160, -4576, 1716, 225225,
+		-136, 63063,
+		1024, -208, 105105,
+		3584, -3328, 1144, 315315,
+		-128, 135135,
+		-2560, 832, 405405,
+		128, 99099,
+	}
+	var x [geodOrder * (geodOrder + 1) / 2]float64
+	o, k := 0, 0
+	for l := 0; l < geodOrder; l++ {
+		for j := geodOrder - 1; j >= l; j-- {
+			m := geodOrder - j - 1
+			x[k] = polyval(m, coeff[o:], geodN) / coeff[o+m+1]
+			k++
+			o += m + 2
+		}
+	}
+	return x
+}
+
+// norm2 returns sine and cosine scaled to unit length.
+func norm2(s, c float64) (float64, float64) {
+	r := math.Hypot(s, c)
+	return s / r, c / r
+}
+
+// angRound rounds tiny angles so small differences from zero are treated
+// as zero.
+func angRound(x float64) float64 {
+	const z = 1.0 / 16
+	y := math.Abs(x)
+	if w := z - y; w > 0 {
+		y = z - w
+	}
+	return math.Copysign(y, x)
+}
+
+// latFix returns NaN for latitudes outside range -90 to 90.
+func latFix(lat float64) float64 {
+	if math.Abs(lat) > 90 {
+		return math.NaN()
+	}
+	return lat
+}
+
+// angNormalize returns angle in degrees reduced to range -180 to 180.
+func angNormalize(x float64) float64 {
+	y := math.Remainder(x, 360)
+	if math.Abs(y) == 180 {
+		return math.Copysign(180, x)
+	}
+	return y
+}
+
+// twoSum returns sum of u and v and the rounding error of the sum.
+func twoSum(u, v float64) (s, t float64) {
+	s = u + v
+	up := s - v
+	vpp := s - up
+	up -= u
+	vpp -= v
+	if s != 0 {
+		t = 0 - (up + vpp)
+	} else {
+		t = s
+	}
+	return s, t
+}
+
+// angDiff returns difference y - x in degrees reduced to range -180 to
+// 180 and the rounding error of the difference.
+func angDiff(x, y float64) (d, e float64) {
+	d, e = twoSum(math.Remainder(-x, 360), math.Remainder(y, 360))
+	d, e = twoSum(math.Remainder(d, 360), e)
+	if d == 0 || math.Abs(d) == 180 {
+		if e == 0 {
+			d = math.Copysign(d, y-x)
+		} else {
+			d = math.Copysign(d, -e)
+		}
+	}
+	return d, e
+}
+
+// sincosd returns sine and cosine of angle in degrees with exact values
+// for multiples of 90 degrees.
+func sincosd(x float64) (float64, float64) {
+	return sincosde(x, 0)
+}
+
+// sincosde returns sine and cosine of angle x + t in degrees where t is
+// small correction to x.
+func sincosde(x, t float64) (sinx, cosx float64) {
+	r := math.Remainder(x, 90)
+	q := int(math.Round((x - r) / 90))
+	r = angRound(r+t) * rad
+	s, c := math.Sincos(r)
+	switch q & 3 {
+	case 0:
+		sinx, cosx = s, c
+	case 1:
+		sinx, cosx = c, -s
+	case 2:
+		sinx, cosx = -s, -c
+	default:
+		sinx, cosx = -c, s
+	}
+	cosx += 0
+	if sinx == 0 {
+		sinx = math.Copysign(sinx, x)
+	}
+	return sinx, cosx
+}
+
+// geodTransit returns 1 or -1 if the edge from lon1 to lon2 crosses the
+// prime meridian in east or west direction, otherwise 0.
+func geodTransit(lon1, lon2 float64) int {
+	lon12, _ := angDiff(lon1, lon2)
+	lon1 = angNormalize(lon1)
+	lon2 = angNormalize(lon2)
+	switch {
+	case lon12 > 0 && ((lon1 < 0 && lon2 >= 0) || (lon1 > 0 && lon2 == 0)):
+		return 1
+	case lon12 < 0 && lon1 >= 0 && lon2 < 0:
+		return -1
+	}
+	return 0
+}
+
+// geodesicArea returns signed area in square meters of the geodesic
+// polygon ring, positive for counterclockwise traversal. The area is in
+// range from minus to plus half of the ellipsoid area.
+func geodesicArea(ring []Coord) float64 {
+	var area float64
+	var crossings int
+	for i := range ring {
+		p1, p2 := ring[i], ring[(i+1)%len(ring)]
+		_, s := geodesicInverse(p1.Lat, p1.Lon, p2.Lat, p2.Lon)
+		area += s
+		crossings += geodTransit(p1.Lon, p2.Lon)
+	}
+
+	area0 := 4 * math.Pi * geodC2
+	area = math.Remainder(area, area0)
+	if crossings&1 != 0 {
+		if area < 0 {
+			area += area0 / 2
+		} else {
+			area -= area0 / 2
+		}
+	}
+	// Sum of the areas is positive for clockwise traversal.
+	area = -area
+	if area > area0/2 {
+		area -= area0
+	} else if area <= -area0/2 {
+		area += area0
+	}
+	return 0 + area
+}
@@ -0,0 +1,1221 @@
+package kml
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupportedGeometry is returned when geometry cannot be converted to
+// or from other format.
+var ErrUnsupportedGeometry = errors.New("unsupported geometry")
+
+// ErrInvalidGeoJSON is returned when GeoJSON cannot be converted to KML.
+var ErrInvalidGeoJSON = errors.New("invalid GeoJSON")
+
+// GeoJSON object types.
+const (
+	GeoJSONFeatureCollectionType = "FeatureCollection"
+	GeoJSONFeatureType           = "Feature"
+	GeoJSONPoint                 = "Point"
+	GeoJSONMultiPoint            = "MultiPoint"
+	GeoJSONLineString            = "LineString"
+	GeoJSONMultiLineString       = "MultiLineString"
+	GeoJSONPolygon               = "Polygon"
+	GeoJSONMultiPolygon          = "MultiPolygon"
+	GeoJSONGeometryCollection    = "GeometryCollection"
+)
+
+// GeoJSONFeatureCollection represents GeoJSON feature collection.
+type GeoJSONFeatureCollection struct {
+	Type     string            `json:"type"`
+	Features []*GeoJSONFeature `json:"features"`
+}
+
+// GeoJSONFeature represents GeoJSON feature.
+type GeoJSONFeature struct {
+	Type       string                 `json:"type"`
+	ID         interface{}            `json:"id,omitempty"`
+	Geometry   *GeoJSONGeometry       `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// GeoJSONGeometry represents GeoJSON geometry. Depending on the geometry
+// type Coordinates is one of:
+//
+//	[]float64       - Point
+//	[][]float64     - MultiPoint, LineString
+//	[][][]float64   - MultiLineString, Polygon
+//	[][][][]float64 - MultiPolygon
+//
+// Geometries is used only by GeometryCollection.
+type GeoJSONGeometry struct {
+	Type        string
+	Coordinates interface{}
+	Geometries  []*GeoJSONGeometry
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (g *GeoJSONGeometry) MarshalJSON() ([]byte, error) {
+	if g.Type == GeoJSONGeometryCollection {
+		geoms := g.Geometries
+		if geoms == nil {
+			geoms = []*GeoJSONGeometry{}
+		}
+		return json.Marshal(struct {
+			Type       string             `json:"type"`
+			Geometries []*GeoJSONGeometry `json:"geometries"`
+		}{g.Type, geoms})
+	}
+	return json.Marshal(struct {
+		Type        string      `json:"type"`
+		Coordinates interface{} `json:"coordinates"`
+	}{g.Type, g.Coordinates})
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface. Coordinates are
+// decoded to the type matching the geometry type.
+func (g *GeoJSONGeometry) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Type        string             `json:"type"`
+		Coordinates json.RawMessage    `json:"coordinates"`
+		Geometries  []*GeoJSONGeometry `json:"geometries"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	var crs interface{}
+	switch raw.Type {
+	case GeoJSONPoint:
+		crs = &[]float64{}
+	case GeoJSONMultiPoint, GeoJSONLineString:
+		crs = &[][]float64{}
+	case GeoJSONMultiLineString, GeoJSONPolygon:
+		crs = &[][][]float64{}
+	case GeoJSONMultiPolygon:
+		crs = &[][][][]float64{}
+	case GeoJSONGeometryCollection:
+		*g = GeoJSONGeometry{Type: raw.Type, Geometries: raw.Geometries}
+		return nil
+	default:
+		return fmt.Errorf("%w %q", ErrUnsupportedGeometry, raw.Type)
+	}
+	if len(raw.Coordinates) > 0 {
+		if err := json.Unmarshal(raw.Coordinates, crs); err != nil {
+			return fmt.Errorf("%w: %s coordinates: %s", ErrInvalidGeoJSON, raw.Type, err)
+		}
+	}
+
+	*g = GeoJSONGeometry{Type: raw.Type}
+	switch v := crs.(type) {
+	case *[]float64:
+		g.Coordinates = *v
+	case *[][]float64:
+		g.Coordinates = *v
+	case *[][][]float64:
+		g.Coordinates = *v
+	case *[][][][]float64:
+		g.Coordinates = *v
+	}
+	return nil
+}
+
+// GeoJSONOptions configures GeoJSON export.
+type GeoJSONOptions struct {
+	// Add "folders" property with names of Documents and Folders
+	// enclosing the Placemark starting from the outermost one.
+	FolderPath bool
+
+	// Add simplestyle-spec properties (marker-color, marker-size, stroke,
+	// stroke-opacity, stroke-width, fill, fill-opacity) resolved from
+	// Placemark's shared and inline styles.
+	Styles bool
+}
+
+// ToGeoJSON converts Placemarks in the element tree rooted at el to GeoJSON
+// feature collection. Placemarks are looked up in el and in Documents and
+// Folders it contains. Point, LineString, LinearRing, Polygon,
+// MultiGeometry, gx:Track and gx:MultiTrack are converted to GeoJSON
+// geometries, other geometries in MultiGeometry are skipped. Placemarks
+// with other geometries, MultiGeometry without supported geometries or
+// without geometry have null geometry. The name, description, ExtendedData Data values and
+// SchemaData SimpleData values are converted to properties. SimpleData
+// values are converted to numbers or booleans when the Schema declaring
+// them is found in the document. The Placemark id attribute becomes the
+// feature ID.
+func ToGeoJSON(el *Element, opts GeoJSONOptions) (*GeoJSONFeatureCollection, error) {
+	x := &geoJSONExporter{
+		opts: opts,
+		root: el,
+		fc: &GeoJSONFeatureCollection{
+			Type:     GeoJSONFeatureCollectionType,
+			Features: []*GeoJSONFeature{},
+		},
+	}
+	for x.root.parent != nil {
+		x.root = x.root.parent
+	}
+	if err := eachPlacemark(el, x.feature); err != nil {
+		return nil, err
+	}
+	return x.fc, nil
+}
+
+// eachPlacemark calls fn for Placemark el or for Placemarks in container el
+// and containers it contains. The path holds names of Documents and
+// Folders enclosing the Placemark starting from the outermost one.
+func eachPlacemark(el *Element, fn func(pm *Element, path []string) error) error {
+	var path []string
+	ancs := el.Ancestors()
+	for i := len(ancs) - 1; i >= 0; i-- {
+		if isContainer(ancs[i]) {
+			path = append(path, containerName(ancs[i]))
+		}
+	}
+
+	if el.LocalName() == ElemPlacemark {
+		return fn(el, path)
+	}
+	if isContainer(el) {
+		path = append(path, containerName(el))
+	}
+	return eachContainerPlacemark(el, path, fn)
+}
+
+// eachContainerPlacemark calls fn for Placemarks in container element el
+// and containers it contains.
+func eachContainerPlacemark(el *Element, path []string, fn func(pm *Element, path []string) error) error {
+	for _, ch := range el.children {
+		switch {
+		case ch.LocalName() == ElemPlacemark:
+			if err := fn(ch, path); err != nil {
+				return err
+			}
+		case isContainer(ch):
+			sub := append(path[:len(path):len(path)], containerName(ch))
+			if err := eachContainerPlacemark(ch, sub, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isContainer returns true for Document and Folder elements.
+func isContainer(el *Element) bool {
+	n := el.LocalName()
+	return n == ElemDocument || n == ElemFolder
+}
+
+// containerName returns content of container's name element.
+func containerName(el *Element) string {
+	if nam := el.ChildByName(ElemName); nam != nil {
+		return nam.ContentString()
+	}
+	return ""
+}
+
+// geoJSONExporter converts KML to GeoJSON.
+type geoJSONExporter struct {
+	opts GeoJSONOptions
+	root *Element
+	ids  map[string]*Element // Elements by ID, nil until needed.
+	fc   *GeoJSONFeatureCollection
+}
+
+// feature converts Placemark to GeoJSON feature.
+func (x *geoJSONExporter) feature(pm *Element, path []string) error {
+	ft := &GeoJSONFeature{
+		Type:       GeoJSONFeatureType,
+		Properties: make(map[string]interface{}),
+	}
+	if id := pm.ID(); id != "" {
+		ft.ID = id
+	}
+
+	for _, ch := range pm.children {
+		switch ch.LocalName() {
+		case ElemName, ElemDescription:
+			ft.Properties[ch.LocalName()] = ch.ContentString()
+
+		case ElemExtendedData:
+			if err := x.extendedData(ch, ft.Properties); err != nil {
+				return err
+			}
+
+		default:
+			if ft.Geometry != nil || !isGeometry(ch) {
+				continue
+			}
+			geom, err := geoJSONGeometry(ch)
+			if err != nil && !errors.Is(err, ErrUnsupportedGeometry) {
+				return err
+			}
+			ft.Geometry = geom
+		}
+	}
+
+	if x.opts.FolderPath {
+		folders := make([]string, len(path))
+		copy(folders, path)
+		ft.Properties["folders"] = folders
+	}
+	if x.opts.Styles {
+		if err := x.styles(pm, ft.Properties); err != nil {
+			return err
+		}
+	}
+
+	x.fc.Features = append(x.fc.Features, ft)
+	return nil
+}
+
+// extendedData adds Data and SimpleData values to props. Existing
+// properties are not overwritten.
+func (x *geoJSONExporter) extendedData(ed *Element, props map[string]interface{}) error {
+	set := func(name string, value interface{}) {
+		if _, ok := props[name]; !ok && name != "" {
+			props[name] = value
+		}
+	}
+
+	for _, ch := range ed.children {
+		switch ch.LocalName() {
+		case ElemData:
+			var val string
+			if v := ch.ChildByName(ElemValue); v != nil {
+				val = v.ContentString()
+			}
+			set(ch.Attribute("name").Value, val)
+
+		case ElemSchemaData:
+			types := x.schemaTypes(ch.Attribute("schemaUrl").Value)
+			for _, sd := range ch.children {
+				if sd.LocalName() != ElemSimpleData {
+					continue
+				}
+				name := sd.Attribute("name").Value
+				val, err := simpleDataValue(sd, types[name])
+				if err != nil {
+					return err
+				}
+				set(name, val)
+			}
+		}
+	}
+	return nil
+}
+
+// schemaTypes returns SimpleField types by field name of Schema referenced
+// by schemaURL. Returns nil if Schema is not found in the document.
+func (x *geoJSONExporter) schemaTypes(schemaURL string) map[string]string {
+	if !strings.HasPrefix(schemaURL, "#") {
+		return nil
+	}
+	sch := x.byID(schemaURL[1:])
+	if sch == nil || sch.LocalName() != ElemSchema {
+		return nil
+	}
+	types := make(map[string]string)
+	for _, sf := range sch.children {
+		if sf.LocalName() == ElemSimpleField {
+			types[sf.Attribute("name").Value] = sf.Attribute("type").Value
+		}
+	}
+	return types
+}
+
+// simpleDataValue returns SimpleData value converted to Go type based on
+// SimpleField type.
+func simpleDataValue(sd *Element, typ string) (interface{}, error) {
+	switch typ {
+	case "int", "uint", "short", "ushort":
+		return sd.ContentInt()
+	case "float", "double":
+		return sd.ContentFloat()
+	case "bool":
+		return sd.ContentBool()
+	}
+	return sd.ContentString(), nil
+}
+
+// byID returns element with ID from the document or nil if it does not
+// exist.
+func (x *geoJSONExporter) byID(id string) *Element {
+	if x.ids == nil {
+		x.ids = make(map[string]*Element)
+		_ = x.root.Walk(func(el *Element, _ int) error {
+			if id := el.ID(); id != "" {
+				if _, ok := x.ids[id]; !ok {
+					x.ids[id] = el
+				}
+			}
+			return nil
+		})
+	}
+	return x.ids[id]
+}
+
+// styles adds simplestyle-spec properties for Placemark's styles to props.
+// Inline style takes precedence over shared style.
+func (x *geoJSONExporter) styles(pm *Element, props map[string]interface{}) error {
+	var styles []*Element
+	if su := pm.ChildByName(ElemStyleURL); su != nil {
+		styles = x.resolveStyle(su.ContentString(), styles, 0)
+	}
+	if sty := pm.ChildByName(ElemStyle); sty != nil {
+		styles = append(styles, sty)
+	}
+
+	for _, sty := range styles {
+		if err := styleProperties(sty, props); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveStyle appends Style elements referenced by style URL to styles.
+// StyleMaps are resolved to their normal style. Only references to
+// elements in the same document are resolved.
+func (x *geoJSONExporter) resolveStyle(url string, styles []*Element, depth int) []*Element {
+	if !strings.HasPrefix(url, "#") || depth > 8 {
+		return styles
+	}
+	el := x.byID(url[1:])
+	if el == nil {
+		return styles
+	}
+
+	switch el.LocalName() {
+	case ElemStyle:
+		return append(styles, el)
+
+	case ElemStyleMap:
+		for _, pair := range el.children {
+			if pair.LocalName() != ElemPair {
+				continue
+			}
+			if key := pair.ChildByName(ElemKey); key == nil || key.ContentString() != "normal" {
+				continue
+			}
+			if su := pair.ChildByName(ElemStyleURL); su != nil {
+				styles = x.resolveStyle(su.ContentString(), styles, depth+1)
+			}
+			if sty := pair.ChildByName(ElemStyle); sty != nil {
+				styles = append(styles, sty)
+			}
+		}
+	}
+	return styles
+}
+
+// styleProperties sets simplestyle-spec properties from Style element.
+func styleProperties(sty *Element, props map[string]interface{}) error {
+	if is := sty.ChildByName(ElemIconStyle); is != nil {
+		if is.HasChild(ElemColor) {
+			c, err := is.ChildColor(ElemColor)
+			if err != nil {
+				return err
+			}
+			props["marker-color"] = c.Web()
+		}
+		if is.HasChild(ElemScale) {
+			scale, err := is.ChildFloat(ElemScale)
+			if err != nil {
+				return err
+			}
+			switch {
+			case scale < 0.75:
+				props["marker-size"] = "small"
+			case scale > 1.25:
+				props["marker-size"] = "large"
+			default:
+				props["marker-size"] = "medium"
+			}
+		}
+	}
+
+	if ls := sty.ChildByName(ElemLineStyle); ls != nil {
+		if ls.HasChild(ElemColor) {
+			c, err := ls.ChildColor(ElemColor)
+			if err != nil {
+				return err
+			}
+			props["stroke"] = c.Web()
+			props["stroke-opacity"] = roundOpacity(c.Opacity())
+		}
+		if ls.HasChild(ElemWidth) {
+			w, err := ls.ChildFloat(ElemWidth)
+			if err != nil {
+				return err
+			}
+			props["stroke-width"] = w
+		}
+	}
+
+	if ps := sty.ChildByName(ElemPolyStyle); ps != nil {
+		if ps.HasChild(ElemColor) {
+			c, err := ps.ChildColor(ElemColor)
+			if err != nil {
+				return err
+			}
+			props["fill"] = c.Web()
+			props["fill-opacity"] = roundOpacity(c.Opacity())
+		}
+		if ps.HasChild(ElemFill) {
+			fill, err := ps.ChildBool(ElemFill)
+			if err != nil {
+				return err
+			}
+			if !fill {
+				props["fill-opacity"] = 0.0
+			}
+		}
+	}
+	return nil
+}
+
+// roundOpacity rounds opacity to three decimal places.
+func roundOpacity(v float64) float64 {
+	r, _ := strconv.ParseFloat(strconv.FormatFloat(v, 'f', 3, 64), 64)
+	return r
+}
+
+// isGeometry returns true for KML geometry elements.
+func isGeometry(el *Element) bool {
+	switch el.qualifiedName() {
+	case ElemPoint, ElemLineString, ElemLinearRing, ElemPolygon,
+		ElemMultiGeometry, ElemModel, ElemGxTrack, ElemGxMultiTrack:
+		return true
+	}
+	return false
+}
+
+// geoJSONGeometry converts KML geometry element to GeoJSON geometry.
+// Returns error wrapping ErrUnsupportedGeometry for geometries which
+// cannot be converted.
+func geoJSONGeometry(el *Element) (*GeoJSONGeometry, error) {
+	switch el.qualifiedName() {
+	case ElemPoint:
+		crs, err := geometryCoords(el)
+		if err != nil {
+			return nil, err
+		}
+		if len(crs) == 0 {
+			return nil, newGeometryError(el, geometryErr("missing coordinates"))
+		}
+		return &GeoJSONGeometry{Type: GeoJSONPoint, Coordinates: geoJSONPosition(crs[0])}, nil
+
+	case ElemLineString:
+		crs, err := geometryCoords(el)
+		if err != nil {
+			return nil, err
+		}
+		return &GeoJSONGeometry{Type: GeoJSONLineString, Coordinates: geoJSONPositions(crs)}, nil
+
+	case ElemLinearRing:
+		crs, err := geometryCoords(el)
+		if err != nil {
+			return nil, err
+		}
+		return &GeoJSONGeometry{Type: GeoJSONPolygon, Coordinates: [][][]float64{geoJSONPositions(crs)}}, nil
+
+	case ElemPolygon:
+		rings, err := polygonRings(el)
+		if err != nil {
+			return nil, err
+		}
+		poly := make([][][]float64, len(rings))
+		for i, ring := range rings {
+			poly[i] = geoJSONPositions(ring)
+		}
+		return &GeoJSONGeometry{Type: GeoJSONPolygon, Coordinates: poly}, nil
+
+	case ElemGxTrack:
+		crs, err := trackCoords(el)
+		if err != nil {
+			return nil, err
+		}
+		return &GeoJSONGeometry{Type: GeoJSONLineString, Coordinates: geoJSONPositions(crs)}, nil
+
+	case ElemGxMultiTrack:
+		var lines [][][]float64
+		for _, ch := range el.children {
+			if !ch.MatchName(ElemGxTrack) {
+				continue
+			}
+			crs, err := trackCoords(ch)
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, geoJSONPositions(crs))
+		}
+		return &GeoJSONGeometry{Type: GeoJSONMultiLineString, Coordinates: lines}, nil
+
+	case ElemMultiGeometry:
+		return multiGeometry(el)
+	}
+	return nil, newGeometryError(el, fmt.Errorf("%w %s", ErrUnsupportedGeometry, el.LocalName()))
+}
+
+// multiGeometry converts MultiGeometry to GeoJSON geometry. MultiGeometry
+// with only Points, LineStrings or Polygons is converted to MultiPoint,
+// MultiLineString or MultiPolygon respectively, other to
+// GeometryCollection. Unsupported geometries are skipped, returns error
+// wrapping ErrUnsupportedGeometry when none of the geometries is
+// supported.
+func multiGeometry(el *Element) (*GeoJSONGeometry, error) {
+	var geoms []*GeoJSONGeometry
+	var unsupported error
+	for _, ch := range el.children {
+		if !isGeometry(ch) {
+			continue
+		}
+		geom, err := geoJSONGeometry(ch)
+		if errors.Is(err, ErrUnsupportedGeometry) {
+			unsupported = err
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		geoms = append(geoms, geom)
+	}
+	if len(geoms) == 0 && unsupported != nil {
+		return nil, unsupported
+	}
+
+	typ := ""
+	for i, g := range geoms {
+		if i > 0 && g.Type != typ {
+			typ = ""
+			break
+		}
+		typ = g.Type
+	}
+
+	switch typ {
+	case GeoJSONPoint:
+		crs := make([][]float64, len(geoms))
+		for i, g := range geoms {
+			crs[i] = g.Coordinates.([]float64)
+		}
+		return &GeoJSONGeometry{Type: GeoJSONMultiPoint, Coordinates: crs}, nil
+
+	case GeoJSONLineString:
+		crs := make([][][]float64, len(geoms))
+		for i, g := range geoms {
+			crs[i] = g.Coordinates.([][]float64)
+		}
+		return &GeoJSONGeometry{Type: GeoJSONMultiLineString, Coordinates: crs}, nil
+
+	case GeoJSONPolygon:
+		crs := make([][][][]float64, len(geoms))
+		for i, g := range geoms {
+			crs[i] = g.Coordinates.([][][]float64)
+		}
+		return &GeoJSONGeometry{Type: GeoJSONMultiPolygon, Coordinates: crs}, nil
+	}
+	return &GeoJSONGeometry{Type: GeoJSONGeometryCollection, Geometries: geoms}, nil
+}
+
+// geometryCoords returns coordinates of geometry element's coordinates
+// child.
+func geometryCoords(el *Element) ([]Coord, error) {
+	cor := el.ChildByName(ElemCoordinates)
+	if cor == nil {
+		return nil, newGeometryError(el, geometryErr("missing coordinates"))
+	}
+	return cor.Coords()
+}
+
+// polygonRings returns coordinates of Polygon's outer ring followed by
+// its inner rings.
+func polygonRings(poly *Element) ([][]Coord, error) {
+	var outer []Coord
+	var inner [][]Coord
+	for _, bnd := range poly.children {
+		name := bnd.LocalName()
+		if name != ElemOuterBoundaryIs && name != ElemInnerBoundaryIs {
+			continue
+		}
+		ring := bnd.ChildByName(ElemLinearRing)
+		if ring == nil {
+			return nil, newGeometryError(bnd, geometryErr("missing LinearRing"))
+		}
+		crs, err := geometryCoords(ring)
+		if err != nil {
+			return nil, err
+		}
+		if name == ElemOuterBoundaryIs {
+			outer = crs
+		} else {
+			inner = append(inner, crs)
+		}
+	}
+	if outer == nil {
+		return nil, newGeometryError(poly, geometryErr("missing outerBoundaryIs"))
+	}
+	return append([][]Coord{outer}, inner...), nil
+}
+
+// trackCoords returns coordinates of gx:Track gx:coord elements.
+func trackCoords(trk *Element) ([]Coord, error) {
+	var crs []Coord
+	for _, ch := range trk.children {
+		if !ch.MatchName(ElemGxCoord) {
+			continue
+		}
+		c, err := ch.ContentGxCoord()
+		if err != nil {
+			return nil, err
+		}
+		crs = append(crs, c)
+	}
+	return crs, nil
+}
+
+// ContentGxCoord parses element's content as gx:coord value which is
+// longitude, latitude and altitude separated by spaces.
+func (e *Element) ContentGxCoord() (Coord, error) {
+	fs := strings.Fields(string(e.content))
+	if len(fs) < 2 || len(fs) > 3 {
+		err := coordsError(0, e.trimmedContent(), "expected 2 or 3 values")
+		return Coord{}, fmt.Errorf("%s: %s: %w", e.pos, e.Path(), err)
+	}
+	var vs [3]float64
+	for i, f := range fs {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			err = coordsError(0, e.trimmedContent(), fmt.Sprintf("invalid value %q", f))
+			return Coord{}, fmt.Errorf("%s: %s: %w", e.pos, e.Path(), err)
+		}
+		vs[i] = v
+	}
+	return Coord{Lon: vs[0], Lat: vs[1], Alt: vs[2]}, nil
+}
+
+// geoJSONPosition returns GeoJSON position for coordinate. Altitude is
+// omitted when it's zero.
+func geoJSONPosition(c Coord) []float64 {
+	if c.Alt != 0 {
+		return []float64{c.Lon, c.Lat, c.Alt}
+	}
+	return []float64{c.Lon, c.Lat}
+}
+
+// geoJSONPositions returns GeoJSON positions for coordinates.
+func geoJSONPositions(crs []Coord) [][]float64 {
+	pos := make([][]float64, len(crs))
+	for i, c := range crs {
+		pos[i] = geoJSONPosition(c)
+	}
+	return pos
+}
+
+// ParseGeoJSON decodes GeoJSON feature collection from r and converts it
+// with FromGeoJSON.
+func ParseGeoJSON(r io.Reader) (*Element, error) {
+	fc := &GeoJSONFeatureCollection{}
+	if err := json.NewDecoder(r).Decode(fc); err != nil {
+		if errors.Is(err, ErrUnsupportedGeometry) || errors.Is(err, ErrInvalidGeoJSON) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %s", ErrInvalidGeoJSON, err)
+	}
+	return FromGeoJSON(fc)
+}
+
+// FromGeoJSON builds KML(Document(...)) element tree from GeoJSON feature
+// collection. Each feature becomes a Placemark with the feature's geometry.
+// Multi geometries and geometry collections become MultiGeometry.
+//
+// The string name and description properties become Placemark's name and
+// description and simplestyle-spec properties become shared Style elements
+// referenced by styleUrl. Features with the same style share the Style
+// element. Other properties, including name and description which are not
+// strings, are stored in ExtendedData. When no property is an object or
+// array the properties are stored as SchemaData of generated Schema with
+// field types inferred from all values of the property (string when the
+// values have different types), otherwise they are stored as Data
+// elements with objects and arrays encoded as JSON. Properties with null
+// values are skipped.
+//
+// String feature IDs which are valid XML IDs become Placemark IDs, only
+// the first of features with the same ID gets it. Generated Schema and
+// Style IDs don't collide with Placemark IDs.
+func FromGeoJSON(fc *GeoJSONFeatureCollection) (*Element, error) {
+	if fc.Type != GeoJSONFeatureCollectionType {
+		return nil, fmt.Errorf("%w: expected %s got %q", ErrInvalidGeoJSON, GeoJSONFeatureCollectionType, fc.Type)
+	}
+
+	ids := make(map[string]bool)              // IDs used in the document.
+	pmIDs := make([]string, len(fc.Features)) // Placemark IDs.
+	for i, ft := range fc.Features {
+		if ft == nil {
+			continue
+		}
+		if id, ok := ft.ID.(string); ok && reXMLID.MatchString(id) && !ids[id] {
+			ids[id] = true
+			pmIDs[i] = id
+		}
+	}
+
+	doc := Document()
+	var schID string
+	fields := schemaFields(fc.Features)
+	if len(fields) > 0 {
+		schID = newID(ids, "sch_")
+		sch := NewElement(ElemSchema, AttrID(schID), Attr("name", "properties"))
+		for _, f := range fields {
+			_ = sch.AddChild(SimpleField(f.typ, f.name))
+		}
+		_ = doc.InsertChild(sch)
+	}
+
+	styles := make(map[string]string) // Style IDs by style key.
+	var pms []*Element
+	for i, ft := range fc.Features {
+		pm, err := geoJSONFeature(ft, pmIDs[i], fields, schID, doc, styles, ids)
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %w", i, err)
+		}
+		pms = append(pms, pm)
+	}
+	for _, pm := range pms {
+		_ = doc.AddChild(pm)
+	}
+	return KML(doc), nil
+}
+
+// simpleStyleProps is the set of simplestyle-spec properties.
+var simpleStyleProps = map[string]bool{
+	"marker-color":   true,
+	"marker-size":    true,
+	"marker-symbol":  true,
+	"stroke":         true,
+	"stroke-opacity": true,
+	"stroke-width":   true,
+	"fill":           true,
+	"fill-opacity":   true,
+}
+
+// isDataProperty returns true for properties stored in ExtendedData.
+func isDataProperty(name string, value interface{}) bool {
+	if _, ok := value.(string); ok && (name == ElemName || name == ElemDescription) {
+		return false
+	}
+	return value != nil && !simpleStyleProps[name]
+}
+
+// newID returns the first ID made of prefix and a number which is not in
+// ids and adds it to ids.
+func newID(ids map[string]bool, prefix string) string {
+	for i := 0; ; i++ {
+		id := prefix + strconv.Itoa(i)
+		if !ids[id] {
+			ids[id] = true
+			return id
+		}
+	}
+}
+
+// schemaField represents SimpleField of generated Schema.
+type schemaField struct {
+	name string
+	typ  string
+}
+
+// schemaFields returns Schema fields for feature properties stored in
+// ExtendedData. Field types are inferred from all values of the property,
+// fields with values of different types are string. Returns nil when any
+// of the properties is an object or array.
+func schemaFields(fts []*GeoJSONFeature) []schemaField {
+	types := make(map[string]string)
+	for _, ft := range fts {
+		for name, val := range ft.Properties {
+			if !isDataProperty(name, val) {
+				continue
+			}
+			var typ string
+			switch v := val.(type) {
+			case string:
+				typ = "string"
+			case bool:
+				typ = "bool"
+			case float64:
+				typ = "int"
+				if v != math.Trunc(v) || math.Abs(v) > math.MaxInt32 {
+					typ = "double"
+				}
+			default:
+				return nil
+			}
+			prev, ok := types[name]
+			switch {
+			case !ok || prev == typ:
+			case prev == "int" && typ == "double", prev == "double" && typ == "int":
+				typ = "double"
+			default:
+				typ = "string"
+			}
+			types[name] = typ
+		}
+	}
+
+	fields := make([]schemaField, 0, len(types))
+	for name, typ := range types {
+		fields = append(fields, schemaField{name: name, typ: typ})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].name < fields[j].name })
+	return fields
+}
+
+// geoJSONFeature converts GeoJSON feature to Placemark with ID id (may be
+// empty). Shared styles are added to doc with IDs not present in ids.
+func geoJSONFeature(ft *GeoJSONFeature, id string, fields []schemaField, schID string, doc *Element, styles map[string]string, ids map[string]bool) (*Element, error) {
+	if ft == nil || ft.Type != GeoJSONFeatureType {
+		return nil, fmt.Errorf("%w: expected %s", ErrInvalidGeoJSON, GeoJSONFeatureType)
+	}
+
+	pm := Placemark()
+	if id != "" {
+		pm.SetAttribute(AttrID(id))
+	}
+	if v, ok := ft.Properties[ElemName].(string); ok {
+		_ = pm.AddChild(Name(v))
+	}
+	if v, ok := ft.Properties[ElemDescription].(string); ok {
+		_ = pm.AddChild(Description(v))
+	}
+
+	sty, err := simpleStyle(ft.Properties)
+	if err != nil {
+		return nil, err
+	}
+	if sty != nil {
+		data, err := xml.Marshal(sty)
+		if err != nil {
+			return nil, err
+		}
+		key := string(data)
+		styID, ok := styles[key]
+		if !ok {
+			styID = newID(ids, "sty_")
+			styles[key] = styID
+			sty.SetAttribute(AttrID(styID))
+			_ = doc.InsertChild(sty)
+		}
+		_ = pm.AddChild(StyleURL("#" + styID))
+	}
+
+	if ed := extendedData(ft.Properties, fields, schID); ed != nil {
+		_ = pm.AddChild(ed)
+	}
+
+	if ft.Geometry != nil {
+		geom, err := kmlGeometry(ft.Geometry)
+		if err != nil {
+			return nil, err
+		}
+		_ = pm.AddChild(geom)
+	}
+	return pm, nil
+}
+
+// reXMLID matches values which can be used as XML IDs.
+var reXMLID = regexp.MustCompile(`^[\pL_][\pL\pN._\-]*$`)
+
+// extendedData returns ExtendedData for feature properties or nil if
+// there are no properties to store. When fields is not empty properties
+// are stored as SchemaData of Schema with ID schID.
+func extendedData(props map[string]interface{}, fields []schemaField, schID string) *Element {
+	if len(fields) > 0 {
+		sd := SchemaData("#" + schID)
+		for _, f := range fields {
+			if val, ok := props[f.name]; ok && isDataProperty(f.name, val) {
+				_ = sd.AddChild(SimpleData(f.name, propertyString(val)))
+			}
+		}
+		if sd.ChildCnt() == 0 {
+			return nil
+		}
+		return ExtendedData(sd)
+	}
+
+	names := make([]string, 0, len(props))
+	for name, val := range props {
+		if isDataProperty(name, val) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+
+	ed := ExtendedData()
+	for _, name := range names {
+		_ = ed.AddChild(Data(name, Value(propertyString(props[name]))))
+	}
+	return ed
+}
+
+// propertyString returns property value as string. Objects and arrays
+// are encoded as JSON.
+func propertyString(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	data, _ := json.Marshal(val)
+	return string(data)
+}
+
+// simpleStyle returns Style element for simplestyle-spec properties or nil
+// if feature has no such properties. Missing values have simplestyle-spec
+// defaults.
+func simpleStyle(props map[string]interface{}) (*Element, error) {
+	str := func(name, def string) (string, bool, error) {
+		v, ok := props[name]
+		if !ok || v == nil {
+			return def, false, nil
+		}
+		s, ok := v.(string)
+		if !ok {
+			return "", false, fmt.Errorf("%w: property %s must be a string", ErrInvalidGeoJSON, name)
+		}
+		return s, true, nil
+	}
+	num := func(name string, def float64) (float64, bool, error) {
+		v, ok := props[name]
+		if !ok || v == nil {
+			return def, false, nil
+		}
+		switch n := v.(type) {
+		case float64:
+			return n, true, nil
+		case string:
+			if f, err := strconv.ParseFloat(n, 64); err == nil {
+				return f, true, nil
+			}
+		}
+		return 0, false, fmt.Errorf("%w: property %s must be a number", ErrInvalidGeoJSON, name)
+	}
+	color := func(name, def string, opacity float64) (KMLColor, error) {
+		s, _, err := str(name, def)
+		if err != nil {
+			return KMLColor{}, err
+		}
+		c, err := ParseWebColor(s)
+		if err != nil {
+			return KMLColor{}, fmt.Errorf("property %s: %w", name, err)
+		}
+		return c.WithOpacity(opacity), nil
+	}
+
+	sty := NewElement(ElemStyle)
+
+	_, hasColor, err := str("marker-color", "")
+	if err != nil {
+		return nil, err
+	}
+	size, hasSize, err := str("marker-size", "medium")
+	if err != nil {
+		return nil, err
+	}
+	if hasColor || hasSize {
+		is := IconStyle()
+		if hasColor {
+			c, err := color("marker-color", "", 1)
+			if err != nil {
+				return nil, err
+			}
+			_ = is.AddChild(ColorRGBA(c))
+		}
+		if hasSize {
+			scale := map[string]float64{"small": 0.5, "medium": 1, "large": 1.5}[size]
+			if scale == 0 {
+				return nil, fmt.Errorf("%w: invalid marker-size %q", ErrInvalidGeoJSON, size)
+			}
+			_ = is.AddChild(Scale(scale))
+		}
+		_ = sty.AddChild(is)
+	}
+
+	_, hasStroke, err := str("stroke", "")
+	if err != nil {
+		return nil, err
+	}
+	strokeOpacity, hasStrokeOpacity, err := num("stroke-opacity", 1)
+	if err != nil {
+		return nil, err
+	}
+	strokeWidth, hasStrokeWidth, err := num("stroke-width", 2)
+	if err != nil {
+		return nil, err
+	}
+	if hasStroke || hasStrokeOpacity || hasStrokeWidth {
+		c, err := color("stroke", "#555555", strokeOpacity)
+		if err != nil {
+			return nil, err
+		}
+		_ = sty.AddChild(LineStyle(ColorRGBA(c), Width(strokeWidth)))
+	}
+
+	_, hasFill, err := str("fill", "")
+	if err != nil {
+		return nil, err
+	}
+	fillOpacity, hasFillOpacity, err := num("fill-opacity", 0.6)
+	if err != nil {
+		return nil, err
+	}
+	if hasFill || hasFillOpacity {
+		c, err := color("fill", "#555555", fillOpacity)
+		if err != nil {
+			return nil, err
+		}
+		_ = sty.AddChild(PolyStyle(ColorRGBA(c)))
+	}
+
+	if sty.ChildCnt() == 0 {
+		return nil, nil
+	}
+	return sty, nil
+}
+
+// kmlGeometry converts GeoJSON geometry to KML geometry element.
+func kmlGeometry(g *GeoJSONGeometry) (*Element, error) {
+	switch crs := g.Coordinates.(type) {
+	case []float64:
+		if g.Type == GeoJSONPoint {
+			c, err := geoJSONCoord(crs)
+			if err != nil {
+				return nil, err
+			}
+			return Point(CoordinatesFrom([]Coord{c})), nil
+		}
+
+	case [][]float64:
+		switch g.Type {
+		case GeoJSONLineString:
+			return geoJSONLine(LineString(), crs)
+		case GeoJSONMultiPoint:
+			mg := MultiGeometry()
+			for _, pos := range crs {
+				c, err := geoJSONCoord(pos)
+				if err != nil {
+					return nil, err
+				}
+				_ = mg.AddChild(Point(CoordinatesFrom([]Coord{c})))
+			}
+			return mg, nil
+		}
+
+	case [][][]float64:
+		switch g.Type {
+		case GeoJSONPolygon:
+			return geoJSONPolygon(crs)
+		case GeoJSONMultiLineString:
+			mg := MultiGeometry()
+			for _, line := range crs {
+				ls, err := geoJSONLine(LineString(), line)
+				if err != nil {
+					return nil, err
+				}
+				_ = mg.AddChild(ls)
+			}
+			return mg, nil
+		}
+
+	case [][][][]float64:
+		if g.Type == GeoJSONMultiPolygon {
+			mg := MultiGeometry()
+			for _, poly := range crs {
+				pl, err := geoJSONPolygon(poly)
+				if err != nil {
+					return nil, err
+				}
+				_ = mg.AddChild(pl)
+			}
+			return mg, nil
+		}
+
+	case nil:
+		if g.Type == GeoJSONGeometryCollection {
+			mg := MultiGeometry()
+			for _, sub := range g.Geometries {
+				if sub == nil {
+					continue
+				}
+				el, err := kmlGeometry(sub)
+				if err != nil {
+					return nil, err
+				}
+				_ = mg.AddChild(el)
+			}
+			return mg, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: invalid %s coordinates", ErrInvalidGeoJSON, g.Type)
+}
+
+// geoJSONCoord returns coordinate for GeoJSON position.
+func geoJSONCoord(pos []float64) (Coord, error) {
+	if len(pos) < 2 {
+		return Coord{}, fmt.Errorf("%w: position must have at least 2 values", ErrInvalidGeoJSON)
+	}
+	c := Coord{Lon: pos[0], Lat: pos[1]}
+	if len(pos) > 2 {
+		c.Alt = pos[2]
+	}
+	return c, nil
+}
+
+// geoJSONLine adds coordinates element with GeoJSON positions to el.
+func geoJSONLine(el *Element, pos [][]float64) (*Element, error) {
+	crs := make([]Coord, len(pos))
+	for i, p := range pos {
+		c, err := geoJSONCoord(p)
+		if err != nil {
+			return nil, err
+		}
+		crs[i] = c
+	}
+	_ = el.AddChild(CoordinatesFrom(crs))
+	return el, nil
+}
+
+// geoJSONPolygon converts GeoJSON polygon rings to Polygon element.
+func geoJSONPolygon(rings [][][]float64) (*Element, error) {
+	if len(rings) == 0 {
+		return nil, fmt.Errorf("%w: polygon without rings", ErrInvalidGeoJSON)
+	}
+	poly := Polygon()
+	for i, ring := range rings {
+		lr, err := geoJSONLine(LinearRing(), ring)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			_ = poly.AddChild(OuterBoundaryIs(lr))
+		} else {
+			_ = poly.AddChild(InnerBoundaryIs(lr))
+		}
+	}
+	return poly, nil
+}
@@ -0,0 +1,451 @@
+package kml
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+
+	kit "github.com/rzajac/testkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ToGeoJSON(t *testing.T) {
+	// --- Given ---
+	root, err := Parse(kit.OpenFile(t, "testdata/geojson.kml"))
+	require.NoError(t, err)
+
+	// --- When ---
+	fc, err := ToGeoJSON(root, GeoJSONOptions{})
+
+	// --- Then ---
+	require.NoError(t, err)
+	data, err := json.Marshal(fc)
+	require.NoError(t, err)
+
+	exp := `{"type":"FeatureCollection","features":[` +
+		`{"type":"Feature","id":"pm_0","geometry":{"type":"Point","coordinates":[1.5,2.5,10]},` +
+		`"properties":{"cost":12.5,"description":"Trip start","kind":"camp","name":"Start","note":"quiet","open":true,"rating":4}},` +
+		`{"type":"Feature","id":"pm_1","geometry":{"type":"LineString","coordinates":[[1,2],[3,4]]},` +
+		`"properties":{"name":"Road"}},` +
+		`{"type":"Feature","id":"pm_2","geometry":{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[2,2],[4,2],[4,4],[2,2]]]},` +
+		`"properties":{}},` +
+		`{"type":"Feature","geometry":{"type":"LineString","coordinates":[[1,2,3],[4,5,6]]},"properties":{}},` +
+		`{"type":"Feature","geometry":{"type":"MultiPoint","coordinates":[[1,1],[2,2]]},"properties":{}},` +
+		`{"type":"Feature","geometry":{"type":"GeometryCollection","geometries":[` +
+		`{"type":"Point","coordinates":[1,1]},{"type":"LineString","coordinates":[[1,1],[2,2]]}]},"properties":{}},` +
+		`{"type":"Feature","geometry":null,"properties":{"name":"No geometry"}}]}`
+	assert.JSONEq(t, exp, string(data))
+}
+
+func Test_ToGeoJSON_Options(t *testing.T) {
+	// --- Given ---
+	root, err := Parse(kit.OpenFile(t, "testdata/geojson.kml"))
+	require.NoError(t, err)
+	opts := GeoJSONOptions{FolderPath: true, Styles: true}
+
+	// --- When ---
+	fc, err := ToGeoJSON(root, opts)
+
+	// --- Then ---
+	require.NoError(t, err)
+	require.Len(t, fc.Features, 7)
+
+	pm0 := fc.Features[0].Properties
+	assert.Exactly(t, []string{"Trip"}, pm0["folders"])
+	assert.Exactly(t, "#ff0000", pm0["marker-color"])
+	assert.Exactly(t, "large", pm0["marker-size"])
+
+	pm1 := fc.Features[1].Properties
+	assert.Exactly(t, []string{"Trip", "Day 1"}, pm1["folders"])
+	assert.Exactly(t, "#ff0000", pm1["stroke"])
+	assert.Exactly(t, 0.502, pm1["stroke-opacity"])
+	assert.Exactly(t, 3.0, pm1["stroke-width"])
+	assert.NotContains(t, pm1, "fill")
+
+	pm2 := fc.Features[2].Properties
+	assert.Exactly(t, "#00ff00", pm2["fill"])
+	assert.Exactly(t, 0.0, pm2["fill-opacity"])
+
+	trk := fc.Features[3].Properties
+	assert.Exactly(t, []string{"Trip", "Day 1", "Tracks"}, trk["folders"])
+}
+
+func Test_ToGeoJSON_Subtree(t *testing.T) {
+	// --- Given ---
+	root, err := Parse(kit.OpenFile(t, "testdata/geojson.kml"))
+	require.NoError(t, err)
+	fld := root.FindFirst(ElemFolder)
+
+	// --- When ---
+	fc, err := ToGeoJSON(fld, GeoJSONOptions{FolderPath: true, Styles: true})
+
+	// --- Then ---
+	require.NoError(t, err)
+	require.Len(t, fc.Features, 3)
+	assert.Exactly(t, "pm_1", fc.Features[0].ID)
+	assert.Exactly(t, []string{"Trip", "Day 1"}, fc.Features[0].Properties["folders"])
+	assert.Exactly(t, "#ff0000", fc.Features[0].Properties["stroke"])
+
+	// --- When ---
+	fc, err = ToGeoJSON(root.FindByID("pm_2"), GeoJSONOptions{FolderPath: true})
+
+	// --- Then ---
+	require.NoError(t, err)
+	require.Len(t, fc.Features, 1)
+	assert.Exactly(t, []string{"Trip", "Day 1"}, fc.Features[0].Properties["folders"])
+}
+
+func Test_ToGeoJSON_Errors(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		pm  *Element
+		err error
+	}{
+		{"coordinates", Placemark(Point(Coordinates("1,a"))), ErrInvalidCoordinates},
+		{"missing", Placemark(LineString()), ErrInvalidGeometry},
+		{"track", Placemark(GxTrack(GxCoord("1 2 3 4"))), ErrInvalidCoordinates},
+		{"polygon", Placemark(Polygon()), ErrInvalidGeometry},
+		{"color", Placemark(Style("sty_0", LineStyle(Color("red")))), ErrInvalidColor},
+		{"schema", Document(
+			Schema("sch_0", "s", SimpleField("int", "n")),
+			Placemark(ExtendedData(SchemaData("#sch_0", SimpleData("n", "x")))),
+		), ErrInvalidContent},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			fc, err := ToGeoJSON(tc.pm, GeoJSONOptions{Styles: true})
+
+			// --- Then ---
+			assert.Nil(t, fc)
+			assert.True(t, errors.Is(err, tc.err), err)
+		})
+	}
+}
+
+func Test_ToGeoJSON_Unsupported(t *testing.T) {
+	// --- Given ---
+	pm := Placemark(Name("model"), Model())
+
+	// --- When ---
+	fc, err := ToGeoJSON(pm, GeoJSONOptions{})
+
+	// --- Then ---
+	require.NoError(t, err)
+	require.Len(t, fc.Features, 1)
+	assert.Nil(t, fc.Features[0].Geometry)
+}
+
+func Test_ToGeoJSON_MultiGeometryUnsupported(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		pm  *Element
+		exp *GeoJSONGeometry
+	}{
+		{"skipped", Placemark(MultiGeometry(Point(Coordinates("1,2")), Model(), Point(Coordinates("3,4")))),
+			&GeoJSONGeometry{Type: GeoJSONMultiPoint, Coordinates: [][]float64{{1, 2}, {3, 4}}}},
+		{"nested", Placemark(MultiGeometry(Point(Coordinates("1,2")), MultiGeometry(Model()))),
+			&GeoJSONGeometry{Type: GeoJSONMultiPoint, Coordinates: [][]float64{{1, 2}}}},
+		{"only unsupported", Placemark(MultiGeometry(Model(), Model())), nil},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			fc, err := ToGeoJSON(tc.pm, GeoJSONOptions{})
+
+			// --- Then ---
+			require.NoError(t, err)
+			require.Len(t, fc.Features, 1)
+			assert.Exactly(t, tc.exp, fc.Features[0].Geometry)
+		})
+	}
+}
+
+func Test_ToGeoJSON_ForeignNamespace(t *testing.T) {
+	// --- Given ---
+	trk := NewElementNS("http://example.com/ns", "Track", GxCoord("1 2 3"))
+	pm := Placemark(Name("foreign"), trk)
+
+	// --- When ---
+	fc, err := ToGeoJSON(pm, GeoJSONOptions{})
+
+	// --- Then ---
+	require.NoError(t, err)
+	require.Len(t, fc.Features, 1)
+	assert.Nil(t, fc.Features[0].Geometry)
+	assert.False(t, isGeometry(trk))
+	assert.True(t, isGeometry(GxTrack()))
+}
+
+func Test_Element_ContentGxCoord(t *testing.T) {
+	// --- Given ---
+	el := GxCoord(" -122.2 37.4\t150 ")
+
+	// --- When ---
+	got, err := el.ContentGxCoord()
+
+	// --- Then ---
+	require.NoError(t, err)
+	assert.Exactly(t, Coord{Lon: -122.2, Lat: 37.4, Alt: 150}, got)
+
+	_, err = GxCoord("1 x").ContentGxCoord()
+	assert.ErrorIs(t, err, ErrInvalidCoordinates)
+}
+
+func Test_ParseGeoJSON(t *testing.T) {
+	// --- Given ---
+	fil := kit.OpenFile(t, "testdata/geojson.json")
+
+	// --- When ---
+	root, err := ParseGeoJSON(fil)
+
+	// --- Then ---
+	require.NoError(t, err)
+	data, err := xml.Marshal(root.ChildAtIdx(0))
+	require.NoError(t, err)
+
+	exp := `<Document>` +
+		`<Style id="sty_0"><IconStyle><color>ff0000ff</color><scale>1.5</scale></IconStyle></Style>` +
+		`<Style id="sty_1"><LineStyle><color>ff0000ff</color><width>3</width></LineStyle></Style>` +
+		`<Schema id="sch_0" name="properties">` +
+		`<SimpleField type="double" name="cost"></SimpleField>` +
+		`<SimpleField type="bool" name="open"></SimpleField>` +
+		`<SimpleField type="int" name="rating"></SimpleField>` +
+		`</Schema>` +
+		`<Placemark id="stop_1"><name>Start</name><description>Trip start</description><styleUrl>#sty_0</styleUrl>` +
+		`<ExtendedData><SchemaData schemaUrl="#sch_0">` +
+		`<SimpleData name="cost">12.5</SimpleData><SimpleData name="open">true</SimpleData><SimpleData name="rating">4</SimpleData>` +
+		`</SchemaData></ExtendedData>` +
+		`<Point><coordinates>1.5,2.5,10</coordinates></Point></Placemark>` +
+		`<Placemark><name>Road</name><styleUrl>#sty_1</styleUrl>` +
+		`<ExtendedData><SchemaData schemaUrl="#sch_0">` +
+		`<SimpleData name="cost">1</SimpleData><SimpleData name="rating">3</SimpleData>` +
+		`</SchemaData></ExtendedData>` +
+		`<LineString><coordinates>1,2 3,4</coordinates></LineString></Placemark>` +
+		`<Placemark><styleUrl>#sty_0</styleUrl><Polygon>` +
+		`<outerBoundaryIs><LinearRing><coordinates>0,0 10,0 10,10 0,10 0,0</coordinates></LinearRing></outerBoundaryIs>` +
+		`<innerBoundaryIs><LinearRing><coordinates>2,2 4,2 4,4 2,2</coordinates></LinearRing></innerBoundaryIs>` +
+		`</Polygon></Placemark>` +
+		`<Placemark><MultiGeometry>` +
+		`<MultiGeometry><Point><coordinates>1,1</coordinates></Point><Point><coordinates>2,2</coordinates></Point></MultiGeometry>` +
+		`<MultiGeometry><LineString><coordinates>1,1 2,2</coordinates></LineString></MultiGeometry>` +
+		`<MultiGeometry><Polygon><outerBoundaryIs><LinearRing><coordinates>0,0 1,0 1,1 0,0</coordinates></LinearRing></outerBoundaryIs></Polygon></MultiGeometry>` +
+		`</MultiGeometry></Placemark>` +
+		`<Placemark></Placemark>` +
+		`</Document>`
+	assert.Exactly(t, exp, string(data))
+	assert.Nil(t, Validate(root))
+}
+
+func Test_FromGeoJSON_Data(t *testing.T) {
+	// --- Given ---
+	fc := &GeoJSONFeatureCollection{
+		Type: GeoJSONFeatureCollectionType,
+		Features: []*GeoJSONFeature{
+			{
+				Type:       GeoJSONFeatureType,
+				Properties: map[string]interface{}{"a": "x", "b": []interface{}{1.0, "y"}},
+			},
+			{
+				Type:       GeoJSONFeatureType,
+				Properties: map[string]interface{}{"a": 1.0, "fill-opacity": 0.5},
+			},
+		},
+	}
+
+	// --- When ---
+	root, err := FromGeoJSON(fc)
+
+	// --- Then ---
+	require.NoError(t, err)
+	data, err := xml.Marshal(root.ChildAtIdx(0))
+	require.NoError(t, err)
+
+	exp := `<Document>` +
+		`<Style id="sty_0"><PolyStyle><color>80555555</color></PolyStyle></Style>` +
+		`<Placemark><ExtendedData>` +
+		`<Data name="a"><value>x</value></Data><Data name="b"><value>[1,&#34;y&#34;]</value></Data>` +
+		`</ExtendedData></Placemark>` +
+		`<Placemark><styleUrl>#sty_0</styleUrl><ExtendedData><Data name="a"><value>1</value></Data></ExtendedData></Placemark>` +
+		`</Document>`
+	assert.Exactly(t, exp, string(data))
+}
+
+func Test_FromGeoJSON_IDs(t *testing.T) {
+	// --- Given ---
+	fc := &GeoJSONFeatureCollection{
+		Type: GeoJSONFeatureCollectionType,
+		Features: []*GeoJSONFeature{
+			{
+				Type:       GeoJSONFeatureType,
+				ID:         "sch_0",
+				Properties: map[string]interface{}{"a": 1.0},
+			},
+			{
+				Type:       GeoJSONFeatureType,
+				ID:         "sty_0",
+				Properties: map[string]interface{}{"a": 2.0, "fill-opacity": 0.5},
+			},
+			{
+				Type:       GeoJSONFeatureType,
+				ID:         "sty_0",
+				Properties: map[string]interface{}{"a": 3.0},
+			},
+		},
+	}
+
+	// --- When ---
+	root, err := FromGeoJSON(fc)
+
+	// --- Then ---
+	require.NoError(t, err)
+	data, err := xml.Marshal(root.ChildAtIdx(0))
+	require.NoError(t, err)
+
+	exp := `<Document>` +
+		`<Style id="sty_1"><PolyStyle><color>80555555</color></PolyStyle></Style>` +
+		`<Schema id="sch_1" name="properties"><SimpleField type="int" name="a"></SimpleField></Schema>` +
+		`<Placemark id="sch_0"><ExtendedData><SchemaData schemaUrl="#sch_1">` +
+		`<SimpleData name="a">1</SimpleData></SchemaData></ExtendedData></Placemark>` +
+		`<Placemark id="sty_0"><styleUrl>#sty_1</styleUrl><ExtendedData><SchemaData schemaUrl="#sch_1">` +
+		`<SimpleData name="a">2</SimpleData></SchemaData></ExtendedData></Placemark>` +
+		`<Placemark><ExtendedData><SchemaData schemaUrl="#sch_1">` +
+		`<SimpleData name="a">3</SimpleData></SchemaData></ExtendedData></Placemark>` +
+		`</Document>`
+	assert.Exactly(t, exp, string(data))
+}
+
+func Test_FromGeoJSON_NonStringName(t *testing.T) {
+	// --- Given ---
+	fc := &GeoJSONFeatureCollection{
+		Type: GeoJSONFeatureCollectionType,
+		Features: []*GeoJSONFeature{
+			{
+				Type:       GeoJSONFeatureType,
+				Properties: map[string]interface{}{"name": 7.0, "description": []interface{}{"x"}},
+			},
+		},
+	}
+
+	// --- When ---
+	root, err := FromGeoJSON(fc)
+
+	// --- Then ---
+	require.NoError(t, err)
+	data, err := xml.Marshal(root.ChildAtIdx(0))
+	require.NoError(t, err)
+
+	exp := `<Document><Placemark><ExtendedData>` +
+		`<Data name="description"><value>[&#34;x&#34;]</value></Data>` +
+		`<Data name="name"><value>7</value></Data>` +
+		`</ExtendedData></Placemark></Document>`
+	assert.Exactly(t, exp, string(data))
+}
+
+func Test_FromGeoJSON_SchemaTypes(t *testing.T) {
+	// --- Given ---
+	fc := &GeoJSONFeatureCollection{
+		Type: GeoJSONFeatureCollectionType,
+		Features: []*GeoJSONFeature{
+			{
+				Type:       GeoJSONFeatureType,
+				Properties: map[string]interface{}{"name": "x", "description": 1.0, "a": 1.0},
+			},
+			{
+				Type:       GeoJSONFeatureType,
+				Properties: map[string]interface{}{"name": 7.0, "description": true, "a": "y"},
+			},
+		},
+	}
+
+	// --- When ---
+	root, err := FromGeoJSON(fc)
+
+	// --- Then ---
+	require.NoError(t, err)
+	data, err := xml.Marshal(root.ChildAtIdx(0))
+	require.NoError(t, err)
+
+	exp := `<Document>` +
+		`<Schema id="sch_0" name="properties">` +
+		`<SimpleField type="string" name="a"></SimpleField>` +
+		`<SimpleField type="string" name="description"></SimpleField>` +
+		`<SimpleField type="int" name="name"></SimpleField>` +
+		`</Schema>` +
+		`<Placemark><name>x</name><ExtendedData><SchemaData schemaUrl="#sch_0">` +
+		`<SimpleData name="a">1</SimpleData><SimpleData name="description">1</SimpleData>` +
+		`</SchemaData></ExtendedData></Placemark>` +
+		`<Placemark><ExtendedData><SchemaData schemaUrl="#sch_0">` +
+		`<SimpleData name="a">y</SimpleData><SimpleData name="description">true</SimpleData><SimpleData name="name">7</SimpleData>` +
+		`</SchemaData></ExtendedData></Placemark>` +
+		`</Document>`
+	assert.Exactly(t, exp, string(data))
+}
+
+func Test_GeoJSON_RoundTrip(t *testing.T) {
+	// --- Given ---
+	root, err := ParseGeoJSON(kit.OpenFile(t, "testdata/geojson.json"))
+	require.NoError(t, err)
+
+	// --- When ---
+	fc, err := ToGeoJSON(root, GeoJSONOptions{Styles: true})
+
+	// --- Then ---
+	require.NoError(t, err)
+	require.Len(t, fc.Features, 5)
+
+	ft := fc.Features[0]
+	assert.Exactly(t, "stop_1", ft.ID)
+	assert.Exactly(t, []float64{1.5, 2.5, 10}, ft.Geometry.Coordinates)
+	exp := map[string]interface{}{
+		"name":         "Start",
+		"description":  "Trip start",
+		"rating":       4,
+		"cost":         12.5,
+		"open":         true,
+		"marker-color": "#ff0000",
+		"marker-size":  "large",
+	}
+	assert.Exactly(t, exp, ft.Properties)
+	assert.Exactly(t, 3.0, fc.Features[1].Properties["stroke-width"])
+	assert.Exactly(t, GeoJSONGeometryCollection, fc.Features[3].Geometry.Type)
+	assert.Nil(t, fc.Features[4].Geometry)
+}
+
+func Test_ParseGeoJSON_Errors(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		json string
+		err  error
+	}{
+		{"syntax", `{"type":`, ErrInvalidGeoJSON},
+		{"type", `{"type":"Feature"}`, ErrInvalidGeoJSON},
+		{"feature", `{"type":"FeatureCollection","features":[{"type":"Point"}]}`, ErrInvalidGeoJSON},
+		{"geometry", `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Circle"}}]}`, ErrUnsupportedGeometry},
+		{"coordinates", `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[[1,2]]}}]}`, ErrInvalidGeoJSON},
+		{"position", `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[1]}}]}`, ErrInvalidGeoJSON},
+		{"polygon", `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Polygon","coordinates":[]}}]}`, ErrInvalidGeoJSON},
+		{"color", `{"type":"FeatureCollection","features":[{"type":"Feature","properties":{"stroke":"red"}}]}`, ErrInvalidColor},
+		{"width", `{"type":"FeatureCollection","features":[{"type":"Feature","properties":{"stroke-width":"wide"}}]}`, ErrInvalidGeoJSON},
+		{"size", `{"type":"FeatureCollection","features":[{"type":"Feature","properties":{"marker-size":"huge"}}]}`, ErrInvalidGeoJSON},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			root, err := ParseGeoJSON(strings.NewReader(tc.json))
+
+			// --- Then ---
+			assert.Nil(t, root)
+			assert.True(t, errors.Is(err, tc.err), err)
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package kml
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrInvalidGeometry is returned when geometry element violates KML
+// geometry rules.
+var ErrInvalidGeometry = errors.New("invalid geometry")
+
+// GeometryError describes geometry validation violation.
+type GeometryError struct {
+	Name string   // Local name of the offending element.
+	Path string   // Absolute path of the offending element.
+	Pos  Position // Position of the offending element.
+	Err  error    // Violation.
+}
+
+// newGeometryError returns GeometryError for element el.
+func newGeometryError(el *Element, err error) *GeometryError {
+	return &GeometryError{
+		Name: el.LocalName(),
+		Path: el.Path(),
+		Pos:  el.Position(),
+		Err:  err,
+	}
+}
+
+func (e *GeometryError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", e.Pos, e.Path, e.Err)
+}
+
+// Unwrap returns underlying error.
+func (e *GeometryError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateGeometry validates all geometries in the element tree rooted at
+// el and returns list of violations. It checks that linear rings are
+// closed, have at least four positions and that polygon inner boundaries
+// are inside the outer boundary. Returns nil when no violations were found.
+func ValidateGeometry(el *Element) []error {
+	var errs []error
+	_ = el.Walk(func(el *Element, _ int) error {
+		switch el.LocalName() {
+		case ElemLinearRing:
+			validateRing(el, &errs)
+			return SkipSubtree
+
+		case ElemPolygon:
+			validatePolygon(el, &errs)
+			return SkipSubtree
+		}
+		return nil
+	})
+	return errs
+}
+
+// validateRing validates LinearRing element and returns its coordinates.
+// Returns nil if ring coordinates cannot be used for further checks.
+func validateRing(ring *Element, errs *[]error) []Coord {
+	cor := ring.ChildByName(ElemCoordinates)
+	if cor == nil {
+		*errs = append(*errs, newGeometryError(ring, geometryErr("missing coordinates")))
+		return nil
+	}
+
+	crs, err := cor.Coords()
+	if err != nil {
+		*errs = append(*errs, err)
+		return nil
+	}
+
+	if len(crs) < 4 {
+		msg := fmt.Sprintf("ring has %d positions, at least 4 required", len(crs))
+		*errs = append(*errs, newGeometryError(cor, geometryErr(msg)))
+		return nil
+	}
+
+	if crs[0] != crs[len(crs)-1] {
+		*errs = append(*errs, newGeometryError(cor, geometryErr("ring is not closed")))
+		return nil
+	}
+
+	return crs
+}
+
+// validatePolygon validates Polygon element and its boundaries.
+func validatePolygon(poly *Element, errs *[]error) {
+	var outer []Coord
+	var outerFound bool
+	for _, bnd := range poly.children {
+		if bnd.LocalName() != ElemOuterBoundaryIs {
+			continue
+		}
+		outerFound = true
+		if ring := bnd.ChildByName(ElemLinearRing); ring != nil {
+			outer = validateRing(ring, errs)
+		} else {
+			*errs = append(*errs, newGeometryError(bnd, geometryErr("missing LinearRing")))
+		}
+	}
+
+	if !outerFound {
+		*errs = append(*errs, newGeometryError(poly, geometryErr("missing outerBoundaryIs")))
+	}
+
+	for _, bnd := range poly.children {
+		if bnd.LocalName() != ElemInnerBoundaryIs {
+			continue
+		}
+		ring := bnd.ChildByName(ElemLinearRing)
+		if ring == nil {
+			*errs = append(*errs, newGeometryError(bnd, geometryErr("missing LinearRing")))
+			continue
+		}
+		inner := validateRing(ring, errs)
+		if outer == nil || inner == nil {
+			continue
+		}
+		if !ringInside(inner, outer) {
+			*errs = append(*errs, newGeometryError(ring, geometryErr("inner ring is not inside outer ring")))
+		}
+	}
+}
+
+// geometryErr returns ErrInvalidGeometry wrapped with message.
+func geometryErr(msg string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidGeometry, msg)
+}
+
+// ringInside returns true if all positions of inner ring are inside or on
+// the boundary of outer ring and no inner ring edge crosses outer ring
+// edge. Edges may touch the outer ring.
+func ringInside(inner, outer []Coord) bool {
+	for _, c := range inner {
+		if !pointInRing(c, outer) {
+			return false
+		}
+	}
+	for i := 1; i < len(inner); i++ {
+		for j := 1; j < len(outer); j++ {
+			if segmentsCross(inner[i-1], inner[i], outer[j-1], outer[j]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// segmentsCross returns true if segments a-b and c-d intersect in a single
+// point which is not an endpoint of any of them.
+func segmentsCross(a, b, c, d Coord) bool {
+	d1 := orientation(c, d, a)
+	d2 := orientation(c, d, b)
+	d3 := orientation(a, b, c)
+	d4 := orientation(a, b, d)
+	return d1*d2 < 0 && d3*d4 < 0
+}
+
+// orientation returns positive value if p is on the left of the line
+// going through a and b, negative value if it is on the right and zero if
+// the points are collinear.
+func orientation(a, b, p Coord) float64 {
+	return (b.Lon-a.Lon)*(p.Lat-a.Lat) - (b.Lat-a.Lat)*(p.Lon-a.Lon)
+}
+
+// pointInRing returns true if point p is inside or on the boundary of the
+// closed ring. Uses ray casting on longitude and latitude.
+func pointInRing(p Coord, ring []Coord) bool {
+	in := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if onSegment(p, a, b) {
+			return true
+		}
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) {
+			x := (b.Lon-a.Lon)*(p.Lat-a.Lat)/(b.Lat-a.Lat) + a.Lon
+			if p.Lon < x {
+				in = !in
+			}
+		}
+	}
+	return in
+}
+
+// onSegment returns true if point p lies on segment a-b.
+func onSegment(p, a, b Coord) bool {
+	if orientation(a, b, p) != 0 {
+		return false
+	}
+	return p.Lon >= math.Min(a.Lon, b.Lon) && p.Lon <= math.Max(a.Lon, b.Lon) &&
+		p.Lat >= math.Min(a.Lat, b.Lat) && p.Lat <= math.Max(a.Lat, b.Lat)
+}
@@ -0,0 +1,129 @@
+package kml
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ValidateGeometry_Valid(t *testing.T) {
+	// --- Given ---
+	pm := Placemark(
+		MultiGeometry(
+			Point(Coordinates("1,2")),
+			Polygon(
+				Extrude(true),
+				OuterBoundaryIs(LinearRing(Coordinates("0,0 10,0 10,10 0,10 0,0"))),
+				InnerBoundaryIs(LinearRing(Coordinates("1,1 2,1 2,2 1,1"))),
+				InnerBoundaryIs(LinearRing(Coordinates("0,0 5,0 5,5 0,0"))),
+			),
+			LinearRing(Coordinates("0,0,1 1,0,1 1,1,1 0,0,1")),
+			Polygon(
+				OuterBoundaryIs(LinearRing(Coordinates("0,0 10,0 10,10 7,10 7,3 3,3 3,10 0,10 0,0"))),
+				InnerBoundaryIs(LinearRing(Coordinates("1,1 9,1 9,2 1,1"))),
+				InnerBoundaryIs(LinearRing(Coordinates("1,3 3,3 2,5 1,3"))),
+			),
+		),
+	)
+
+	// --- When ---
+	errs := ValidateGeometry(pm)
+
+	// --- Then ---
+	assert.Nil(t, errs)
+}
+
+func Test_ValidateGeometry_Violations(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		elm *Element
+		exp string
+	}{
+		{
+			"ring not closed",
+			LinearRing(Coordinates("0,0 1,0 1,1 0,1")),
+			"0:0: /Placemark/LinearRing/coordinates: invalid geometry: ring is not closed",
+		},
+		{
+			"ring too short",
+			LinearRing(Coordinates("0,0 1,0 0,0")),
+			"0:0: /Placemark/LinearRing/coordinates: invalid geometry: ring has 3 positions, at least 4 required",
+		},
+		{
+			"ring without coordinates",
+			LinearRing(),
+			"0:0: /Placemark/LinearRing: invalid geometry: missing coordinates",
+		},
+		{
+			"polygon without outer boundary",
+			Polygon(),
+			"0:0: /Placemark/Polygon: invalid geometry: missing outerBoundaryIs",
+		},
+		{
+			"boundary without ring",
+			Polygon(OuterBoundaryIs()),
+			"0:0: /Placemark/Polygon/outerBoundaryIs: invalid geometry: missing LinearRing",
+		},
+		{
+			"inner ring outside",
+			Polygon(
+				OuterBoundaryIs(LinearRing(Coordinates("0,0 10,0 10,10 0,10 0,0"))),
+				InnerBoundaryIs(LinearRing(Coordinates("1,1 11,1 2,2 1,1"))),
+			),
+			"0:0: /Placemark/Polygon/innerBoundaryIs/LinearRing: invalid geometry: inner ring is not inside outer ring",
+		},
+		{
+			"inner ring crosses concave outer ring",
+			Polygon(
+				OuterBoundaryIs(LinearRing(Coordinates("0,0 10,0 10,10 7,10 7,3 3,3 3,10 0,10 0,0"))),
+				InnerBoundaryIs(LinearRing(Coordinates("1,8 9,8 5,1 1,8"))),
+			),
+			"0:0: /Placemark/Polygon/innerBoundaryIs/LinearRing: invalid geometry: inner ring is not inside outer ring",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			errs := ValidateGeometry(Placemark(tc.elm))
+
+			// --- Then ---
+			require.Len(t, errs, 1)
+			assert.True(t, errors.Is(errs[0], ErrInvalidGeometry))
+			assert.EqualError(t, errs[0], tc.exp)
+		})
+	}
+}
+
+func Test_ValidateGeometry_InvalidCoordinates(t *testing.T) {
+	// --- Given ---
+	ring := LinearRing(Coordinates("0,0 1"))
+
+	// --- When ---
+	errs := ValidateGeometry(ring)
+
+	// --- Then ---
+	require.Len(t, errs, 1)
+	assert.True(t, errors.Is(errs[0], ErrInvalidCoordinates))
+}
+
+func Test_ValidateGeometry_ReportsPosition(t *testing.T) {
+	// --- Given ---
+	cor := Coordinates("0,0 1,0 1,1 0,1")
+	cor.pos = Position{Offset: 123, EndOffset: 160, Line: 4, Column: 9}
+
+	// --- When ---
+	errs := ValidateGeometry(LinearRing(cor))
+
+	// --- Then ---
+	require.Len(t, errs, 1)
+	var gErr *GeometryError
+	require.True(t, errors.As(errs[0], &gErr))
+	assert.Exactly(t, Position{Offset: 123, EndOffset: 160, Line: 4, Column: 9}, gErr.Pos)
+	assert.Exactly(t, ElemCoordinates, gErr.Name)
+	assert.Exactly(t, "/LinearRing/coordinates", gErr.Path)
+	assert.EqualError(t, gErr, "4:9: /LinearRing/coordinates: invalid geometry: ring is not closed")
+}
@@ -1,10 +1,14 @@
 module github.com/rzajac/kml
 
-go 1.15
+go 1.19
 
 require (
-	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/rzajac/testkit v0.10.0
 	github.com/stretchr/testify v1.7.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
 )
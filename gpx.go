@@ -0,0 +1,541 @@
+package kml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NsGPX is GPX 1.1 namespace.
+const NsGPX = "http://www.topografix.com/GPX/1/1"
+
+// ErrInvalidGPX is returned when GPX cannot be converted to KML.
+var ErrInvalidGPX = errors.New("invalid GPX")
+
+// GPX represents GPX 1.1 document.
+type GPX struct {
+	XMLName   xml.Name       `xml:"http://www.topografix.com/GPX/1/1 gpx"`
+	Version   string         `xml:"version,attr"`
+	Creator   string         `xml:"creator,attr"`
+	Metadata  *GPXMetadata   `xml:"metadata,omitempty"`
+	Waypoints []*GPXWaypoint `xml:"wpt"`
+	Routes    []*GPXRoute    `xml:"rte"`
+	Tracks    []*GPXTrack    `xml:"trk"`
+}
+
+// GPXMetadata represents GPX document metadata.
+type GPXMetadata struct {
+	Name string `xml:"name,omitempty"`
+	Desc string `xml:"desc,omitempty"`
+}
+
+// GPXWaypoint represents GPX waypoint, route point or track point.
+type GPXWaypoint struct {
+	Lat        float64        `xml:"lat,attr"`
+	Lon        float64        `xml:"lon,attr"`
+	Ele        *float64       `xml:"ele,omitempty"`
+	Time       *time.Time     `xml:"time,omitempty"`
+	Name       string         `xml:"name,omitempty"`
+	Desc       string         `xml:"desc,omitempty"`
+	Extensions *GPXExtensions `xml:"extensions,omitempty"`
+}
+
+// GPXRoute represents GPX route.
+type GPXRoute struct {
+	Name       string         `xml:"name,omitempty"`
+	Desc       string         `xml:"desc,omitempty"`
+	Extensions *GPXExtensions `xml:"extensions,omitempty"`
+	Points     []*GPXWaypoint `xml:"rtept"`
+}
+
+// GPXTrack represents GPX track.
+type GPXTrack struct {
+	Name       string         `xml:"name,omitempty"`
+	Desc       string         `xml:"desc,omitempty"`
+	Extensions *GPXExtensions `xml:"extensions,omitempty"`
+	Segments   []*GPXSegment  `xml:"trkseg"`
+}
+
+// GPXSegment represents GPX track segment.
+type GPXSegment struct {
+	Points []*GPXWaypoint `xml:"trkpt"`
+}
+
+// GPXExtensions represents content of GPX extensions element.
+type GPXExtensions struct {
+	Nodes []*GPXExtension `xml:",any"`
+}
+
+// GPXExtension represents single element in GPX extensions.
+type GPXExtension struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr      `xml:",any,attr"`
+	Value   string          `xml:",chardata"`
+	Nodes   []*GPXExtension `xml:",any"`
+}
+
+// values appends local names and values of extension elements without
+// child elements to names and vals.
+func (ext *GPXExtensions) values(names, vals []string) ([]string, []string) {
+	if ext == nil {
+		return names, vals
+	}
+	var walk func(nodes []*GPXExtension)
+	walk = func(nodes []*GPXExtension) {
+		for _, n := range nodes {
+			if len(n.Nodes) > 0 {
+				walk(n.Nodes)
+				continue
+			}
+			names = append(names, n.XMLName.Local)
+			vals = append(vals, strings.TrimSpace(n.Value))
+		}
+	}
+	walk(ext.Nodes)
+	return names, vals
+}
+
+// GPXOptions configures GPX import.
+type GPXOptions struct {
+	// Convert tracks to LineString instead of gx:Track. Track point
+	// extensions are not converted for such tracks.
+	TrackLineString bool
+}
+
+// gpxTrackSchemaID is the ID of Schema describing track point extensions.
+const gpxTrackSchemaID = "trkpt"
+
+// ParseGPX decodes GPX 1.1 document from r and converts it with FromGPX.
+func ParseGPX(r io.Reader, opts GPXOptions) (*Element, error) {
+	gpx := &GPX{}
+	if err := xml.NewDecoder(r).Decode(gpx); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidGPX, err)
+	}
+	return FromGPX(gpx, opts)
+}
+
+// FromGPX builds KML(Document(...)) element tree from GPX document.
+// Waypoints become Placemarks with Point, routes become Placemarks with
+// LineString and tracks become Placemarks with gx:Track (gx:MultiTrack for
+// tracks with many segments). Track points get when elements only when all
+// points of the track have timestamps. Routes and track segments without
+// points have no geometry. Elevations become altitudes with absolute
+// altitude mode. Waypoint, route and track extensions are stored in
+// ExtendedData as Data elements named by local names of extension
+// elements without children. Track point extensions are stored the same
+// way in gx:SimpleArrayData elements of gx:Track, described by Schema
+// with ID "trkpt" added to the Document.
+func FromGPX(gpx *GPX, opts GPXOptions) (*Element, error) {
+	doc := Document()
+	if gpx.Metadata != nil {
+		if gpx.Metadata.Name != "" {
+			_ = doc.AddChild(Name(gpx.Metadata.Name))
+		}
+		if gpx.Metadata.Desc != "" {
+			_ = doc.AddChild(Description(gpx.Metadata.Desc))
+		}
+	}
+
+	for _, wpt := range gpx.Waypoints {
+		pm := gpxPlacemark(wpt.Name, wpt.Desc, wpt.Extensions)
+		if wpt.Time != nil {
+			_ = pm.InsertChild(TimeStamp(When(formatTime(*wpt.Time))))
+		}
+		crs, abs := gpxCoords([]*GPXWaypoint{wpt})
+		_ = pm.AddChild(gpxGeometry(Point(), crs, abs))
+		_ = doc.AddChild(pm)
+	}
+
+	for _, rte := range gpx.Routes {
+		pm := gpxPlacemark(rte.Name, rte.Desc, rte.Extensions)
+		if crs, abs := gpxCoords(rte.Points); crs != nil {
+			_ = pm.AddChild(gpxGeometry(LineString(), crs, abs))
+		}
+		_ = doc.AddChild(pm)
+	}
+
+	var fields []schemaField
+	if !opts.TrackLineString {
+		fields = gpxTrackFields(gpx.Tracks)
+	}
+	if len(fields) > 0 {
+		sch := Schema(gpxTrackSchemaID, gpxTrackSchemaID)
+		for _, f := range fields {
+			_ = sch.AddChild(GxSimpleArrayField(Attr("type", f.typ), Attr("name", f.name)))
+		}
+		_ = doc.InsertChild(sch)
+	}
+
+	for _, trk := range gpx.Tracks {
+		timed := true
+		for _, seg := range trk.Segments {
+			for _, pt := range seg.Points {
+				timed = timed && pt.Time != nil
+			}
+		}
+
+		var geoms []*Element
+		for _, seg := range trk.Segments {
+			var geom *Element
+			if opts.TrackLineString {
+				if crs, abs := gpxCoords(seg.Points); crs != nil {
+					geom = gpxGeometry(LineString(), crs, abs)
+				}
+			} else {
+				geom = gpxSegment(seg, timed, fields)
+			}
+			if geom != nil {
+				geoms = append(geoms, geom)
+			}
+		}
+
+		pm := gpxPlacemark(trk.Name, trk.Desc, trk.Extensions)
+		switch {
+		case len(geoms) == 1:
+			_ = pm.AddChild(geoms[0])
+		case len(geoms) > 1 && geoms[0].MatchName(ElemGxTrack):
+			_ = pm.AddChild(GxMultiTrack(toInterfaces(geoms)...))
+		case len(geoms) > 1:
+			_ = pm.AddChild(MultiGeometry(toInterfaces(geoms)...))
+		}
+		_ = doc.AddChild(pm)
+	}
+
+	return KML(doc), nil
+}
+
+// gpxPlacemark returns Placemark with name, description and ExtendedData
+// for GPX extensions.
+func gpxPlacemark(name, desc string, ext *GPXExtensions) *Element {
+	pm := Placemark()
+	if name != "" {
+		_ = pm.AddChild(Name(name))
+	}
+	if desc != "" {
+		_ = pm.AddChild(Description(desc))
+	}
+	if names, vals := ext.values(nil, nil); len(names) > 0 {
+		ed := ExtendedData()
+		for i, name := range names {
+			_ = ed.AddChild(Data(name, Value(vals[i])))
+		}
+		_ = pm.AddChild(ed)
+	}
+	return pm
+}
+
+// gpxCoords returns coordinates of GPX points. The abs is true when any of
+// the points has elevation. Returns nil if there are no points.
+func gpxCoords(pts []*GPXWaypoint) (crs []Coord, abs bool) {
+	for _, pt := range pts {
+		c := Coord{Lon: pt.Lon, Lat: pt.Lat}
+		if pt.Ele != nil {
+			c.Alt = *pt.Ele
+			abs = true
+		}
+		crs = append(crs, c)
+	}
+	return crs, abs
+}
+
+// gpxGeometry adds altitude mode and coordinates to geometry element.
+func gpxGeometry(geom *Element, crs []Coord, abs bool) *Element {
+	if abs {
+		_ = geom.AddChild(AltitudeMode(AltitudeModeAbsolute))
+	}
+	_ = geom.AddChild(CoordinatesFrom(crs))
+	return geom
+}
+
+// gpxTrackFields returns Schema fields for track point extensions in order
+// of appearance. Fields with values which are all integers are int, all
+// numbers are double and string otherwise.
+func gpxTrackFields(trks []*GPXTrack) []schemaField {
+	var fields []schemaField
+	idx := make(map[string]int)
+	for _, trk := range trks {
+		for _, seg := range trk.Segments {
+			for _, pt := range seg.Points {
+				names, vals := pt.Extensions.values(nil, nil)
+				for i, name := range names {
+					j, ok := idx[name]
+					if !ok {
+						j = len(fields)
+						idx[name] = j
+						fields = append(fields, schemaField{name: name, typ: SFTypeInt})
+					}
+					fields[j].typ = gpxValueType(fields[j].typ, vals[i])
+				}
+			}
+		}
+	}
+	return fields
+}
+
+// gpxValueType returns field type typ widened, if necessary, to hold val.
+func gpxValueType(typ, val string) string {
+	if val == "" || typ == SFTypeString {
+		return typ
+	}
+	if typ == SFTypeInt {
+		if _, err := strconv.ParseInt(val, 10, 32); err == nil {
+			return typ
+		}
+	}
+	if f, err := strconv.ParseFloat(val, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+		return SFTypeDouble
+	}
+	return SFTypeString
+}
+
+// gpxSegment converts GPX track segment to gx:Track. Points have when
+// elements when timed is true. Values of track point extensions named by
+// fields are added in gx:SimpleArrayData elements. Returns nil for
+// segments without points.
+func gpxSegment(seg *GPXSegment, timed bool, fields []schemaField) *Element {
+	crs, abs := gpxCoords(seg.Points)
+	if crs == nil {
+		return nil
+	}
+
+	trk := GxTrack()
+	if abs {
+		_ = trk.AddChild(AltitudeMode(AltitudeModeAbsolute))
+	}
+	if timed {
+		for _, pt := range seg.Points {
+			_ = trk.AddChild(When(formatTime(*pt.Time)))
+		}
+	}
+	for _, c := range crs {
+		_ = trk.AddChild(GxCoord(formatGxCoord(c)))
+	}
+	if sd := gpxSchemaData(seg.Points, fields); sd != nil {
+		_ = trk.AddChild(ExtendedData(sd))
+	}
+	return trk
+}
+
+// gpxSchemaData returns SchemaData with gx:SimpleArrayData element for
+// each of fields with values of extensions of pts. Points without value
+// have empty gx:value. Returns nil when pts have no extensions.
+func gpxSchemaData(pts []*GPXWaypoint, fields []schemaField) *Element {
+	vals := make([]map[string]string, len(pts))
+	var found bool
+	for i, pt := range pts {
+		names, values := pt.Extensions.values(nil, nil)
+		vals[i] = make(map[string]string, len(names))
+		for j, name := range names {
+			if _, ok := vals[i][name]; !ok {
+				vals[i][name] = values[j]
+				found = true
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+
+	sd := SchemaData("#" + gpxTrackSchemaID)
+	for _, f := range fields {
+		arr := GxSimpleArrayData(Attr("name", f.name))
+		for _, pv := range vals {
+			_ = arr.AddChild(GxValue(pv[f.name]))
+		}
+		_ = sd.AddChild(arr)
+	}
+	return sd
+}
+
+// formatTime formats time in KML dateTime format.
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+// formatGxCoord formats coordinate as gx:coord value.
+func formatGxCoord(c Coord) string {
+	return strconv.FormatFloat(c.Lon, 'f', -1, 64) + " " +
+		strconv.FormatFloat(c.Lat, 'f', -1, 64) + " " +
+		strconv.FormatFloat(c.Alt, 'f', -1, 64)
+}
+
+// toInterfaces returns elements as slice of interfaces.
+func toInterfaces(els []*Element) []interface{} {
+	xes := make([]interface{}, len(els))
+	for i, el := range els {
+		xes[i] = el
+	}
+	return xes
+}
+
+// ToGPX converts Placemarks in the element tree rooted at el to GPX 1.1
+// document. Placemarks are looked up the same way ToGeoJSON does. Points
+// become waypoints, LineStrings become routes and gx:Track and
+// gx:MultiTrack become tracks. Geometries in MultiGeometry are converted
+// individually. Other geometries, ExtendedData (including gx:Track
+// gx:SimpleArrayData) and styles are not converted. Non-zero altitudes
+// become elevations.
+func ToGPX(el *Element) (*GPX, error) {
+	gpx := &GPX{
+		Version: "1.1",
+		Creator: "github.com/rzajac/kml",
+	}
+	if isContainer(el) && el.HasChild(ElemName) {
+		gpx.Metadata = &GPXMetadata{Name: containerName(el)}
+	}
+
+	var pms []*Element
+	if el.LocalName() == ElemPlacemark {
+		pms = append(pms, el)
+	} else {
+		pms = placemarks(el, pms)
+	}
+
+	for _, pm := range pms {
+		var name, desc string
+		if ch := pm.ChildByName(ElemName); ch != nil {
+			name = ch.ContentString()
+		}
+		if ch := pm.ChildByName(ElemDescription); ch != nil {
+			desc = ch.ContentString()
+		}
+		var when *time.Time
+		if ts := pm.ChildByName(ElemTimeStamp); ts != nil && ts.HasChild(ElemWhen) {
+			t, err := ts.ChildTime(ElemWhen)
+			if err != nil {
+				return nil, err
+			}
+			when = &t
+		}
+
+		for _, geom := range pm.children {
+			if !isGeometry(geom) {
+				continue
+			}
+			if err := gpxAdd(gpx, geom, name, desc, when); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return gpx, nil
+}
+
+// placemarks appends Placemarks in container element el and containers it
+// contains to pms.
+func placemarks(el *Element, pms []*Element) []*Element {
+	for _, ch := range el.children {
+		switch {
+		case ch.LocalName() == ElemPlacemark:
+			pms = append(pms, ch)
+		case isContainer(ch):
+			pms = placemarks(ch, pms)
+		}
+	}
+	return pms
+}
+
+// gpxAdd adds GPX waypoints, routes or tracks for geometry element.
+func gpxAdd(gpx *GPX, geom *Element, name, desc string, when *time.Time) error {
+	switch geom.qualifiedName() {
+	case ElemPoint:
+		crs, err := geometryCoords(geom)
+		if err != nil {
+			return err
+		}
+		for _, c := range crs {
+			wpt := gpxPoint(c, when)
+			wpt.Name, wpt.Desc = name, desc
+			gpx.Waypoints = append(gpx.Waypoints, wpt)
+		}
+
+	case ElemLineString:
+		crs, err := geometryCoords(geom)
+		if err != nil {
+			return err
+		}
+		rte := &GPXRoute{Name: name, Desc: desc}
+		for _, c := range crs {
+			rte.Points = append(rte.Points, gpxPoint(c, nil))
+		}
+		gpx.Routes = append(gpx.Routes, rte)
+
+	case ElemGxTrack:
+		seg, err := gpxTrackSegment(geom)
+		if err != nil {
+			return err
+		}
+		gpx.Tracks = append(gpx.Tracks, &GPXTrack{Name: name, Desc: desc, Segments: []*GPXSegment{seg}})
+
+	case ElemGxMultiTrack:
+		trk := &GPXTrack{Name: name, Desc: desc}
+		for _, ch := range geom.children {
+			if !ch.MatchName(ElemGxTrack) {
+				continue
+			}
+			seg, err := gpxTrackSegment(ch)
+			if err != nil {
+				return err
+			}
+			trk.Segments = append(trk.Segments, seg)
+		}
+		gpx.Tracks = append(gpx.Tracks, trk)
+
+	case ElemMultiGeometry:
+		for _, ch := range geom.children {
+			if !isGeometry(ch) {
+				continue
+			}
+			if err := gpxAdd(gpx, ch, name, desc, when); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// gpxPoint returns GPX point for coordinate.
+func gpxPoint(c Coord, when *time.Time) *GPXWaypoint {
+	pt := &GPXWaypoint{Lat: c.Lat, Lon: c.Lon, Time: when}
+	if c.Alt != 0 {
+		ele := c.Alt
+		pt.Ele = &ele
+	}
+	return pt
+}
+
+// gpxTrackSegment converts gx:Track to GPX track segment. Timestamps are
+// assigned to coordinates in order, when the number of when elements does
+// not match number of gx:coord elements points have no timestamps.
+func gpxTrackSegment(trk *Element) (*GPXSegment, error) {
+	crs, err := trackCoords(trk)
+	if err != nil {
+		return nil, err
+	}
+
+	var times []time.Time
+	for _, ch := range trk.children {
+		if ch.LocalName() != ElemWhen {
+			continue
+		}
+		t, err := ch.ContentTime()
+		if err != nil {
+			return nil, err
+		}
+		times = append(times, t)
+	}
+
+	seg := &GPXSegment{}
+	for i, c := range crs {
+		var when *time.Time
+		if len(times) == len(crs) {
+			when = &times[i]
+		}
+		seg.Points = append(seg.Points, gpxPoint(c, when))
+	}
+	return seg, nil
+}
@@ -0,0 +1,247 @@
+package kml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	kit "github.com/rzajac/testkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseGPX(t *testing.T) {
+	// --- Given ---
+	fil := kit.OpenFile(t, "testdata/track.gpx")
+
+	// --- When ---
+	root, err := ParseGPX(fil, GPXOptions{})
+
+	// --- Then ---
+	require.NoError(t, err)
+	data, err := xml.Marshal(root.ChildAtIdx(0))
+	require.NoError(t, err)
+
+	exp := `<Document><name>Field day</name>` +
+		`<Schema name="trkpt" id="trkpt">` +
+		`<gx:SimpleArrayField type="double" name="hr"></gx:SimpleArrayField>` +
+		`<gx:SimpleArrayField type="int" name="cad"></gx:SimpleArrayField>` +
+		`</Schema>` +
+		`<Placemark><name>Camp</name><description>Base camp</description>` +
+		`<TimeStamp><when>2020-05-01T10:00:00Z</when></TimeStamp>` +
+		`<ExtendedData><Data name="tents"><value>3</value></Data><Data name="water"><value>yes</value></Data></ExtendedData>` +
+		`<Point><altitudeMode>absolute</altitudeMode><coordinates>-122.25,37.5,12.5</coordinates></Point></Placemark>` +
+		`<Placemark><name>Route</name><LineString><coordinates>2,1 4,3</coordinates></LineString></Placemark>` +
+		`<Placemark><name>Morning</name><gx:Track><altitudeMode>absolute</altitudeMode>` +
+		`<when>2020-05-01T08:00:00Z</when><when>2020-05-01T08:01:00Z</when>` +
+		`<gx:coord>20 10 100</gx:coord><gx:coord>21 11 110</gx:coord>` +
+		`<ExtendedData><SchemaData schemaUrl="#trkpt">` +
+		`<gx:SimpleArrayData name="hr"><gx:value>120</gx:value><gx:value>125.5</gx:value></gx:SimpleArrayData>` +
+		`<gx:SimpleArrayData name="cad"><gx:value>80</gx:value><gx:value></gx:value></gx:SimpleArrayData>` +
+		`</SchemaData></ExtendedData></gx:Track></Placemark>` +
+		`<Placemark><name>Untimed</name><gx:MultiTrack>` +
+		`<gx:Track><gx:coord>1 1 0</gx:coord><gx:coord>2 2 0</gx:coord></gx:Track>` +
+		`<gx:Track><gx:coord>3 3 0</gx:coord></gx:Track>` +
+		`</gx:MultiTrack></Placemark>` +
+		`</Document>`
+	assert.Exactly(t, exp, string(data))
+	assert.Nil(t, Validate(root))
+}
+
+func Test_ParseGPX_TrackLineString(t *testing.T) {
+	// --- Given ---
+	fil := kit.OpenFile(t, "testdata/track.gpx")
+
+	// --- When ---
+	root, err := ParseGPX(fil, GPXOptions{TrackLineString: true})
+
+	// --- Then ---
+	require.NoError(t, err)
+	assert.Nil(t, root.FindFirst(ElemSchema))
+	pm := root.ChildAtIdx(0).ChildAtIdx(3)
+	require.NotNil(t, pm)
+	data, err := xml.Marshal(pm)
+	require.NoError(t, err)
+
+	exp := `<Placemark><name>Morning</name><LineString><altitudeMode>absolute</altitudeMode>` +
+		`<coordinates>20,10,100 21,11,110</coordinates></LineString></Placemark>`
+	assert.Exactly(t, exp, string(data))
+
+	pm = root.ChildAtIdx(0).ChildAtIdx(4)
+	require.NotNil(t, pm)
+	data, err = xml.Marshal(pm)
+	require.NoError(t, err)
+
+	exp = `<Placemark><name>Untimed</name><MultiGeometry>` +
+		`<LineString><coordinates>1,1 2,2</coordinates></LineString>` +
+		`<LineString><coordinates>3,3</coordinates></LineString>` +
+		`</MultiGeometry></Placemark>`
+	assert.Exactly(t, exp, string(data))
+}
+
+func Test_ParseGPX_MultiTrack(t *testing.T) {
+	// --- Given ---
+	gpx := `<gpx version="1.1" xmlns="http://www.topografix.com/GPX/1/1"><trk>` +
+		`<trkseg><trkpt lat="1" lon="2"><time>2020-05-01T08:00:00Z</time></trkpt></trkseg>` +
+		`<trkseg></trkseg>` +
+		`<trkseg><trkpt lat="3" lon="4"><time>2020-05-01T09:00:00Z</time></trkpt></trkseg>` +
+		`</trk></gpx>`
+
+	// --- When ---
+	root, err := ParseGPX(strings.NewReader(gpx), GPXOptions{})
+
+	// --- Then ---
+	require.NoError(t, err)
+	data, err := xml.Marshal(root.FindFirst(ElemPlacemark))
+	require.NoError(t, err)
+
+	exp := `<Placemark><gx:MultiTrack>` +
+		`<gx:Track><when>2020-05-01T08:00:00Z</when><gx:coord>2 1 0</gx:coord></gx:Track>` +
+		`<gx:Track><when>2020-05-01T09:00:00Z</when><gx:coord>4 3 0</gx:coord></gx:Track>` +
+		`</gx:MultiTrack></Placemark>`
+	assert.Exactly(t, exp, string(data))
+}
+
+func Test_ParseGPX_Errors(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		gpx string
+	}{
+		{"syntax", `<gpx`},
+		{"namespace", `<gpx version="1.0" xmlns="http://www.topografix.com/GPX/1/0"></gpx>`},
+		{"lat", `<gpx xmlns="http://www.topografix.com/GPX/1/1"><wpt lat="x" lon="1"/></gpx>`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			root, err := ParseGPX(strings.NewReader(tc.gpx), GPXOptions{})
+
+			// --- Then ---
+			assert.Nil(t, root)
+			assert.True(t, errors.Is(err, ErrInvalidGPX), err)
+		})
+	}
+}
+
+func Test_ToGPX(t *testing.T) {
+	// --- Given ---
+	root := KML(
+		Document(
+			Name("doc"),
+			Placemark(
+				Name("wpt"),
+				Description("desc"),
+				TimeStamp(When("2020-05-01T10:00:00Z")),
+				Point(Coordinates("1,2,3")),
+			),
+			Folder(
+				Placemark(
+					Name("multi"),
+					MultiGeometry(
+						Point(Coordinates("5,6")),
+						LineString(Coordinates("1,2 3,4")),
+						Polygon(OuterBoundaryIs(LinearRing(Coordinates("0,0 1,0 1,1 0,0")))),
+					),
+				),
+				Placemark(
+					Name("trk"),
+					GxMultiTrack(
+						GxTrack(
+							When("2020-05-01T08:00:00Z"),
+							When("2020-05-01T08:01:00Z"),
+							GxCoord("20 10 100"),
+							GxCoord("21 11 0"),
+						),
+						GxTrack(
+							When("2020-05-01T08:02:00Z"),
+							GxCoord("22 12 0"),
+							GxCoord("23 13 0"),
+						),
+					),
+				),
+			),
+		),
+	)
+
+	// --- When ---
+	gpx, err := ToGPX(root.ChildAtIdx(0))
+
+	// --- Then ---
+	require.NoError(t, err)
+	buf := &bytes.Buffer{}
+	enc := xml.NewEncoder(buf)
+	require.NoError(t, enc.Encode(gpx))
+
+	exp := `<gpx xmlns="http://www.topografix.com/GPX/1/1" version="1.1" creator="github.com/rzajac/kml">` +
+		`<metadata><name>doc</name></metadata>` +
+		`<wpt lat="2" lon="1"><ele>3</ele><time>2020-05-01T10:00:00Z</time><name>wpt</name><desc>desc</desc></wpt>` +
+		`<wpt lat="6" lon="5"><name>multi</name></wpt>` +
+		`<rte><name>multi</name><rtept lat="2" lon="1"></rtept><rtept lat="4" lon="3"></rtept></rte>` +
+		`<trk><name>trk</name>` +
+		`<trkseg><trkpt lat="10" lon="20"><ele>100</ele><time>2020-05-01T08:00:00Z</time></trkpt>` +
+		`<trkpt lat="11" lon="21"><time>2020-05-01T08:01:00Z</time></trkpt></trkseg>` +
+		`<trkseg><trkpt lat="12" lon="22"></trkpt><trkpt lat="13" lon="23"></trkpt></trkseg>` +
+		`</trk></gpx>`
+	assert.Exactly(t, exp, buf.String())
+}
+
+func Test_GPX_RoundTrip(t *testing.T) {
+	// --- Given ---
+	root, err := ParseGPX(kit.OpenFile(t, "testdata/track.gpx"), GPXOptions{})
+	require.NoError(t, err)
+
+	// --- When ---
+	gpx, err := ToGPX(root)
+
+	// --- Then ---
+	require.NoError(t, err)
+	require.Len(t, gpx.Waypoints, 1)
+	require.Len(t, gpx.Routes, 1)
+	require.Len(t, gpx.Tracks, 2)
+
+	wpt := gpx.Waypoints[0]
+	assert.Exactly(t, "Camp", wpt.Name)
+	assert.Exactly(t, 12.5, *wpt.Ele)
+	assert.True(t, time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC).Equal(*wpt.Time))
+
+	trk := gpx.Tracks[0]
+	require.Len(t, trk.Segments, 1)
+	require.Len(t, trk.Segments[0].Points, 2)
+	assert.Exactly(t, 110.0, *trk.Segments[0].Points[1].Ele)
+
+	trk = gpx.Tracks[1]
+	assert.Exactly(t, "Untimed", trk.Name)
+	require.Len(t, trk.Segments, 2)
+	require.Len(t, trk.Segments[0].Points, 2)
+	assert.Nil(t, trk.Segments[0].Points[0].Time)
+}
+
+func Test_ToGPX_Errors(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		pm  *Element
+		err error
+	}{
+		{"coordinates", Placemark(Point(Coordinates("1,x"))), ErrInvalidCoordinates},
+		{"when", Placemark(TimeStamp(When("noon")), Point(Coordinates("1,2"))), ErrInvalidContent},
+		{"track when", Placemark(GxTrack(When("noon"), GxCoord("1 2 3"))), ErrInvalidContent},
+		{"track coord", Placemark(GxTrack(GxCoord("1 2 3 4"))), ErrInvalidCoordinates},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			gpx, err := ToGPX(tc.pm)
+
+			// --- Then ---
+			assert.Nil(t, gpx)
+			assert.True(t, errors.Is(err, tc.err), err)
+		})
+	}
+}
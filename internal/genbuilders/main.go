@@ -0,0 +1,361 @@
+// Command genbuilders generates KML element name constants and builder
+// functions from the bundled XSD schemas.
+//
+// Elements listed in handWritten have hand written constants and builders
+// in the package and are skipped, so the hand written API always takes
+// precedence.
+//
+// Usage (from the repository root):
+//
+//	go run ./internal/genbuilders
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"go/format"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/rzajac/kml/internal/xsd"
+)
+
+// XML namespaces of the bundled schemas.
+const (
+	nsKML  = "http://www.opengis.net/kml/2.2"
+	nsGx   = "http://www.google.com/kml/ext/2.2"
+	nsAtom = "http://www.w3.org/2005/Atom"
+)
+
+// schemaFiles lists bundled schema files in load order.
+var schemaFiles = []string{
+	"atom-author-link.xsd",
+	"ogckml22.xsd",
+	"kml22gx.xsd",
+}
+
+// prefixes maps namespaces to element name prefixes.
+var prefixes = map[string]string{
+	nsKML:  "",
+	nsGx:   "gx:",
+	nsAtom: "atom:",
+}
+
+// overrides maps element names to Go names for elements whose names differ
+// only in case from other elements in the same namespace.
+var overrides = map[string]string{
+	"Scale":   "ModelScale",
+	"snippet": "SnippetDeprecated",
+}
+
+// handWritten lists names of elements with hand written name constants and
+// builders in the package.
+var handWritten = map[string]bool{
+	"BalloonStyle":     true,
+	"Camera":           true,
+	"Data":             true,
+	"Document":         true,
+	"ExtendedData":     true,
+	"Folder":           true,
+	"LabelStyle":       true,
+	"LineString":       true,
+	"LineStyle":        true,
+	"LinearRing":       true,
+	"Model":            true,
+	"MultiGeometry":    true,
+	"Placemark":        true,
+	"Point":            true,
+	"PolyStyle":        true,
+	"Polygon":          true,
+	"Schema":           true,
+	"SchemaData":       true,
+	"SimpleData":       true,
+	"SimpleField":      true,
+	"Snippet":          true,
+	"Style":            true,
+	"altitude":         true,
+	"altitudeMode":     true,
+	"bgColor":          true,
+	"color":            true,
+	"coordinates":      true,
+	"description":      true,
+	"displayMode":      true,
+	"displayName":      true,
+	"extrude":          true,
+	"gx:TimeStamp":     true,
+	"gx:ViewerOptions": true,
+	"gx:option":        true,
+	"heading":          true,
+	"href":             true,
+	"innerBoundaryIs":  true,
+	"kml":              true,
+	"latitude":         true,
+	"longitude":        true,
+	"name":             true,
+	"outerBoundaryIs":  true,
+	"outline":          true,
+	"roll":             true,
+	"scale":            true,
+	"styleUrl":         true,
+	"tessellate":       true,
+	"text":             true,
+	"tilt":             true,
+	"width":            true,
+}
+
+// handWrittenEnums lists hand written enumeration constants.
+var handWrittenEnums = map[string]bool{
+	"DisplayModeDefault": true,
+	"DisplayModeHide":    true,
+}
+
+// initialisms lists words which are written in upper case in Go names.
+var initialisms = map[string]string{
+	"Http": "HTTP",
+	"Kml":  "KML",
+	"Uri":  "URI",
+	"Url":  "URL",
+}
+
+func main() {
+	xsdDir := flag.String("xsd", "xsd", "directory with XSD schemas")
+	pkgDir := flag.String("pkg", ".", "package directory")
+	out := flag.String("o", "elements_gen.go", "output file name")
+	flag.Parse()
+
+	src, err := generate(os.DirFS(*xsdDir))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(*pkgDir, *out), src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// builder represents generated element builder.
+type builder struct {
+	GoName string // Builder function name.
+	Name   string // Element name with namespace prefix.
+	Type   string // Go type of the value or empty for complex elements.
+	Helper string // Helper function creating element with value.
+}
+
+// enum represents generated group of enumeration constants.
+type enum struct {
+	GoName string
+	Values []enumValue
+}
+
+// enumValue represents generated enumeration constant.
+type enumValue struct {
+	GoName string
+	Value  string
+}
+
+// generate returns formatted source of the generated file. Abstract
+// elements, elements listed in handWritten and constants listed in
+// handWrittenEnums are not generated.
+func generate(fsys fs.FS) ([]byte, error) {
+	set, err := xsd.Load(fsys, schemaFiles...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Types of abstract substitution group heads. Elements declared with
+	// such type, like gx:AbstractTourPrimitive, are abstract too even if
+	// the schema doesn't say so.
+	abstract := make(map[xml.Name]bool)
+	for _, decl := range set.Elements {
+		if decl.Abstract && decl.TypeName.Local != "" {
+			abstract[decl.TypeName] = true
+		}
+	}
+
+	var builders []builder
+	enums := make(map[xml.Name]*enum)
+	for qn, decl := range set.Elements {
+		if decl.Abstract || decl.Complex != nil && decl.Complex.Abstract || abstract[decl.TypeName] {
+			continue
+		}
+		prefix, ok := prefixes[qn.Space]
+		if !ok {
+			continue
+		}
+
+		bl := builder{
+			GoName: elemGoName(qn),
+			Name:   prefix + qn.Local,
+		}
+
+		st := decl.Simple
+		if decl.Complex != nil {
+			st = decl.Complex.Simple
+		}
+		if st != nil {
+			bl.Type, bl.Helper = valueType(set, st)
+			if len(st.Enum) > 0 && st.Name.Local != "" {
+				addEnum(enums, st)
+			}
+		}
+
+		if handWritten[bl.Name] {
+			continue
+		}
+		builders = append(builders, bl)
+	}
+
+	sort.Slice(builders, func(i, j int) bool {
+		return builders[i].GoName < builders[j].GoName
+	})
+
+	var enumList []*enum
+	for _, e := range enums {
+		if len(e.Values) > 0 {
+			enumList = append(enumList, e)
+		}
+	}
+	sort.Slice(enumList, func(i, j int) bool {
+		return enumList[i].GoName < enumList[j].GoName
+	})
+
+	buf := &bytes.Buffer{}
+	err = tpl.Execute(buf, struct {
+		Builders []builder
+		Enums    []*enum
+	}{builders, enumList})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// valueType returns Go type and helper function for elements with simple
+// type st.
+func valueType(set *xsd.Set, st *xsd.SimpleType) (string, string) {
+	base := set.BuiltinBase(st)
+	if base == nil || len(st.Enum) > 0 {
+		return "string", "StringElement"
+	}
+	switch base.Name.Local {
+	case "double", "float", "decimal":
+		return "float64", "FloatElement"
+	case "int", "integer", "long", "short", "byte",
+		"nonNegativeInteger", "positiveInteger",
+		"unsignedInt", "unsignedShort", "unsignedByte":
+		return "int", "IntElement"
+	case "boolean":
+		return "bool", "BoolElement"
+	}
+	return "string", "StringElement"
+}
+
+// addEnum adds enumeration constants for simple type st unless they were
+// already added or are hand written.
+func addEnum(enums map[xml.Name]*enum, st *xsd.SimpleType) {
+	if _, ok := enums[st.Name]; ok {
+		return
+	}
+
+	name := strings.TrimSuffix(st.Name.Local, "EnumType")
+	if st.Name.Space == nsGx {
+		name = "gx:" + name
+	}
+
+	e := &enum{GoName: goName(name)}
+	for _, v := range st.Enum {
+		ev := enumValue{GoName: e.GoName + goName(v), Value: v}
+		if !handWrittenEnums[ev.GoName] {
+			e.Values = append(e.Values, ev)
+		}
+	}
+	enums[st.Name] = e
+}
+
+// elemGoName returns Go name for element.
+func elemGoName(qn xml.Name) string {
+	if qn.Space == nsKML {
+		if n, ok := overrides[qn.Local]; ok {
+			return n
+		}
+	}
+	return goName(prefixes[qn.Space] + qn.Local)
+}
+
+// goName returns exported Go name for XML name with optional namespace
+// prefix. The prefix becomes part of the name (gx:Track -> GxTrack).
+func goName(name string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range name {
+		if r == ':' || r == '_' || r == '-' || r == '.' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	return initialism(sb.String())
+}
+
+// initialism replaces words listed in initialisms with their upper case
+// versions. Words start with an upper case letter and end before the next
+// upper case letter.
+func initialism(name string) string {
+	var sb strings.Builder
+	for i := 0; i < len(name); {
+		j := i + 1
+		for j < len(name) && !unicode.IsUpper(rune(name[j])) {
+			j++
+		}
+		word := name[i:j]
+		if w, ok := initialisms[word]; ok {
+			word = w
+		}
+		sb.WriteString(word)
+		i = j
+	}
+	return sb.String()
+}
+
+var tpl = template.Must(template.New("gen").Parse(`// Code generated by genbuilders from bundled XSD schemas. DO NOT EDIT.
+
+package kml
+
+// KML element names.
+const (
+{{- range .Builders}}
+	Elem{{.GoName}} = {{printf "%q" .Name}}
+{{- end}}
+)
+{{range .Enums}}
+// {{.GoName}} valid values.
+const (
+{{- range .Values}}
+	{{.GoName}} = {{printf "%q" .Value}}
+{{- end}}
+)
+{{end}}
+{{- range .Builders}}
+// {{.GoName}} returns new {{.Name}} element.
+{{- if .Type}}
+func {{.GoName}}(value {{.Type}}, xes ...interface{}) *Element {
+	return {{.Helper}}(Elem{{.GoName}}, value, xes...)
+}
+{{- else}}
+func {{.GoName}}(xes ...interface{}) *Element {
+	return NewElement(Elem{{.GoName}}, xes...)
+}
+{{- end}}
+{{end -}}
+`))
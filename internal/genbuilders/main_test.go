@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_generate_UpToDate(t *testing.T) {
+	// --- Given ---
+	exp, err := os.ReadFile("../../elements_gen.go")
+	require.NoError(t, err)
+
+	// --- When ---
+	got, err := generate(os.DirFS("../../xsd"))
+
+	// --- Then ---
+	require.NoError(t, err)
+	assert.Exactly(t, string(exp), string(got), "run go generate")
+}
+
+func Test_generate_SkipsAbstract(t *testing.T) {
+	// --- When ---
+	got, err := generate(os.DirFS("../../xsd"))
+
+	// --- Then ---
+	require.NoError(t, err)
+	assert.NotContains(t, string(got), "AbstractTourPrimitive")
+	assert.NotContains(t, string(got), "AbstractObjectGroup")
+	assert.Contains(t, string(got), "func GxTour(")
+}
+
+func Test_goName(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		name string
+		exp  string
+	}{
+		{"simple", "altitude", "Altitude"},
+		{"camel case", "maxSnippetLines", "MaxSnippetLines"},
+		{"prefix", "gx:Track", "GxTrack"},
+		{"initialism", "styleUrl", "StyleURL"},
+		{"initialism whole", "Url", "URL"},
+		{"initialism middle", "httpQuery", "HTTPQuery"},
+		{"not initialism", "Urls", "Urls"},
+		{"enum value", "relativeToSeaFloor", "RelativeToSeaFloor"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			got := goName(tc.name)
+
+			// --- Then ---
+			assert.Exactly(t, tc.exp, got)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package xsd
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// ContentError describes content model violation.
+type ContentError struct {
+	// Index of the offending child. Equal to number of children for
+	// violations not related to any particular child (missing elements).
+	Index int
+
+	// Violation description.
+	Msg string
+}
+
+// slot represents position in the flattened content model. Element can
+// appear in the slot if it matches any of the slot particles.
+type slot struct {
+	particles []*Particle
+	min       int
+	max       int // Unbounded for no limit.
+}
+
+// Match matches names of child elements against content model of complex
+// type ct. It returns element declaration for each child (nil for children
+// matched by wildcards without known declaration or not matched at all)
+// and the list of violations.
+//
+// The content model is validated as a list of ordered slots created by
+// flattening nested sequences. Choices occupy single slot and their
+// occurrence limits are combined.
+func (set *Set) Match(ct *ComplexType, names []xml.Name) ([]*Element, []ContentError) {
+	decls := make([]*Element, len(names))
+	var errs []ContentError
+
+	var slots []slot
+	if ct.Content != nil {
+		slots = flatten(ct.Content, 1, 1, nil)
+	}
+
+	cnt := make([]int, len(slots))
+	last := make([]xml.Name, len(slots)) // Last child name matched in slot.
+	cur := 0
+	for i, name := range names {
+		s, el := set.findSlot(slots, cur, cnt, name)
+		if s < 0 {
+			if prev, _ := set.findSlot(slots, 0, nil, name); prev >= 0 && prev < cur {
+				msg := fmt.Sprintf("element %s must appear before element %s", name.Local, last[cur].Local)
+				errs = append(errs, ContentError{Index: i, Msg: msg})
+			} else if prev >= 0 {
+				msg := fmt.Sprintf("too many %s elements", name.Local)
+				errs = append(errs, ContentError{Index: i, Msg: msg})
+			} else {
+				msg := fmt.Sprintf("element %s is not allowed", name.Local)
+				errs = append(errs, ContentError{Index: i, Msg: msg})
+			}
+			decls[i] = set.Elements[name]
+			continue
+		}
+		cur = s
+		cnt[s]++
+		last[s] = name
+		decls[i] = el
+	}
+
+	for i, sl := range slots {
+		if cnt[i] >= sl.min {
+			continue
+		}
+		var msg string
+		if len(sl.particles) == 1 && sl.particles[0].Kind == ElementParticle {
+			msg = fmt.Sprintf("missing required element %s", sl.particles[0].Ref.Local)
+		} else {
+			msg = "missing required element"
+		}
+		errs = append(errs, ContentError{Index: len(names), Msg: msg})
+	}
+
+	return decls, errs
+}
+
+// Slot returns index of the first position in the flattened content model
+// of complex type ct which can accept element with name. Children ordered
+// by their slots appear in the order required by the content model.
+// Returns -1 if element is not allowed by the content model.
+func (set *Set) Slot(ct *ComplexType, name xml.Name) int {
+	if ct.Content == nil {
+		return -1
+	}
+	s, _ := set.findSlot(flatten(ct.Content, 1, 1, nil), 0, nil, name)
+	return s
+}
+
+// findSlot returns index of the first slot starting at from which can
+// accept element with name and the element declaration. Slots which
+// reached their maximum number of elements are skipped when cnt is not
+// nil. Returns -1 if no slot can accept the element.
+func (set *Set) findSlot(slots []slot, from int, cnt []int, name xml.Name) (int, *Element) {
+	for i := from; i < len(slots); i++ {
+		sl := slots[i]
+		if cnt != nil && sl.max != Unbounded && cnt[i] >= sl.max {
+			continue
+		}
+		for _, p := range sl.particles {
+			if ok, el := set.matchParticle(p, name); ok {
+				return i, el
+			}
+		}
+	}
+	return -1, nil
+}
+
+// matchParticle returns true if element with name matches element or
+// wildcard particle and returns its declaration if known.
+func (set *Set) matchParticle(p *Particle, name xml.Name) (bool, *Element) {
+	switch p.Kind {
+	case ElementParticle:
+		if p.Elem == nil {
+			// Element from namespace which was not loaded.
+			return p.Ref == name, nil
+		}
+		if p.Ref == name && !p.Elem.Abstract {
+			return true, p.Elem
+		}
+		el := set.Elements[name]
+		if el != nil && !el.Abstract && set.substitutes(el, p.Ref) {
+			return true, el
+		}
+		return false, nil
+
+	case AnyParticle:
+		switch p.AnyNS {
+		case "##any":
+		case "##other":
+			if name.Space == p.TargetNS {
+				return false, nil
+			}
+		default:
+			if name.Space != p.AnyNS {
+				return false, nil
+			}
+		}
+		return true, set.Elements[name]
+	}
+	return false, nil
+}
+
+// flatten flattens particle to list of slots. The min and max are
+// occurrence limits of the enclosing particles.
+func flatten(p *Particle, min, max int, slots []slot) []slot {
+	min, max = mulOccurs(min, max, p.Min, p.Max)
+	switch p.Kind {
+	case ElementParticle, AnyParticle:
+		return append(slots, slot{particles: []*Particle{p}, min: min, max: max})
+
+	case SequenceParticle:
+		for _, item := range p.Items {
+			slots = flatten(item, min, max, slots)
+		}
+		return slots
+
+	case ChoiceParticle:
+		sl := slot{min: min, max: max}
+		imin, imax := -1, 0
+		for _, item := range p.Items {
+			// Nested groups in choices are flattened to their particles.
+			for _, s := range flatten(item, 1, 1, nil) {
+				sl.particles = append(sl.particles, s.particles...)
+				if imin < 0 || s.min < imin {
+					imin = s.min
+				}
+				if imax != Unbounded && (s.max == Unbounded || s.max > imax) {
+					imax = s.max
+				}
+			}
+		}
+		if imin < 0 {
+			imin = 0
+		}
+		sl.min, sl.max = mulOccurs(min, max, imin, imax)
+		return append(slots, sl)
+	}
+	return slots
+}
+
+// mulOccurs multiplies occurrence limits.
+func mulOccurs(min0, max0, min1, max1 int) (int, int) {
+	if max0 == Unbounded || max1 == Unbounded {
+		return min0 * min1, Unbounded
+	}
+	return min0 * min1, max0 * max1
+}
@@ -0,0 +1,331 @@
+package xsd
+
+import (
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// SimpleType represents simple type definition.
+type SimpleType struct {
+	Name     xml.Name
+	Base     xml.Name // Restriction base. Equal to Name for built-in types.
+	Enum     []string
+	Min      *float64 // Inclusive minimum.
+	Max      *float64 // Inclusive maximum.
+	Length   int      // Required length, -1 when not restricted.
+	Patterns []string
+	ItemType xml.Name   // Item type of list types.
+	Members  []xml.Name // Member types of union types.
+
+	patterns []*regexp.Regexp
+}
+
+// IsBuiltin returns true for built-in XML Schema types.
+func (st *SimpleType) IsBuiltin() bool {
+	return st.Name.Space == NS && st.Base == st.Name
+}
+
+// IsList returns true for list types.
+func (st *SimpleType) IsList() bool {
+	return st.ItemType.Local != ""
+}
+
+// IsUnion returns true for union types.
+func (st *SimpleType) IsUnion() bool {
+	return len(st.Members) > 0
+}
+
+// builtins is the list of supported built-in types.
+var builtins = map[string]bool{
+	"anySimpleType":    true,
+	"anyURI":           true,
+	"boolean":          true,
+	"date":             true,
+	"dateTime":         true,
+	"decimal":          true,
+	"double":           true,
+	"float":            true,
+	"gYear":            true,
+	"gYearMonth":       true,
+	"hexBinary":        true,
+	"ID":               true,
+	"int":              true,
+	"integer":          true,
+	"NCName":           true,
+	"normalizedString": true,
+	"short":            true,
+	"string":           true,
+	"token":            true,
+	"unsignedInt":      true,
+	"unsignedShort":    true,
+}
+
+// builtin returns built-in type with local name.
+func builtin(name string) *SimpleType {
+	n := xml.Name{Space: NS, Local: name}
+	return &SimpleType{Name: n, Base: n, Length: -1}
+}
+
+// builtinOrNamed returns built-in or named simple type. Returns nil if
+// type does not exist.
+func (set *Set) builtinOrNamed(name xml.Name) *SimpleType {
+	if name.Space == NS {
+		if builtins[name.Local] {
+			return builtin(name.Local)
+		}
+		return nil
+	}
+	return set.SimpleTypes[name]
+}
+
+// simpleType reads simple type definition.
+func (s *schema) simpleType(n *node) *SimpleType {
+	st := &SimpleType{Length: -1}
+	if v := n.attr("name"); v != "" {
+		st.Name = xml.Name{Space: s.target, Local: v}
+	}
+	for _, ch := range n.Nodes {
+		switch ch.XMLName.Local {
+		case "restriction":
+			st.Base = s.typeName(ch.attr("base"))
+			for _, f := range ch.Nodes {
+				v := f.attr("value")
+				switch f.XMLName.Local {
+				case "enumeration":
+					st.Enum = append(st.Enum, v)
+				case "minInclusive":
+					if x, err := strconv.ParseFloat(v, 64); err == nil {
+						st.Min = &x
+					}
+				case "maxInclusive":
+					if x, err := strconv.ParseFloat(v, 64); err == nil {
+						st.Max = &x
+					}
+				case "length":
+					if x, err := strconv.Atoi(v); err == nil {
+						st.Length = x
+					}
+				case "pattern":
+					st.Patterns = append(st.Patterns, v)
+					// XML Schema patterns are implicitly anchored.
+					if re, err := regexp.Compile("^(?:" + v + ")$"); err == nil {
+						st.patterns = append(st.patterns, re)
+					}
+				}
+			}
+		case "list":
+			st.ItemType = s.typeName(ch.attr("itemType"))
+		case "union":
+			for _, m := range strings.Fields(ch.attr("memberTypes")) {
+				st.Members = append(st.Members, s.typeName(m))
+			}
+		}
+	}
+	return st
+}
+
+// BuiltinBase returns built-in type the simple type is derived from.
+// For list and union types it returns nil.
+func (set *Set) BuiltinBase(st *SimpleType) *SimpleType {
+	for i := 0; st != nil && i < 32; i++ {
+		if st.IsList() || st.IsUnion() {
+			return nil
+		}
+		if st.IsBuiltin() {
+			return st
+		}
+		st = set.builtinOrNamed(st.Base)
+	}
+	return nil
+}
+
+// ValidateValue validates value against simple type.
+func (set *Set) ValidateValue(st *SimpleType, value string) error {
+	return set.validateValue(st, value, 0)
+}
+
+// validateValue validates value against simple type. The depth is used to
+// protect against circular type definitions.
+func (set *Set) validateValue(st *SimpleType, value string, depth int) error {
+	if depth > 32 {
+		return errors.New("type derivation too deep")
+	}
+
+	switch {
+	case st.IsBuiltin():
+		return validateBuiltin(st.Name.Local, value)
+
+	case st.IsList():
+		it := set.builtinOrNamed(st.ItemType)
+		if it == nil {
+			return nil
+		}
+		items := strings.Fields(value)
+		for _, item := range items {
+			if err := set.validateValue(it, item, depth+1); err != nil {
+				return err
+			}
+		}
+		if st.Length >= 0 && len(items) != st.Length {
+			return fmt.Errorf("expected %d items", st.Length)
+		}
+		return nil
+
+	case st.IsUnion():
+		for _, m := range st.Members {
+			mt := set.builtinOrNamed(m)
+			if mt != nil && set.validateValue(mt, value, depth+1) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid value %q", value)
+	}
+
+	base := set.builtinOrNamed(st.Base)
+	if base != nil {
+		if err := set.validateValue(base, value, depth+1); err != nil {
+			return err
+		}
+	}
+	return set.validateFacets(st, value)
+}
+
+// validateFacets validates value against restriction facets.
+func (set *Set) validateFacets(st *SimpleType, value string) error {
+	if len(st.Enum) > 0 {
+		v := strings.TrimSpace(value)
+		found := false
+		for _, e := range st.Enum {
+			if e == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("value %q is not one of %s", v, strings.Join(st.Enum, ", "))
+		}
+	}
+
+	if st.Min != nil || st.Max != nil {
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		if st.Min != nil && v < *st.Min {
+			return fmt.Errorf("value %s is less than %s", formatFloat(v), formatFloat(*st.Min))
+		}
+		if st.Max != nil && v > *st.Max {
+			return fmt.Errorf("value %s is greater than %s", formatFloat(v), formatFloat(*st.Max))
+		}
+	}
+
+	if st.Length >= 0 {
+		l := utf8.RuneCountInString(value)
+		if base := set.BuiltinBase(st); base != nil && base.Name.Local == "hexBinary" {
+			l = len(strings.TrimSpace(value)) / 2
+		}
+		if l != st.Length {
+			return fmt.Errorf("value %q has invalid length", value)
+		}
+	}
+
+	for i, re := range st.patterns {
+		if !re.MatchString(value) {
+			return fmt.Errorf("value %q does not match pattern %s", value, st.Patterns[i])
+		}
+	}
+	return nil
+}
+
+// formatFloat formats float for error messages.
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	reNCName     = regexp.MustCompile(`^[\pL_][\pL\pN._\-]*$`)
+	reDateTime   = regexp.MustCompile(`^(-?\d{4,}-\d{2}-\d{2})T(\d{2}):(\d{2}):(\d{2})(\.\d+)?(Z|[+-]\d{2}:\d{2})?$`)
+	reDate       = regexp.MustCompile(`^(-?\d{4,}-\d{2}-\d{2})(Z|[+-]\d{2}:\d{2})?$`)
+	reGYearMonth = regexp.MustCompile(`^-?\d{4,}-(\d{2})(Z|[+-]\d{2}:\d{2})?$`)
+	reGYear      = regexp.MustCompile(`^-?\d{4,}(Z|[+-]\d{2}:\d{2})?$`)
+)
+
+// validateBuiltin validates value against built-in type.
+func validateBuiltin(typ, value string) error {
+	if typ == "string" || typ == "anySimpleType" || typ == "anyURI" || typ == "normalizedString" {
+		return nil
+	}
+
+	v := strings.TrimSpace(value)
+	var ok bool
+	switch typ {
+	case "token":
+		ok = true
+	case "boolean":
+		ok = v == "true" || v == "false" || v == "1" || v == "0"
+	case "double", "float", "decimal":
+		_, err := strconv.ParseFloat(v, 64)
+		ok = err == nil
+	case "int":
+		_, err := strconv.ParseInt(v, 10, 32)
+		ok = err == nil
+	case "short":
+		_, err := strconv.ParseInt(v, 10, 16)
+		ok = err == nil
+	case "integer":
+		_, err := strconv.ParseInt(v, 10, 64)
+		ok = err == nil
+	case "unsignedInt":
+		_, err := strconv.ParseUint(v, 10, 32)
+		ok = err == nil
+	case "unsignedShort":
+		_, err := strconv.ParseUint(v, 10, 16)
+		ok = err == nil
+	case "ID", "NCName":
+		ok = reNCName.MatchString(v)
+	case "hexBinary":
+		_, err := hex.DecodeString(v)
+		ok = err == nil
+	case "dateTime":
+		m := reDateTime.FindStringSubmatch(v)
+		ok = m != nil && validDate(m[1]) && validTime(m[2], m[3], m[4])
+	case "date":
+		m := reDate.FindStringSubmatch(v)
+		ok = m != nil && validDate(m[1])
+	case "gYearMonth":
+		m := reGYearMonth.FindStringSubmatch(v)
+		ok = m != nil && m[1] >= "01" && m[1] <= "12"
+	case "gYear":
+		ok = reGYear.MatchString(v)
+	default:
+		ok = true
+	}
+
+	if !ok {
+		return fmt.Errorf("invalid %s value %q", typ, value)
+	}
+	return nil
+}
+
+// validDate returns true if YYYY-MM-DD date is valid.
+func validDate(d string) bool {
+	d = strings.TrimPrefix(d, "-")
+	if len(d) != 10 {
+		// Years with more than four digits.
+		d = "2000" + d[len(d)-6:]
+	}
+	_, err := time.Parse("2006-01-02", d)
+	return err == nil
+}
+
+// validTime returns true if hour, minute and second are in range.
+func validTime(h, m, s string) bool {
+	return h <= "24" && m <= "59" && s <= "60"
+}
@@ -0,0 +1,558 @@
+// Package xsd implements loader for the subset of XML Schema used by the
+// bundled KML schemas and validation of values and content models against
+// the loaded schemas.
+package xsd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// NS is the XML Schema namespace.
+const NS = "http://www.w3.org/2001/XMLSchema"
+
+// Unbounded represents unbounded maxOccurs.
+const Unbounded = -1
+
+// Set represents set of loaded schemas.
+type Set struct {
+	// Global element declarations.
+	Elements map[xml.Name]*Element
+
+	// Named simple types.
+	SimpleTypes map[xml.Name]*SimpleType
+
+	// Named complex types.
+	ComplexTypes map[xml.Name]*ComplexType
+
+	// Substitution group members by the group head name.
+	members map[xml.Name][]*Element
+
+	// Global attribute groups.
+	attrGroups map[xml.Name][]*Attribute
+
+	// Namespaces of loaded schemas.
+	namespaces map[string]bool
+}
+
+// Element represents element declaration.
+type Element struct {
+	Name       xml.Name
+	TypeName   xml.Name    // Zero for anonymous types.
+	Simple     *SimpleType // Resolved simple type.
+	Complex    *ComplexType
+	Abstract   bool
+	SubstGroup xml.Name
+	Default    string
+}
+
+// IsSimple returns true if element has simple type.
+func (e *Element) IsSimple() bool {
+	return e.Simple != nil
+}
+
+// ComplexType represents complex type definition. Content and Attrs
+// include the content and attributes inherited from the base types.
+type ComplexType struct {
+	Name     xml.Name
+	Abstract bool
+	Base     xml.Name    // Extension base type name.
+	Simple   *SimpleType // Content type for types with simple content.
+	Content  *Particle   // Nil for types without element content.
+	Attrs    []*Attribute
+
+	own      *Particle // Content defined by the type itself.
+	resolved bool
+}
+
+// Attribute represents attribute declaration.
+type Attribute struct {
+	Name     string
+	Type     *SimpleType
+	Required bool
+	Default  string
+}
+
+// ParticleKind represents kind of content model particle.
+type ParticleKind int
+
+// Particle kinds.
+const (
+	ElementParticle ParticleKind = iota
+	SequenceParticle
+	ChoiceParticle
+	AnyParticle
+)
+
+// Particle represents content model particle.
+type Particle struct {
+	Kind  ParticleKind
+	Min   int
+	Max   int         // Unbounded for no limit.
+	Ref   xml.Name    // Element name for element particles.
+	Elem  *Element    // Resolved element declaration. Nil if unknown.
+	Items []*Particle // Sequence and choice items.
+
+	// Namespace constraint of wildcard particles (##any or ##other)
+	// and target namespace of the schema the wildcard is defined in.
+	AnyNS    string
+	TargetNS string
+}
+
+// node represents generic XML Schema node.
+type node struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodes   []*node    `xml:",any"`
+}
+
+// attr returns attribute value by local name.
+func (n *node) attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Space == "" && a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// schema holds state of a single schema document being loaded.
+type schema struct {
+	set    *Set
+	target string
+	prefix map[string]string // Namespace prefixes.
+}
+
+// Load loads schema files from fsys. References between the schemas are
+// resolved by namespace, schemaLocation of imports is ignored. References
+// to components from namespaces which were not loaded are left unresolved.
+func Load(fsys fs.FS, files ...string) (*Set, error) {
+	set := &Set{
+		Elements:     make(map[xml.Name]*Element),
+		SimpleTypes:  make(map[xml.Name]*SimpleType),
+		ComplexTypes: make(map[xml.Name]*ComplexType),
+		members:      make(map[xml.Name][]*Element),
+		attrGroups:   make(map[xml.Name][]*Attribute),
+		namespaces:   make(map[string]bool),
+	}
+
+	var schemas []*schema
+	var roots []*node
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		root := &node{}
+		if err := xml.Unmarshal(data, root); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if root.XMLName != (xml.Name{Space: NS, Local: "schema"}) {
+			return nil, fmt.Errorf("%s: not XML Schema", file)
+		}
+		sch := &schema{
+			set:    set,
+			target: root.attr("targetNamespace"),
+			prefix: map[string]string{"": NS},
+		}
+		for _, a := range root.Attrs {
+			switch {
+			case a.Name.Space == "xmlns":
+				sch.prefix[a.Name.Local] = a.Value
+			case a.Name.Space == "" && a.Name.Local == "xmlns":
+				sch.prefix[""] = a.Value
+			}
+		}
+		set.namespaces[sch.target] = true
+		schemas = append(schemas, sch)
+		roots = append(roots, root)
+	}
+
+	// Simple types first so they can be resolved while reading the rest.
+	for i, sch := range schemas {
+		for _, n := range roots[i].Nodes {
+			if n.XMLName.Local == "simpleType" {
+				st := sch.simpleType(n)
+				set.SimpleTypes[st.Name] = st
+			}
+		}
+	}
+
+	for i, sch := range schemas {
+		for _, n := range roots[i].Nodes {
+			switch n.XMLName.Local {
+			case "attributeGroup":
+				name := sch.qname(n.attr("name"), sch.target)
+				set.attrGroups[name] = sch.attributes(n)
+			}
+		}
+	}
+
+	for i, sch := range schemas {
+		for _, n := range roots[i].Nodes {
+			var err error
+			switch n.XMLName.Local {
+			case "element":
+				var el *Element
+				el, err = sch.element(n)
+				if err == nil {
+					set.Elements[el.Name] = el
+				}
+			case "complexType":
+				var ct *ComplexType
+				ct, err = sch.complexType(n)
+				if err == nil {
+					set.ComplexTypes[ct.Name] = ct
+				}
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", files[i], err)
+			}
+		}
+	}
+
+	if err := set.resolve(); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// qname resolves prefixed name to qualified name. Unprefixed names are
+// resolved to def namespace.
+func (s *schema) qname(v, def string) xml.Name {
+	if i := strings.IndexByte(v, ':'); i >= 0 {
+		return xml.Name{Space: s.prefix[v[:i]], Local: v[i+1:]}
+	}
+	return xml.Name{Space: def, Local: v}
+}
+
+// typeName resolves type reference.
+func (s *schema) typeName(v string) xml.Name {
+	return s.qname(v, s.prefix[""])
+}
+
+// element reads global or local element declaration.
+func (s *schema) element(n *node) (*Element, error) {
+	el := &Element{
+		Name:     xml.Name{Space: s.target, Local: n.attr("name")},
+		Abstract: n.attr("abstract") == "true",
+		Default:  n.attr("default"),
+	}
+	if v := n.attr("substitutionGroup"); v != "" {
+		el.SubstGroup = s.typeName(v)
+	}
+	if v := n.attr("type"); v != "" {
+		el.TypeName = s.typeName(v)
+	}
+	for _, ch := range n.Nodes {
+		var err error
+		switch ch.XMLName.Local {
+		case "complexType":
+			el.Complex, err = s.complexType(ch)
+		case "simpleType":
+			el.Simple = s.simpleType(ch)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return el, nil
+}
+
+// complexType reads complex type definition.
+func (s *schema) complexType(n *node) (*ComplexType, error) {
+	ct := &ComplexType{
+		Abstract: n.attr("abstract") == "true",
+	}
+	if v := n.attr("name"); v != "" {
+		ct.Name = xml.Name{Space: s.target, Local: v}
+	}
+
+	for _, ch := range n.Nodes {
+		switch ch.XMLName.Local {
+		case "sequence", "choice":
+			p, err := s.particle(ch)
+			if err != nil {
+				return nil, err
+			}
+			ct.own = p
+		case "attribute", "attributeGroup":
+			ct.Attrs = append(ct.Attrs, s.attributes(&node{Nodes: []*node{ch}})...)
+		case "complexContent", "simpleContent":
+			for _, ext := range ch.Nodes {
+				if ext.XMLName.Local != "extension" && ext.XMLName.Local != "restriction" {
+					continue
+				}
+				base := s.typeName(ext.attr("base"))
+				if ch.XMLName.Local == "simpleContent" {
+					ct.Simple = s.set.builtinOrNamed(base)
+				} else {
+					ct.Base = base
+				}
+				for _, ech := range ext.Nodes {
+					switch ech.XMLName.Local {
+					case "sequence", "choice":
+						p, err := s.particle(ech)
+						if err != nil {
+							return nil, err
+						}
+						ct.own = p
+					}
+				}
+				ct.Attrs = append(ct.Attrs, s.attributes(ext)...)
+			}
+		}
+	}
+	return ct, nil
+}
+
+// attributes reads attribute declarations and attribute group references
+// which are direct children of n.
+func (s *schema) attributes(n *node) []*Attribute {
+	var attrs []*Attribute
+	for _, ch := range n.Nodes {
+		switch ch.XMLName.Local {
+		case "attribute":
+			at := &Attribute{
+				Name:     ch.attr("name"),
+				Required: ch.attr("use") == "required",
+				Default:  ch.attr("default"),
+			}
+			if v := ch.attr("type"); v != "" {
+				at.Type = s.set.builtinOrNamed(s.typeName(v))
+			} else {
+				at.Type = builtin("anySimpleType")
+			}
+			attrs = append(attrs, at)
+		case "attributeGroup":
+			if v := ch.attr("ref"); v != "" {
+				attrs = append(attrs, s.set.attrGroups[s.typeName(v)]...)
+			}
+		}
+	}
+	return attrs
+}
+
+// particle reads sequence, choice, any or element particle.
+func (s *schema) particle(n *node) (*Particle, error) {
+	p := &Particle{Min: 1, Max: 1}
+	if v := n.attr("minOccurs"); v != "" {
+		min, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid minOccurs %q", v)
+		}
+		p.Min = min
+	}
+	if v := n.attr("maxOccurs"); v != "" {
+		if v == "unbounded" {
+			p.Max = Unbounded
+		} else {
+			max, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid maxOccurs %q", v)
+			}
+			p.Max = max
+		}
+	}
+
+	switch n.XMLName.Local {
+	case "element":
+		p.Kind = ElementParticle
+		if v := n.attr("ref"); v != "" {
+			p.Ref = s.typeName(v)
+			return p, nil
+		}
+		el, err := s.element(n)
+		if err != nil {
+			return nil, err
+		}
+		p.Ref = el.Name
+		p.Elem = el
+		return p, nil
+
+	case "any":
+		p.Kind = AnyParticle
+		p.AnyNS = n.attr("namespace")
+		if p.AnyNS == "" {
+			p.AnyNS = "##any"
+		}
+		p.TargetNS = s.target
+		return p, nil
+
+	case "sequence":
+		p.Kind = SequenceParticle
+	case "choice":
+		p.Kind = ChoiceParticle
+	default:
+		return nil, fmt.Errorf("unsupported particle %s", n.XMLName.Local)
+	}
+
+	for _, ch := range n.Nodes {
+		switch ch.XMLName.Local {
+		case "element", "sequence", "choice", "any":
+			item, err := s.particle(ch)
+			if err != nil {
+				return nil, err
+			}
+			p.Items = append(p.Items, item)
+		}
+	}
+	return p, nil
+}
+
+// resolve resolves references between loaded components.
+func (set *Set) resolve() error {
+	for _, el := range set.Elements {
+		if err := set.resolveElement(el); err != nil {
+			return err
+		}
+	}
+
+	for _, ct := range set.ComplexTypes {
+		if err := set.resolveComplex(ct, nil); err != nil {
+			return err
+		}
+	}
+
+	// Substitution groups.
+	for _, el := range set.Elements {
+		for head := el.SubstGroup; head.Local != ""; {
+			set.members[head] = append(set.members[head], el)
+			h := set.Elements[head]
+			if h == nil {
+				break
+			}
+			head = h.SubstGroup
+		}
+	}
+	return nil
+}
+
+// resolveElement resolves element type.
+func (set *Set) resolveElement(el *Element) error {
+	if el.Complex != nil {
+		return set.resolveComplex(el.Complex, nil)
+	}
+	if el.Simple != nil || el.TypeName.Local == "" {
+		return nil
+	}
+	if ct, ok := set.ComplexTypes[el.TypeName]; ok {
+		el.Complex = ct
+		return set.resolveComplex(ct, nil)
+	}
+	el.Simple = set.builtinOrNamed(el.TypeName)
+	if el.Simple == nil {
+		return fmt.Errorf("element %s: unknown type %s", el.Name.Local, el.TypeName.Local)
+	}
+	return nil
+}
+
+// resolveComplex resolves base types and element references of complex
+// type. The seen argument is used to detect circular derivations.
+func (set *Set) resolveComplex(ct *ComplexType, seen map[*ComplexType]bool) error {
+	if ct.resolved {
+		return nil
+	}
+	if seen == nil {
+		seen = make(map[*ComplexType]bool)
+	}
+	if seen[ct] {
+		return fmt.Errorf("circular type derivation %s", ct.Name.Local)
+	}
+	seen[ct] = true
+
+	ct.Content = ct.own
+	if ct.Base.Local != "" && ct.Base.Space != NS {
+		base, ok := set.ComplexTypes[ct.Base]
+		if !ok {
+			return fmt.Errorf("type %s: unknown base type %s", ct.Name.Local, ct.Base.Local)
+		}
+		if err := set.resolveComplex(base, seen); err != nil {
+			return err
+		}
+		ct.Attrs = append(append([]*Attribute{}, base.Attrs...), ct.Attrs...)
+		if ct.Simple == nil {
+			ct.Simple = base.Simple
+		}
+		switch {
+		case base.Content == nil:
+		case ct.own == nil:
+			ct.Content = base.Content
+		default:
+			ct.Content = &Particle{
+				Kind:  SequenceParticle,
+				Min:   1,
+				Max:   1,
+				Items: []*Particle{base.Content, ct.own},
+			}
+		}
+	}
+
+	if err := set.resolveParticle(ct.own); err != nil {
+		return err
+	}
+	ct.resolved = true
+	return nil
+}
+
+// resolveParticle resolves element references in particle.
+func (set *Set) resolveParticle(p *Particle) error {
+	if p == nil {
+		return nil
+	}
+	if p.Kind == ElementParticle {
+		if p.Elem == nil {
+			p.Elem = set.Elements[p.Ref]
+			if p.Elem == nil && set.namespaces[p.Ref.Space] {
+				return fmt.Errorf("unknown element %s", p.Ref.Local)
+			}
+			return nil
+		}
+		return set.resolveElement(p.Elem)
+	}
+	for _, item := range p.Items {
+		if err := set.resolveParticle(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Members returns elements which can substitute element name including
+// the element itself unless it's abstract.
+func (set *Set) Members(name xml.Name) []*Element {
+	var els []*Element
+	if el := set.Elements[name]; el != nil && !el.Abstract {
+		els = append(els, el)
+	}
+	for _, el := range set.members[name] {
+		if !el.Abstract {
+			els = append(els, el)
+		}
+	}
+	return els
+}
+
+// substitutes returns true if element el can be used in place of element
+// with name head.
+func (set *Set) substitutes(el *Element, head xml.Name) bool {
+	for name := el.Name; name.Local != ""; {
+		if name == head {
+			return true
+		}
+		h := set.Elements[name]
+		if h == nil {
+			return false
+		}
+		name = h.SubstGroup
+	}
+	return false
+}
+
+// HasNamespace returns true if schema for namespace ns was loaded.
+func (set *Set) HasNamespace(ns string) bool {
+	return set.namespaces[ns]
+}
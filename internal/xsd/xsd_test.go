@@ -0,0 +1,260 @@
+package xsd
+
+import (
+	"encoding/xml"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	nsKML = "http://www.opengis.net/kml/2.2"
+	nsGx  = "http://www.google.com/kml/ext/2.2"
+)
+
+// kmlName returns name in KML namespace.
+func kmlName(local string) xml.Name {
+	return xml.Name{Space: nsKML, Local: local}
+}
+
+// loadKML loads bundled KML schemas.
+func loadKML(t *testing.T) *Set {
+	t.Helper()
+	set, err := Load(os.DirFS("../../xsd"), "atom-author-link.xsd", "ogckml22.xsd", "kml22gx.xsd")
+	require.NoError(t, err)
+	return set
+}
+
+func Test_Load(t *testing.T) {
+	// --- When ---
+	set := loadKML(t)
+
+	// --- Then ---
+	pm := set.Elements[kmlName("Placemark")]
+	require.NotNil(t, pm)
+	assert.False(t, pm.IsSimple())
+	require.NotNil(t, pm.Complex)
+	assert.Exactly(t, kmlName("AbstractFeatureGroup"), pm.SubstGroup)
+
+	var attrs []string
+	for _, a := range pm.Complex.Attrs {
+		attrs = append(attrs, a.Name)
+	}
+	assert.Exactly(t, []string{"id", "targetId"}, attrs)
+
+	alt := set.Elements[kmlName("altitudeMode")]
+	require.NotNil(t, alt)
+	require.True(t, alt.IsSimple())
+	assert.Exactly(t, []string{"clampToGround", "relativeToGround", "absolute"}, alt.Simple.Enum)
+	assert.Exactly(t, "clampToGround", alt.Default)
+
+	trk := set.Elements[xml.Name{Space: nsGx, Local: "Track"}]
+	require.NotNil(t, trk)
+	assert.Exactly(t, kmlName("AbstractGeometryGroup"), trk.SubstGroup)
+
+	snp := set.Elements[kmlName("Snippet")]
+	require.NotNil(t, snp)
+	require.NotNil(t, snp.Complex)
+	require.NotNil(t, snp.Complex.Simple)
+	assert.Exactly(t, "string", snp.Complex.Simple.Name.Local)
+}
+
+func Test_Set_Members(t *testing.T) {
+	// --- Given ---
+	set := loadKML(t)
+
+	// --- When ---
+	got := set.Members(kmlName("AbstractContainerGroup"))
+
+	// --- Then ---
+	var names []string
+	for _, el := range got {
+		names = append(names, el.Name.Local)
+	}
+	assert.ElementsMatch(t, []string{"Document", "Folder"}, names)
+}
+
+func Test_Set_ValidateValue(t *testing.T) {
+	tt := []struct {
+		typ   string
+		value string
+		exp   string
+	}{
+		{"altitudeModeEnumType", "absolute", ""},
+		{"altitudeModeEnumType", "above", `value "above" is not one of clampToGround, relativeToGround, absolute`},
+		{"colorType", "ff00ff00", ""},
+		{"colorType", "ff00ff0", `invalid hexBinary value "ff00ff0"`},
+		{"colorType", "ff00ff0000", `value "ff00ff0000" has invalid length`},
+		{"angle90Type", "-90", ""},
+		{"angle90Type", "90.1", "value 90.1 is greater than 90"},
+		{"angle180Type", "-180.5", "value -180.5 is less than -180"},
+		{"angle180Type", "abc", `invalid double value "abc"`},
+		{"dateTimeType", "2006", ""},
+		{"dateTimeType", "2006-05", ""},
+		{"dateTimeType", "2006-05-04", ""},
+		{"dateTimeType", "2006-05-04T03:02:01Z", ""},
+		{"dateTimeType", "2006-05-04T03:02:01.123+02:00", ""},
+		{"dateTimeType", "2006-13", `invalid value "2006-13"`},
+		{"dateTimeType", "2006-02-30", `invalid value "2006-02-30"`},
+		{"itemIconStateType", "open error", ""},
+		{"itemIconStateType", "open shut", `value "shut" is not one of open, closed, error, fetching0, fetching1, fetching2`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.typ+"/"+tc.value, func(t *testing.T) {
+			// --- Given ---
+			set := loadKML(t)
+			st := set.SimpleTypes[kmlName(tc.typ)]
+			require.NotNil(t, st)
+
+			// --- When ---
+			err := set.ValidateValue(st, tc.value)
+
+			// --- Then ---
+			if tc.exp == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.exp)
+			}
+		})
+	}
+}
+
+func Test_ValidateBuiltin(t *testing.T) {
+	tt := []struct {
+		typ   string
+		value string
+		valid bool
+	}{
+		{"boolean", "1", true},
+		{"boolean", "false", true},
+		{"boolean", "yes", false},
+		{"int", "-12", true},
+		{"int", "1.5", false},
+		{"int", "4294967296", false},
+		{"ID", "sty_0", true},
+		{"ID", "0sty", false},
+		{"ID", "a:b", false},
+		{"double", "1e3", true},
+		{"string", "", true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.typ+"/"+tc.value, func(t *testing.T) {
+			// --- When ---
+			err := validateBuiltin(tc.typ, tc.value)
+
+			// --- Then ---
+			assert.Exactly(t, tc.valid, err == nil)
+		})
+	}
+}
+
+func Test_Set_Match(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		names []string
+		exp   []ContentError
+	}{
+		{"empty", nil, nil},
+		{"ordered", []string{"name", "description", "styleUrl", "Style", "Style", "Point"}, nil},
+		{"choice", []string{"name", "Snippet", "description"}, nil},
+		{
+			"out of order",
+			[]string{"name", "styleUrl", "description"},
+			[]ContentError{{2, "element description must appear before element styleUrl"}},
+		},
+		{
+			"choice exclusive",
+			[]string{"Snippet", "snippet"},
+			[]ContentError{{1, "too many snippet elements"}},
+		},
+		{
+			"too many",
+			[]string{"name", "name"},
+			[]ContentError{{1, "too many name elements"}},
+		},
+		{
+			"not allowed",
+			[]string{"name", "Folder", "Point"},
+			[]ContentError{{1, "element Folder is not allowed"}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			set := loadKML(t)
+			ct := set.Elements[kmlName("Placemark")].Complex
+			var names []xml.Name
+			for _, n := range tc.names {
+				names = append(names, kmlName(n))
+			}
+
+			// --- When ---
+			decls, errs := set.Match(ct, names)
+
+			// --- Then ---
+			assert.Exactly(t, tc.exp, errs)
+			require.Len(t, decls, len(names))
+			for i, d := range decls {
+				require.NotNil(t, d)
+				assert.Exactly(t, names[i], d.Name)
+			}
+		})
+	}
+}
+
+func Test_Set_Match_Required(t *testing.T) {
+	// --- Given ---
+	set := loadKML(t)
+	ct := set.Elements[kmlName("Data")].Complex
+
+	// --- When ---
+	_, errs := set.Match(ct, []xml.Name{kmlName("displayName")})
+
+	// --- Then ---
+	assert.Exactly(t, []ContentError{{1, "missing required element value"}}, errs)
+}
+
+func Test_Set_Match_Wildcard(t *testing.T) {
+	// --- Given ---
+	set := loadKML(t)
+	ct := set.Elements[kmlName("ExtendedData")].Complex
+	names := []xml.Name{
+		kmlName("Data"),
+		{Space: "urn:other", Local: "custom"},
+		kmlName("Placemark"),
+	}
+
+	// --- When ---
+	decls, errs := set.Match(ct, names)
+
+	// --- Then ---
+	assert.Exactly(t, []ContentError{{2, "element Placemark is not allowed"}}, errs)
+	assert.NotNil(t, decls[0])
+	assert.Nil(t, decls[1])
+}
+
+func Test_Set_Slot(t *testing.T) {
+	// --- Given ---
+	set := loadKML(t)
+	ct := set.Elements[kmlName("Placemark")].Complex
+
+	// --- When ---
+	name := set.Slot(ct, kmlName("name"))
+	snippet := set.Slot(ct, kmlName("Snippet"))
+	styleURL := set.Slot(ct, kmlName("styleUrl"))
+	point := set.Slot(ct, kmlName("Point"))
+	folder := set.Slot(ct, kmlName("Folder"))
+
+	// --- Then ---
+	assert.True(t, name < snippet)
+	assert.True(t, snippet < styleURL)
+	assert.True(t, styleURL < point)
+	assert.Exactly(t, snippet, set.Slot(ct, kmlName("snippet")))
+	assert.Exactly(t, -1, folder)
+}
@@ -0,0 +1,196 @@
+package kml
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ErrNoRootDocument is returned when KMZ archive has no KML document.
+var ErrNoRootDocument = errors.New("KMZ has no KML document")
+
+// kmzRootDoc is the conventional name of the KMZ root document.
+const kmzRootDoc = "doc.kml"
+
+// kmzFilesDir is the archive directory KMZ writer puts resources in.
+const kmzFilesDir = "files"
+
+// KMZ represents KMZ archive.
+type KMZ struct {
+	// Parsed root document.
+	Root *Element
+
+	// Archive path of the root document.
+	RootPath string
+
+	zr *zip.Reader
+}
+
+// ParseKMZ parses KMZ archive. The root document is doc.kml, or when it
+// does not exist, the first .kml file at the archive root level, or the
+// first .kml file in the archive.
+func ParseKMZ(r io.ReaderAt, size int64) (*KMZ, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	rootPath := kmzRootPath(zr)
+	if rootPath == "" {
+		return nil, ErrNoRootDocument
+	}
+
+	fil, err := zr.Open(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	defer fil.Close()
+
+	root, err := Parse(fil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KMZ{
+		Root:     root,
+		RootPath: rootPath,
+		zr:       zr,
+	}, nil
+}
+
+// kmzRootPath returns archive path of the root KML document or empty
+// string if archive has no KML documents.
+func kmzRootPath(zr *zip.Reader) string {
+	var first, firstTop string
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() || !strings.EqualFold(path.Ext(zf.Name), ".kml") {
+			continue
+		}
+		if zf.Name == kmzRootDoc {
+			return zf.Name
+		}
+		if first == "" {
+			first = zf.Name
+		}
+		if firstTop == "" && !strings.Contains(zf.Name, "/") {
+			firstTop = zf.Name
+		}
+	}
+	if firstTop != "" {
+		return firstTop
+	}
+	return first
+}
+
+// Open opens the named archive file. It implements fs.FS interface.
+func (k *KMZ) Open(name string) (fs.File, error) {
+	return k.zr.Open(name)
+}
+
+// OpenHref opens the archive file referenced by href relative to the root
+// document.
+func (k *KMZ) OpenHref(href string) (fs.File, error) {
+	if !isRelativeHref(href) {
+		return nil, &fs.PathError{Op: "open", Path: href, Err: fs.ErrNotExist}
+	}
+	return k.zr.Open(path.Join(path.Dir(k.RootPath), href))
+}
+
+// WriteKMZ writes KMZ archive to w. The root element is written as
+// doc.kml and every file from resources (may be nil) is stored in the
+// files directory. Relative href elements pointing to resources are
+// rewritten in the archived document to point to their new location.
+// The root element is not modified.
+func WriteKMZ(w io.Writer, root *Element, resources fs.FS) error {
+	var names []string
+	if resources != nil {
+		err := fs.WalkDir(resources, ".", func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() {
+				names = append(names, name)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		sort.Strings(names)
+	}
+
+	doc, err := marshalKMZDoc(root, names)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	dst, err := zw.Create(kmzRootDoc)
+	if err != nil {
+		return err
+	}
+	if _, err := dst.Write(doc); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if err := copyKMZResource(zw, resources, name); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// marshalKMZDoc marshals copy of root with href elements pointing to
+// resources rewritten to the archive location.
+func marshalKMZDoc(root *Element, names []string) ([]byte, error) {
+	known := make(map[string]bool, len(names))
+	for _, name := range names {
+		known[name] = true
+	}
+
+	cp := root.Clone()
+	for _, el := range cp.FindAll(ElemHref) {
+		href := el.ContentString()
+		if !isRelativeHref(href) || !known[path.Clean(href)] {
+			continue
+		}
+		el.SetContent([]byte(path.Join(kmzFilesDir, path.Clean(href))))
+	}
+
+	buf := &bytes.Buffer{}
+	if err := xml.NewEncoder(buf).Encode(cp); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// copyKMZResource copies file name from resources to the archive.
+func copyKMZResource(zw *zip.Writer, resources fs.FS, name string) error {
+	src, err := resources.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := zw.Create(path.Join(kmzFilesDir, name))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+// isRelativeHref returns true if href is a relative path within archive.
+func isRelativeHref(href string) bool {
+	if href == "" || strings.Contains(href, "://") || strings.HasPrefix(href, "/") {
+		return false
+	}
+	return fs.ValidPath(path.Clean(href))
+}
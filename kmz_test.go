@@ -0,0 +1,199 @@
+package kml
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// zipArchive returns zip archive with files in order.
+func zipArchive(t *testing.T, files ...string) *bytes.Reader {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for _, name := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = io.WriteString(w, `<kml><Document><name>`+name+`</name></Document></kml>`)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return bytes.NewReader(buf.Bytes())
+}
+
+func Test_ParseKMZ_RootDocument(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		files []string
+		exp   string
+	}{
+		{"doc.kml", []string{"a.kml", "doc.kml"}, "doc.kml"},
+		{"first at root", []string{"dir/a.kml", "b.txt", "c.kml", "d.kml"}, "c.kml"},
+		{"first in archive", []string{"dir/a.kml", "dir/b.kml"}, "dir/a.kml"},
+		{"upper case extension", []string{"a.txt", "A.KML"}, "A.KML"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			r := zipArchive(t, tc.files...)
+
+			// --- When ---
+			kmz, err := ParseKMZ(r, r.Size())
+
+			// --- Then ---
+			require.NoError(t, err)
+			assert.Exactly(t, tc.exp, kmz.RootPath)
+			assert.Exactly(t, tc.exp, kmz.Root.ChildAtIdx(0).ChildAtIdx(0).ContentString())
+		})
+	}
+}
+
+func Test_ParseKMZ_NoDocument(t *testing.T) {
+	// --- Given ---
+	r := zipArchive(t, "a.txt")
+
+	// --- When ---
+	kmz, err := ParseKMZ(r, r.Size())
+
+	// --- Then ---
+	assert.ErrorIs(t, err, ErrNoRootDocument)
+	assert.Nil(t, kmz)
+}
+
+func Test_ParseKMZ_NotZip(t *testing.T) {
+	// --- Given ---
+	r := bytes.NewReader([]byte("<kml></kml>"))
+
+	// --- When ---
+	kmz, err := ParseKMZ(r, r.Size())
+
+	// --- Then ---
+	assert.ErrorIs(t, err, zip.ErrFormat)
+	assert.Nil(t, kmz)
+}
+
+func Test_WriteKMZ_ParseKMZ(t *testing.T) {
+	// --- Given ---
+	root := KML(
+		Document(
+			Style("sty_0", NewElement("IconStyle", NewElement("Icon", Href("icons/pin.png")))),
+			Placemark(NewElement("Icon", Href("http://example.com/icons/pin.png"))),
+			Placemark(NewElement("Icon", Href("./overlay.jpg"))),
+			Placemark(NewElement("Icon", Href("missing.png"))),
+		),
+	)
+	res := fstest.MapFS{
+		"icons/pin.png": {Data: []byte("png")},
+		"overlay.jpg":   {Data: []byte("jpg")},
+	}
+	buf := &bytes.Buffer{}
+
+	// --- When ---
+	err := WriteKMZ(buf, root, res)
+
+	// --- Then ---
+	require.NoError(t, err)
+
+	// Original tree is not modified.
+	doc := root.ChildAtIdx(0)
+	assert.Exactly(t, "icons/pin.png", doc.ChildAtIdx(0).ChildAtIdx(0).ChildAtIdx(0).ChildAtIdx(0).ContentString())
+	assert.Exactly(t, "./overlay.jpg", doc.ChildAtIdx(2).ChildAtIdx(0).ChildAtIdx(0).ContentString())
+
+	kmz, err := ParseKMZ(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	assert.Exactly(t, "doc.kml", kmz.RootPath)
+
+	doc = kmz.Root.ChildAtIdx(0)
+	assert.Exactly(t, "files/icons/pin.png", doc.ChildAtIdx(0).ChildAtIdx(0).ChildAtIdx(0).ChildAtIdx(0).ContentString())
+	assert.Exactly(t, "http://example.com/icons/pin.png", doc.ChildAtIdx(1).ChildAtIdx(0).ChildAtIdx(0).ContentString())
+	assert.Exactly(t, "files/overlay.jpg", doc.ChildAtIdx(2).ChildAtIdx(0).ChildAtIdx(0).ContentString())
+	assert.Exactly(t, "missing.png", doc.ChildAtIdx(3).ChildAtIdx(0).ChildAtIdx(0).ContentString())
+
+	data, err := fs.ReadFile(kmz, "files/icons/pin.png")
+	require.NoError(t, err)
+	assert.Exactly(t, "png", string(data))
+
+	fil, err := kmz.OpenHref("files/overlay.jpg")
+	require.NoError(t, err)
+	data, err = io.ReadAll(fil)
+	require.NoError(t, err)
+	assert.Exactly(t, "jpg", string(data))
+	assert.NoError(t, fil.Close())
+}
+
+func Test_WriteKMZ_SourceNotModified(t *testing.T) {
+	// --- Given ---
+	data := "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+		"<kml>\n  <!-- icon -->\n  <Icon><href>pin.png</href></Icon>\n</kml>"
+	root, err := ParseLossless(strings.NewReader(data))
+	require.NoError(t, err)
+	res := fstest.MapFS{"pin.png": {Data: []byte("png")}}
+	buf := &bytes.Buffer{}
+
+	// --- When ---
+	err = WriteKMZ(buf, root, res)
+
+	// --- Then ---
+	require.NoError(t, err)
+
+	got, err := xml.Marshal(root)
+	require.NoError(t, err)
+	assert.Exactly(t, data, string(got))
+
+	kmz, err := ParseKMZ(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	assert.Exactly(t, "files/pin.png", kmz.Root.FindFirst(ElemHref).ContentString())
+}
+
+func Test_KMZ_OpenHref_NotRelative(t *testing.T) {
+	// --- Given ---
+	r := zipArchive(t, "doc.kml")
+	kmz, err := ParseKMZ(r, r.Size())
+	require.NoError(t, err)
+
+	// --- When ---
+	fil, err := kmz.OpenHref("http://example.com/doc.kml")
+
+	// --- Then ---
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.Nil(t, fil)
+}
+
+func Test_KMZ_OpenHref_RelativeToRoot(t *testing.T) {
+	// --- Given ---
+	r := zipArchive(t, "dir/a.kml", "dir/b.kml")
+	kmz, err := ParseKMZ(r, r.Size())
+	require.NoError(t, err)
+
+	// --- When ---
+	fil, err := kmz.OpenHref("b.kml")
+
+	// --- Then ---
+	require.NoError(t, err)
+	assert.NoError(t, fil.Close())
+}
+
+func Test_WriteKMZ_NoResources(t *testing.T) {
+	// --- Given ---
+	buf := &bytes.Buffer{}
+
+	// --- When ---
+	err := WriteKMZ(buf, KML(Document()), nil)
+
+	// --- Then ---
+	require.NoError(t, err)
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, zr.File, 1)
+	assert.Exactly(t, "doc.kml", zr.File[0].Name)
+}
@@ -0,0 +1,218 @@
+package kml
+
+import (
+	"bytes"
+	"encoding/xml"
+)
+
+// Leading returns comments, processing instructions, directives and
+// character data between element's previous sibling (or parent's start
+// tag) and the element. For the root element returned by ParseLossless
+// these are the tokens before the root start tag (XML declaration,
+// comments). Tokens are kept only by ParseLossless and are of type
+// xml.CharData, xml.Comment, xml.ProcInst or xml.Directive.
+func (e *Element) Leading() []xml.Token {
+	return e.lead
+}
+
+// SetLeading sets tokens written before the element. See Leading.
+func (e *Element) SetLeading(toks ...xml.Token) {
+	if e.parent != nil {
+		e.parent.modified()
+	}
+	e.lead = copyTokens(toks)
+}
+
+// Trailing returns comments, processing instructions, directives and
+// character data between element's last child (or start tag) and its end
+// tag. Content of elements without children is not included.
+func (e *Element) Trailing() []xml.Token {
+	return e.trail
+}
+
+// SetTrailing sets tokens written before the element end tag. See Trailing.
+func (e *Element) SetTrailing(toks ...xml.Token) {
+	e.modified()
+	e.trail = copyTokens(toks)
+}
+
+// modified marks element and its ancestors as modified so they are encoded
+// from the element tree instead of the source bytes.
+func (e *Element) modified() {
+	for el := e; el != nil && el.raw != nil; el = el.parent {
+		el.raw = nil
+	}
+}
+
+// dropRaw marks element and its descendants as modified and drops their
+// source tags. It's used when element is moved to other parent where
+// namespace prefixes used in the source may not be declared.
+func (e *Element) dropRaw() {
+	e.raw, e.stag, e.etag = nil, nil, nil
+	for _, ch := range e.children {
+		ch.dropRaw()
+	}
+}
+
+// hasSource returns true if element or any of its descendants has source
+// bytes or tokens other than elements.
+func (e *Element) hasSource() bool {
+	if e.raw != nil || e.stag != nil || e.trail != nil {
+		return true
+	}
+	for _, ch := range e.children {
+		if ch.lead != nil || ch.hasSource() {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeSource encodes element tags with enc and its inner content with
+// writeInner.
+func (e *Element) encodeSource(enc *xml.Encoder, scope *nsScope) error {
+	se, scope := scope.start(e)
+	buf := &bytes.Buffer{}
+	e.writeInner(buf, scope)
+	inner := struct {
+		Value []byte `xml:",innerxml"`
+	}{
+		Value: buf.Bytes(),
+	}
+	return enc.EncodeElement(inner, se)
+}
+
+// writeTo writes element to buf. Unmodified elements are written exactly
+// as they appear in the source.
+func (e *Element) writeTo(buf *bytes.Buffer, scope *nsScope) {
+	if e.raw != nil {
+		buf.Write(e.raw)
+		return
+	}
+
+	se, scope := scope.start(e)
+	if e.stag != nil {
+		buf.Write(e.stag)
+		e.writeInner(buf, scope)
+		buf.Write(e.etag)
+		return
+	}
+
+	buf.WriteByte('<')
+	buf.WriteString(se.Name.Local)
+	for _, a := range se.Attr {
+		buf.WriteByte(' ')
+		buf.WriteString(a.Name.Local)
+		buf.WriteString(`="`)
+		_ = xml.EscapeText(buf, []byte(a.Value))
+		buf.WriteByte('"')
+	}
+	buf.WriteByte('>')
+
+	e.writeInner(buf, scope)
+
+	buf.WriteString("</")
+	buf.WriteString(se.Name.Local)
+	buf.WriteByte('>')
+}
+
+// writeInner writes element's inner content to buf. Content of elements
+// without children is written the same way MarshalXML writes it.
+func (e *Element) writeInner(buf *bytes.Buffer, scope *nsScope) {
+	if e.raw != nil {
+		buf.Write(e.raw[e.inner[0]:e.inner[1]])
+		return
+	}
+
+	for _, ch := range e.children {
+		writeTokens(buf, ch.lead)
+		ch.writeTo(buf, scope)
+	}
+
+	if len(e.children) == 0 && len(e.content) > 0 {
+		if needsCDATA(e.content) {
+			buf.WriteString(cdataStart)
+			buf.Write(e.content)
+			buf.WriteString(cdataEnd)
+		} else {
+			enc := xml.NewEncoder(buf)
+			_ = enc.EncodeToken(e.content)
+			_ = enc.Flush()
+		}
+	}
+
+	writeTokens(buf, e.trail)
+}
+
+// writeTokens writes tokens to buf. Unlike xml.Encoder it doesn't escape
+// white space in character data.
+func writeTokens(buf *bytes.Buffer, toks []xml.Token) {
+	for _, tok := range toks {
+		switch t := tok.(type) {
+		case xml.CharData:
+			for _, b := range t {
+				switch b {
+				case '&':
+					buf.WriteString("&amp;")
+				case '<':
+					buf.WriteString("&lt;")
+				case '>':
+					buf.WriteString("&gt;")
+				default:
+					buf.WriteByte(b)
+				}
+			}
+
+		case xml.Comment:
+			buf.WriteString("<!--")
+			buf.Write(t)
+			buf.WriteString("-->")
+
+		case xml.ProcInst:
+			buf.WriteString("<?")
+			buf.WriteString(t.Target)
+			if len(t.Inst) > 0 {
+				buf.WriteByte(' ')
+				buf.Write(t.Inst)
+			}
+			buf.WriteString("?>")
+
+		case xml.Directive:
+			buf.WriteString("<!")
+			buf.Write(t)
+			buf.WriteByte('>')
+		}
+	}
+}
+
+// encodeTokens encodes tokens with enc.
+func encodeTokens(enc *xml.Encoder, toks []xml.Token) error {
+	for _, tok := range toks {
+		if err := enc.EncodeToken(tok); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasXMLDecl returns true if tokens contain XML declaration.
+func hasXMLDecl(toks []xml.Token) bool {
+	for _, tok := range toks {
+		if pi, ok := tok.(xml.ProcInst); ok && pi.Target == "xml" {
+			return true
+		}
+	}
+	return false
+}
+
+// copyTokens returns deep copy of tokens.
+func copyTokens(toks []xml.Token) []xml.Token {
+	if toks == nil {
+		return nil
+	}
+	cp := make([]xml.Token, len(toks))
+	for i, tok := range toks {
+		cp[i] = xml.CopyToken(tok)
+	}
+	return cp
+}
@@ -0,0 +1,275 @@
+package kml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	kit "github.com/rzajac/testkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseLossless_RoundTrip(t *testing.T) {
+	// --- Given ---
+	data := kit.ReadAll(t, kit.OpenFile(t, "testdata/lossless.kml"))
+
+	// --- When ---
+	root, err := ParseLossless(bytes.NewReader(data))
+
+	// --- Then ---
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, xml.NewEncoder(buf).Encode(root))
+	assert.Exactly(t, string(data), buf.String())
+}
+
+func Test_ParseLossless_Tree(t *testing.T) {
+	// --- Given ---
+	data := kit.ReadAll(t, kit.OpenFile(t, "testdata/lossless.kml"))
+
+	// --- When ---
+	root, err := ParseLossless(bytes.NewReader(data))
+
+	// --- Then ---
+	require.NoError(t, err)
+	doc := root.ChildByName(ElemDocument)
+	require.NotNil(t, doc)
+	assert.Exactly(t, 3, doc.ChildCnt())
+	assert.Exactly(t, "Café & bar", doc.ChildByName(ElemName).ContentString())
+
+	pm := doc.ChildByID("pm_0")
+	require.NotNil(t, pm)
+	assert.Exactly(t, "spaced", pm.ChildByName(ElemName).ContentString())
+	assert.Exactly(t, "<b>bold</b>", pm.ChildByName(ElemDescription).ContentString())
+	assert.Exactly(t, "/kml/Document/Placemark[pm_0]/Point/coordinates", pm.FindFirst(ElemCoordinates).Path())
+}
+
+func Test_ParseLossless_Tokens(t *testing.T) {
+	// --- Given ---
+	data := kit.ReadAll(t, kit.OpenFile(t, "testdata/lossless.kml"))
+
+	// --- When ---
+	root, err := ParseLossless(bytes.NewReader(data))
+
+	// --- Then ---
+	require.NoError(t, err)
+	assert.Exactly(t, []xml.Token{
+		xml.ProcInst{Target: "xml", Inst: []byte(`version="1.0" encoding="UTF-8"`)},
+		xml.CharData("\n"),
+		xml.Comment(" Hand annotated document. "),
+		xml.CharData("\n"),
+	}, root.Leading())
+	assert.Exactly(t, []xml.Token{xml.CharData("\n")}, root.Trailing())
+	assert.Exactly(t, []xml.Token{
+		xml.CharData("\n"),
+		xml.Comment(" The end. "),
+		xml.CharData("\n"),
+	}, root.tail)
+
+	doc := root.ChildByName(ElemDocument)
+	assert.Exactly(t, []xml.Token{
+		xml.CharData("\n\t\t"),
+		xml.ProcInst{Target: "editor", Inst: []byte("keep-this")},
+		xml.CharData("\n\t\t"),
+	}, doc.ChildByName(ElemName).Leading())
+	assert.Exactly(t, []xml.Token{
+		xml.CharData("\n\t\t"),
+		xml.Comment(" First placemark. "),
+		xml.CharData("\n\t\t"),
+	}, doc.ChildByID("pm_0").Leading())
+	assert.Exactly(t, []xml.Token{xml.CharData("\n\t")}, doc.Trailing())
+	assert.Nil(t, doc.ChildByID("pm_1").Trailing())
+}
+
+func Test_ParseLossless_RootTag(t *testing.T) {
+	// --- Given ---
+	data := "<kml xmlns='http://www.opengis.net/kml/2.2'  >\n<Document/>\n</kml>"
+	root, err := ParseLossless(strings.NewReader(data))
+	require.NoError(t, err)
+
+	// --- When ---
+	got, err := xml.Marshal(root)
+
+	// --- Then ---
+	require.NoError(t, err)
+	exp := "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+		"<kml xmlns=\"http://www.opengis.net/kml/2.2\">\n<Document/>\n</kml>"
+	assert.Exactly(t, exp, string(got))
+}
+
+func Test_ParseLossless_Modified(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		edit func(root *Element)
+		old  string
+		new  string
+	}{
+		{
+			"content",
+			func(root *Element) {
+				root.FindByID("pm_0").ChildByName(ElemName).SetContent([]byte("new & name"))
+			},
+			"<name>  spaced  </name>",
+			"<name>new &amp; name</name>",
+		},
+		{
+			"attribute",
+			func(root *Element) {
+				root.FindByID("pm_0").SetAttribute(Attr("id", "pm_9"))
+			},
+			"<Placemark id='pm_0'>",
+			`<Placemark id="pm_9">`,
+		},
+		{
+			"add child",
+			func(root *Element) {
+				_ = root.FindByID("pm_1").AddChild(Name("x"))
+			},
+			`<Placemark id="pm_1"/>`,
+			`<Placemark id="pm_1"><name>x</name></Placemark>`,
+		},
+		{
+			"remove child",
+			func(root *Element) {
+				pm := root.FindByID("pm_0")
+				pm.RemoveChildAtIdx(pm.ChildByName(ElemPoint).Index())
+			},
+			"\n\t\t\t<Point><coordinates>1,2</coordinates></Point>",
+			"",
+		},
+		{
+			"remove attribute",
+			func(root *Element) {
+				root.FindByID("pm_0").RemoveAttribute("id")
+			},
+			"<Placemark id='pm_0'>",
+			"<Placemark>",
+		},
+		{
+			"detach",
+			func(root *Element) {
+				root.FindByID("pm_0").ChildByName(ElemPoint).Detach()
+			},
+			"\n\t\t\t<Point><coordinates>1,2</coordinates></Point>",
+			"",
+		},
+		{
+			"set leading",
+			func(root *Element) {
+				root.FindByID("pm_0").SetLeading(xml.CharData("\n\t\t"), xml.Comment(" Edited. "), xml.CharData("\n\t\t"))
+			},
+			"<!-- First placemark. -->",
+			"<!-- Edited. -->",
+		},
+		{
+			"set trailing",
+			func(root *Element) {
+				root.FindByID("pm_1").SetTrailing(xml.Comment(" Empty. "))
+			},
+			`<Placemark id="pm_1"/>`,
+			`<Placemark id="pm_1"><!-- Empty. --></Placemark>`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			data := kit.ReadAll(t, kit.OpenFile(t, "testdata/lossless.kml"))
+			root, err := ParseLossless(bytes.NewReader(data))
+			require.NoError(t, err)
+
+			// --- When ---
+			tc.edit(root)
+
+			// --- Then ---
+			got, err := xml.Marshal(root)
+			require.NoError(t, err)
+			src := strings.ReplaceAll(string(data), "\r\n", "\n")
+			exp := strings.Replace(src, tc.old, tc.new, 1)
+			assert.Exactly(t, exp, strings.ReplaceAll(string(got), "\r\n", "\n"))
+		})
+	}
+}
+
+func Test_ParseLossless_Modified_MoveChild(t *testing.T) {
+	// --- Given ---
+	data := kit.ReadAll(t, kit.OpenFile(t, "testdata/lossless.kml"))
+	root, err := ParseLossless(bytes.NewReader(data))
+	require.NoError(t, err)
+	doc := root.ChildByName(ElemDocument)
+
+	// --- When ---
+	require.NoError(t, doc.AddChild(doc.ChildByName(ElemName).Detach()))
+
+	// --- Then ---
+	got, err := xml.Marshal(root)
+	require.NoError(t, err)
+	src := strings.ReplaceAll(string(data), "\r\n", "\n")
+	exp := strings.Replace(src, "\n\t\t<?editor keep-this?>\n\t\t<name>Caf&#233; &amp; bar</name>", "", 1)
+	exp = strings.Replace(
+		exp,
+		"\n\t</Document>",
+		"\n\t\t<?editor keep-this?>\n\t\t<name>Café &amp; bar</name>\n\t</Document>",
+		1,
+	)
+	assert.Exactly(t, exp, strings.ReplaceAll(string(got), "\r\n", "\n"))
+}
+
+func Test_Element_MarshalXML_Tokens(t *testing.T) {
+	// --- Given ---
+	doc := Document(Name("doc"))
+	doc.ChildAtIdx(0).SetLeading(xml.Comment(" name "))
+	doc.SetTrailing(xml.ProcInst{Target: "editor", Inst: []byte("x")})
+	root := KML(doc)
+
+	// --- When ---
+	got, err := xml.Marshal(root)
+
+	// --- Then ---
+	require.NoError(t, err)
+	assert.Contains(t, string(got), "<Document><!-- name --><name>doc</name><?editor x?></Document>")
+}
+
+func Test_Parse_DropsTokens(t *testing.T) {
+	// --- Given ---
+	data := kit.ReadAll(t, kit.OpenFile(t, "testdata/lossless.kml"))
+	root, err := Parse(bytes.NewReader(data))
+	require.NoError(t, err)
+	doc := root.ChildByName(ElemDocument)
+	require.NoError(t, doc.AddChild(Placemark(Name("new"))))
+
+	// --- When ---
+	buf := &bytes.Buffer{}
+	enc := xml.NewEncoder(buf)
+	enc.Indent("", "  ")
+	err = enc.Encode(root)
+
+	// --- Then ---
+	require.NoError(t, err)
+	assert.Nil(t, root.Leading())
+	assert.Nil(t, doc.ChildByName(ElemName).Leading())
+	assert.Nil(t, doc.Trailing())
+	assert.NotContains(t, buf.String(), "<!--")
+	assert.NotContains(t, buf.String(), "\t")
+	assert.Contains(t, buf.String(), "\n    <Placemark>\n      <name>new</name>\n    </Placemark>\n")
+}
+
+func Test_Element_UnmarshalXML_DropsTokens(t *testing.T) {
+	// --- Given ---
+	data := "<kml>\n\t<!-- c -->\n\t<Document><name>doc</name></Document>\n</kml>"
+	root := KML()
+
+	// --- When ---
+	err := xml.Unmarshal([]byte(data), root)
+
+	// --- Then ---
+	require.NoError(t, err)
+	got, err := xml.Marshal(root)
+	require.NoError(t, err)
+	exp := "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<kml><Document><name>doc</name></Document></kml>"
+	assert.Exactly(t, exp, string(got))
+}
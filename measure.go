@@ -0,0 +1,302 @@
+package kml
+
+import (
+	"math"
+)
+
+// WGS84 ellipsoid parameters.
+const (
+	wgs84A = 6378137.0             // Semi-major axis in meters.
+	wgs84F = 1 / 298.257223563     // Flattening.
+	wgs84B = wgs84A * (1 - wgs84F) // Semi-minor axis in meters.
+)
+
+// rad is number of radians in one degree.
+const rad = math.Pi / 180
+
+// GeodesicDistance returns length in meters of the geodesic between a and b
+// on the WGS84 ellipsoid computed with Karney's method, which converges
+// for all pairs of points including nearly antipodal ones. Altitudes are
+// ignored.
+func GeodesicDistance(a, b Coord) float64 {
+	s12, _ := geodesicInverse(a.Lat, a.Lon, b.Lat, b.Lon)
+	return s12
+}
+
+// Length returns sum of geodesic lengths in meters of LineStrings and
+// gx:Tracks in the element tree rooted at e. Rings of Polygons are not
+// included, see Perimeter.
+func (e *Element) Length() (float64, error) {
+	ms, err := measured(e)
+	if err != nil {
+		return 0, err
+	}
+	var sum float64
+	for _, line := range ms.lines {
+		sum += lineLength(line)
+	}
+	return sum, nil
+}
+
+// Perimeter returns sum of geodesic lengths in meters of outer and inner
+// boundaries of Polygons in the element tree rooted at e. LinearRings
+// outside of Polygons are measured as Polygon outer boundaries.
+func (e *Element) Perimeter() (float64, error) {
+	ms, err := measured(e)
+	if err != nil {
+		return 0, err
+	}
+	var sum float64
+	for _, poly := range ms.polys {
+		for _, ring := range poly {
+			sum += lineLength(closeRing(ring))
+		}
+	}
+	return sum, nil
+}
+
+// Area returns sum of areas in square meters of Polygons in the element
+// tree rooted at e on the WGS84 ellipsoid. Areas of inner boundaries are
+// subtracted from areas of outer boundaries. LinearRings outside of
+// Polygons are measured as Polygon outer boundaries. Rings are geodesic
+// polygons, their edges are geodesics between consecutive positions.
+func (e *Element) Area() (float64, error) {
+	ms, err := measured(e)
+	if err != nil {
+		return 0, err
+	}
+	var sum float64
+	for _, poly := range ms.polys {
+		sum += polygonArea(poly)
+	}
+	return sum, nil
+}
+
+// Centroid returns centroid of geometries in the element tree rooted at
+// e. Only geometries of the highest dimension are used: Polygon centroids
+// weighted by area when geometries have area, centroids of LineString and
+// gx:Track segments weighted by length when they have length and
+// otherwise all positions. Centroids are combined on the sphere so
+// geometries crossing the antimeridian are handled. The altitude of the
+// returned centroid is zero. Returns ErrNoGeometry when there are no
+// geometries.
+func (e *Element) Centroid() (Coord, error) {
+	ms, err := measured(e)
+	if err != nil {
+		return Coord{}, err
+	}
+
+	var sum [3]float64
+	add := func(c Coord, w float64) {
+		v := unitVector(c)
+		for i := range sum {
+			sum[i] += v[i] * w
+		}
+	}
+
+	for _, poly := range ms.polys {
+		if c, ok := planarCentroid(poly); ok {
+			add(c, polygonArea(poly))
+		}
+	}
+
+	if sum == [3]float64{} {
+		for _, line := range ms.lines {
+			for i := 1; i < len(line); i++ {
+				mid := midpoint(line[i-1], line[i])
+				add(mid, GeodesicDistance(line[i-1], line[i]))
+			}
+		}
+	}
+
+	var cnt int
+	if sum == [3]float64{} {
+		for _, c := range ms.positions() {
+			add(c, 1)
+			cnt++
+		}
+	}
+
+	if sum == [3]float64{} {
+		if cnt == 0 {
+			return Coord{}, ErrNoGeometry
+		}
+		return Coord{}, newGeometryError(e, geometryErr("centroid is undefined"))
+	}
+	return fromVector(sum), nil
+}
+
+// measurements represents geometries collected for measurements.
+type measurements struct {
+	points []Coord
+	lines  [][]Coord
+	polys  [][][]Coord // Polygon rings, outer boundary first.
+}
+
+// measured collects geometries in the element tree rooted at el.
+func measured(el *Element) (*measurements, error) {
+	ms := &measurements{}
+	err := el.Walk(func(el *Element, _ int) error {
+		switch el.qualifiedName() {
+		case ElemPoint:
+			crs, err := geometryCoords(el)
+			if err != nil {
+				return err
+			}
+			ms.points = append(ms.points, crs...)
+			return SkipSubtree
+
+		case ElemLineString:
+			crs, err := geometryCoords(el)
+			if err != nil {
+				return err
+			}
+			ms.lines = append(ms.lines, crs)
+			return SkipSubtree
+
+		case ElemGxTrack:
+			crs, err := trackCoords(el)
+			if err != nil {
+				return err
+			}
+			ms.lines = append(ms.lines, crs)
+			return SkipSubtree
+
+		case ElemLinearRing:
+			crs, err := geometryCoords(el)
+			if err != nil {
+				return err
+			}
+			ms.polys = append(ms.polys, [][]Coord{crs})
+			return SkipSubtree
+
+		case ElemPolygon:
+			rings, err := polygonRings(el)
+			if err != nil {
+				return err
+			}
+			ms.polys = append(ms.polys, rings)
+			return SkipSubtree
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
+// positions returns all collected positions.
+func (ms *measurements) positions() []Coord {
+	crs := append([]Coord{}, ms.points...)
+	for _, line := range ms.lines {
+		crs = append(crs, line...)
+	}
+	for _, poly := range ms.polys {
+		for _, ring := range poly {
+			crs = append(crs, ring...)
+		}
+	}
+	return crs
+}
+
+// lineLength returns geodesic length of line in meters.
+func lineLength(line []Coord) float64 {
+	var sum float64
+	for i := 1; i < len(line); i++ {
+		sum += GeodesicDistance(line[i-1], line[i])
+	}
+	return sum
+}
+
+// closeRing returns ring with the first position appended if the ring is
+// not closed.
+func closeRing(ring []Coord) []Coord {
+	if len(ring) == 0 {
+		return ring
+	}
+	first, last := ring[0], ring[len(ring)-1]
+	if first.Lon == last.Lon && first.Lat == last.Lat {
+		return ring
+	}
+	return append(ring[:len(ring):len(ring)], first)
+}
+
+// polygonArea returns area of polygon in square meters.
+func polygonArea(poly [][]Coord) float64 {
+	if len(poly) == 0 {
+		return 0
+	}
+	area := ringArea(poly[0])
+	for _, ring := range poly[1:] {
+		area -= ringArea(ring)
+	}
+	return math.Max(area, 0)
+}
+
+// ringArea returns area enclosed by ring in square meters. The smaller of
+// two areas the ring divides the ellipsoid into is returned.
+func ringArea(ring []Coord) float64 {
+	return math.Abs(geodesicArea(ring))
+}
+
+// planarCentroid returns centroid of polygon treating longitudes and
+// latitudes as planar coordinates. Longitudes are unwrapped relative to
+// the first position so polygons crossing the antimeridian are handled.
+// Returns false if polygon has no area.
+func planarCentroid(poly [][]Coord) (Coord, bool) {
+	if len(poly) == 0 || len(poly[0]) == 0 {
+		return Coord{}, false
+	}
+	lon0 := poly[0][0].Lon
+
+	var area, cx, cy float64
+	for i, ring := range poly {
+		var a, x, y float64
+		for j := range ring {
+			p1, p2 := ring[j], ring[(j+1)%len(ring)]
+			x1, x2 := normLon(p1.Lon-lon0), normLon(p2.Lon-lon0)
+			cross := x1*p2.Lat - x2*p1.Lat
+			a += cross
+			x += (x1 + x2) * cross
+			y += (p1.Lat + p2.Lat) * cross
+		}
+		if a == 0 {
+			continue
+		}
+		// Signed ring area a/2, centroid at (x/3a, y/3a).
+		w := math.Abs(a / 2)
+		if i > 0 {
+			w = -w
+		}
+		area += w
+		cx += x / (3 * a) * w
+		cy += y / (3 * a) * w
+	}
+	if area <= 0 {
+		return Coord{}, false
+	}
+	return Coord{Lon: normLon(lon0 + cx/area), Lat: cy / area}, true
+}
+
+// midpoint returns point halfway between a and b on the sphere.
+func midpoint(a, b Coord) Coord {
+	va, vb := unitVector(a), unitVector(b)
+	return fromVector([3]float64{va[0] + vb[0], va[1] + vb[1], va[2] + vb[2]})
+}
+
+// unitVector returns unit vector in Earth centered coordinates pointing at
+// coordinate on the sphere.
+func unitVector(c Coord) [3]float64 {
+	sinLat, cosLat := math.Sincos(c.Lat * rad)
+	sinLon, cosLon := math.Sincos(c.Lon * rad)
+	return [3]float64{cosLat * cosLon, cosLat * sinLon, sinLat}
+}
+
+// fromVector returns coordinate on the sphere for Earth centered vector.
+func fromVector(v [3]float64) Coord {
+	return Coord{
+		Lon: math.Atan2(v[1], v[0]) / rad,
+		Lat: math.Atan2(v[2], math.Hypot(v[0], v[1])) / rad,
+	}
+}
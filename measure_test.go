@@ -0,0 +1,304 @@
+package kml
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GeodesicDistance(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		a, b Coord
+		exp  float64
+	}{
+		{"same", Coord{Lon: 1, Lat: 2}, Coord{Lon: 1, Lat: 2}, 0},
+		{"equator", Coord{Lon: 0, Lat: 0}, Coord{Lon: 1, Lat: 0}, 111319.491},
+		{"meridian", Coord{Lon: 0, Lat: 0}, Coord{Lon: 0, Lat: 1}, 110574.389},
+		{"antimeridian", Coord{Lon: 179.5, Lat: 0}, Coord{Lon: -179.5, Lat: 0}, 111319.491},
+		// Flinders Peak to Buninyong from Vincenty's paper.
+		{"vincenty", Coord{Lon: 144.424867889, Lat: -37.951033417}, Coord{Lon: 143.926495528, Lat: -37.652821139}, 54972.271},
+		// JFK to LHR from GeographicLib documentation.
+		{"geographiclib", Coord{Lon: -73.8, Lat: 40.6}, Coord{Lon: -0.5, Lat: 51.6}, 5551759.400},
+		// Half of the meridian length.
+		{"antipodal", Coord{Lon: 0, Lat: 0}, Coord{Lon: 180, Lat: 0}, 20003931.459},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			got := GeodesicDistance(tc.a, tc.b)
+
+			// --- Then ---
+			assert.InDelta(t, tc.exp, got, 1e-3)
+		})
+	}
+}
+
+func Test_GeodesicDistance_NearlyAntipodal(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		a, b Coord
+		exp  float64
+	}{
+		// Reference values from GeographicLib tests rounded to meters.
+		{"equator 179", Coord{Lon: 0, Lat: 0}, Coord{Lon: 179, Lat: 0}, 19926189},
+		{"equator 179.5", Coord{Lon: 0, Lat: 0}, Coord{Lon: 179.5, Lat: 0}, 19980862},
+		{"off equator", Coord{Lon: 0, Lat: 0}, Coord{Lon: 180, Lat: 1}, 19893357},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			got := GeodesicDistance(tc.a, tc.b)
+
+			// --- Then ---
+			assert.InDelta(t, tc.exp, got, 0.5)
+		})
+	}
+}
+
+func Test_Element_Length(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		el  *Element
+		exp float64
+	}{
+		{"line", LineString(Coordinates("0,0 1,0 1,1")), 111319.491 + 110574.389},
+		{"placemark", Placemark(Name("x"), LineString(Coordinates("0,0 0,1"))), 110574.389},
+		{"multi geometry", MultiGeometry(
+			LineString(Coordinates("0,0 0,1")),
+			LineString(Coordinates("0,0 1,0")),
+			Point(Coordinates("5,5")),
+			Polygon(OuterBoundaryIs(LinearRing(Coordinates("0,0 1,0 1,1 0,0")))),
+		), 110574.389 + 111319.491},
+		{"track", GxMultiTrack(GxTrack(GxCoord("0 0 10"), GxCoord("1 0 20"))), 111319.491},
+		{"no lines", Point(Coordinates("1,2")), 0},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			got, err := tc.el.Length()
+
+			// --- Then ---
+			require.NoError(t, err)
+			assert.InDelta(t, tc.exp, got, 1e-3)
+		})
+	}
+}
+
+func Test_Element_Perimeter(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		el  *Element
+		exp float64
+	}{
+		{"polygon", Polygon(
+			OuterBoundaryIs(LinearRing(Coordinates("0,0 1,0 1,1 0,1 0,0"))),
+			InnerBoundaryIs(LinearRing(Coordinates("0,0 0,1 1,0 0,0"))),
+		), 2*110574.389 + 111319.491 + 111302.649 + 110574.389 + 111319.491 + 156899.567},
+		{"open ring", LinearRing(Coordinates("0,0 1,0 1,1 0,1")),
+			2*110574.389 + 111319.491 + 111302.649},
+		{"line", LineString(Coordinates("0,0 1,0")), 0},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			got, err := tc.el.Perimeter()
+
+			// --- Then ---
+			require.NoError(t, err)
+			assert.InDelta(t, tc.exp, got, 1)
+		})
+	}
+}
+
+func Test_Element_Area(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		el  *Element
+		exp float64
+	}{
+		// Geodesic polygon area 12308778361.469 m2 computed with GeographicLib.
+		{"square", Polygon(OuterBoundaryIs(LinearRing(Coordinates("0,0 1,0 1,1 0,1 0,0")))), 12308778361.469},
+		{"clockwise", Polygon(OuterBoundaryIs(LinearRing(Coordinates("0,0 0,1 1,1 1,0 0,0")))), 12308778361.469},
+		{"antimeridian", Polygon(OuterBoundaryIs(LinearRing(Coordinates("179.5,0 -179.5,0 -179.5,1 179.5,1 179.5,0")))), 12308778361.469},
+		{"multi geometry", MultiGeometry(
+			Polygon(OuterBoundaryIs(LinearRing(Coordinates("0,0 1,0 1,1 0,1 0,0")))),
+			LinearRing(Coordinates("10,0 11,0 11,1 10,1 10,0")),
+			LineString(Coordinates("0,0 1,0 1,1 0,1 0,0")),
+		), 2 * 12308778361.469},
+		{"no polygons", LineString(Coordinates("0,0 1,1")), 0},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			got, err := tc.el.Area()
+
+			// --- Then ---
+			require.NoError(t, err)
+			assert.InEpsilon(t, tc.exp+1, got+1, 1e-6)
+		})
+	}
+}
+
+func Test_Element_Area_Hole(t *testing.T) {
+	// --- Given ---
+	outer := LinearRing(Coordinates("0,0 2,0 2,2 0,2 0,0"))
+	inner := LinearRing(Coordinates("0,0 1,0 1,1 0,1 0,0"))
+	pm := Placemark(Polygon(
+		OuterBoundaryIs(outer.Clone()),
+		InnerBoundaryIs(inner.Clone()),
+	))
+
+	// --- When ---
+	got, err := pm.Area()
+
+	// --- Then ---
+	require.NoError(t, err)
+	expO, err := outer.Area()
+	require.NoError(t, err)
+	expI, err := inner.Area()
+	require.NoError(t, err)
+	assert.InDelta(t, expO-expI, got, 1e-3)
+	assert.InEpsilon(t, 3*12308778361.469, got, 1e-3)
+}
+
+func Test_Element_Area_PolarCap(t *testing.T) {
+	// --- Given ---
+	north := Polygon(OuterBoundaryIs(LinearRing(Coordinates("0,89 90,89 180,89 -90,89 0,89"))))
+	south := Polygon(OuterBoundaryIs(LinearRing(Coordinates("0,-89 -90,-89 180,-89 90,-89 0,-89"))))
+
+	// --- When ---
+	gotN, errN := north.Area()
+	gotS, errS := south.Area()
+
+	// --- Then ---
+	require.NoError(t, errN)
+	require.NoError(t, errS)
+	assert.InEpsilon(t, gotN, gotS, 1e-9)
+	assert.True(t, gotN > 1e10 && gotN < 4e10, gotN)
+}
+
+func Test_Element_Area_GeographicLib(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		crs       string
+		area, per float64
+	}{
+		// Antarctica polygon from GeographicLib documentation.
+		{"antarctica", "-58,-63.1 -74,-72.9 -102,-71.9 -102,-74.9 -131,-74.3 " +
+			"-163,-77.5 163,-77.4 172,-71.7 140,-65.9 113,-65.7 88,-66.6 " +
+			"59,-66.9 25,-69.8 -4,-70.0 -14,-71.0 -33,-77.3 -46,-77.9 -61,-74.7",
+			13662703680020.1, 16831067.893},
+		// Polygons from GeographicLib tests.
+		{"north cap", "0,89 90,89 180,89 270,89", 24952305678.0, 631819.8745},
+		{"diamond", "-1,0 0,-1 1,0 0,1", 24619419146.0, 627598.2731},
+		{"octant", "0,90 0,0 90,0", 63758202715511.0, 30022685},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			el := Polygon(OuterBoundaryIs(LinearRing(Coordinates(tc.crs))))
+
+			// --- When ---
+			area, errA := el.Area()
+			per, errP := el.Perimeter()
+
+			// --- Then ---
+			require.NoError(t, errA)
+			require.NoError(t, errP)
+			assert.InDelta(t, tc.area, area, 1)
+			assert.InDelta(t, tc.per, per, 1)
+		})
+	}
+}
+
+func Test_Element_Centroid(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		el  *Element
+		exp Coord
+	}{
+		{"point", Point(Coordinates("1,2,3")), Coord{Lon: 1, Lat: 2}},
+		{"points", MultiGeometry(
+			Point(Coordinates("0,0")),
+			Point(Coordinates("2,0")),
+		), Coord{Lon: 1, Lat: 0}},
+		{"line", LineString(Coordinates("0,0 2,0 6,0")), Coord{Lon: 3, Lat: 0}},
+		{"polygon", Polygon(OuterBoundaryIs(LinearRing(Coordinates("0,0 2,0 2,2 0,2 0,0")))), Coord{Lon: 1, Lat: 1}},
+		{"hole", Polygon(
+			OuterBoundaryIs(LinearRing(Coordinates("0,0 2,0 2,2 0,2 0,0"))),
+			InnerBoundaryIs(LinearRing(Coordinates("1,0 2,0 2,2 1,2 1,0"))),
+		), Coord{Lon: 0.5, Lat: 1}},
+		{"antimeridian", Polygon(OuterBoundaryIs(LinearRing(Coordinates("179,0 -179,0 -179,2 179,2 179,0")))), Coord{Lon: 180, Lat: 1}},
+		{"highest dimension", Placemark(MultiGeometry(
+			Point(Coordinates("50,50")),
+			LineString(Coordinates("40,40 41,41")),
+			Polygon(OuterBoundaryIs(LinearRing(Coordinates("0,0 2,0 2,2 0,2 0,0")))),
+		)), Coord{Lon: 1, Lat: 1}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			got, err := tc.el.Centroid()
+
+			// --- Then ---
+			require.NoError(t, err)
+			assert.InDelta(t, tc.exp.Lon, got.Lon, 1e-3)
+			assert.InDelta(t, tc.exp.Lat, got.Lat, 1e-3)
+			assert.Exactly(t, 0.0, got.Alt)
+		})
+	}
+}
+
+func Test_Element_Measure_Errors(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		el  *Element
+		err error
+	}{
+		{"coordinates", LineString(Coordinates("1,a")), ErrInvalidCoordinates},
+		{"missing", Polygon(), ErrInvalidGeometry},
+		{"track", GxTrack(GxCoord("1")), ErrInvalidCoordinates},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			_, errL := tc.el.Length()
+			_, errP := tc.el.Perimeter()
+			_, errA := tc.el.Area()
+			_, errC := tc.el.Centroid()
+
+			// --- Then ---
+			assert.True(t, errors.Is(errL, tc.err), errL)
+			assert.True(t, errors.Is(errP, tc.err), errP)
+			assert.True(t, errors.Is(errA, tc.err), errA)
+			assert.True(t, errors.Is(errC, tc.err), errC)
+		})
+	}
+}
+
+func Test_Element_Centroid_NoGeometry(t *testing.T) {
+	// --- When ---
+	_, err := Folder(Name("empty")).Centroid()
+
+	// --- Then ---
+	assert.ErrorIs(t, err, ErrNoGeometry)
+}
@@ -0,0 +1,341 @@
+package kml
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+)
+
+// XML namespaces used in KML documents.
+const (
+	NsKML  = "http://www.opengis.net/kml/2.2"
+	NsGx   = "http://www.google.com/kml/ext/2.2"
+	NsAtom = "http://www.w3.org/2005/Atom"
+)
+
+// nsXML is the namespace bound to the reserved xml prefix.
+const nsXML = "http://www.w3.org/XML/1998/namespace"
+
+// nsLegacyKML is the namespace prefix of KML versions before OGC KML 2.2.
+const nsLegacyKML = "http://earth.google.com/kml/"
+
+// knownPrefixes maps conventional prefixes to namespaces. Element names
+// passed to NewElement and lookup methods may use these prefixes
+// regardless of the prefixes declared in the document.
+var knownPrefixes = map[string]string{
+	"kml":  NsKML,
+	"gx":   NsGx,
+	"atom": NsAtom,
+}
+
+// knownNamespaces maps namespaces to their conventional prefixes.
+var knownNamespaces = map[string]string{
+	NsKML:  "kml",
+	NsGx:   "gx",
+	NsAtom: "atom",
+}
+
+// isKMLNamespace returns true if ns is the KML namespace. Empty namespace
+// (elements created without namespace) and namespaces of KML versions
+// before 2.2 are considered KML namespace.
+func isKMLNamespace(ns string) bool {
+	return ns == "" || ns == NsKML || strings.HasPrefix(ns, nsLegacyKML)
+}
+
+// parseName parses element name in one of the forms:
+//
+//	local         - KML element
+//	prefix:local  - element in namespace with conventional prefix (gx, atom, kml)
+//	{ns}local     - element in namespace ns
+//
+// Names with unknown prefixes are returned as local names.
+func parseName(name string) xml.Name {
+	if strings.HasPrefix(name, "{") {
+		if i := strings.IndexByte(name, '}'); i > 0 {
+			return xml.Name{Space: name[1:i], Local: name[i+1:]}
+		}
+	}
+	if i := strings.IndexByte(name, ':'); i > 0 {
+		if ns, ok := knownPrefixes[name[:i]]; ok {
+			return xml.Name{Space: ns, Local: name[i+1:]}
+		}
+	}
+	return xml.Name{Local: name}
+}
+
+// sameName returns true if names n and m have the same local name and
+// equivalent namespaces.
+func sameName(n, m xml.Name) bool {
+	if n.Local != m.Local {
+		return false
+	}
+	if n.Space == m.Space {
+		return true
+	}
+	if m.Space == "" || m.Space == NsKML {
+		return isKMLNamespace(n.Space)
+	}
+	if n.Space == "" || n.Space == NsKML {
+		return isKMLNamespace(m.Space)
+	}
+	return false
+}
+
+// decodeName returns element name as decoded by xml.Decoder with
+// undeclared conventional prefixes resolved to their namespaces.
+func decodeName(n xml.Name) xml.Name {
+	if ns, ok := knownPrefixes[n.Space]; ok {
+		n.Space = ns
+	}
+	return n
+}
+
+// decodeAttrs returns attributes as decoded by xml.Decoder with namespace
+// declarations converted to attributes named xmlns:prefix. The default
+// namespace declaration is dropped because the namespace is kept in the
+// element name.
+func decodeAttrs(attrs []xml.Attr) []xml.Attr {
+	if len(attrs) == 0 {
+		return nil
+	}
+	out := make([]xml.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		switch {
+		case a.Name.Space == "" && a.Name.Local == "xmlns":
+			continue
+		case a.Name.Space == "xmlns":
+			a.Name = xml.Name{Local: "xmlns:" + a.Name.Local}
+		case a.Name.Space == "xml":
+			a.Name.Space = nsXML
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// NewElementNS returns new instance of Element with name in namespace
+// space and adds child elements or attributes to it. NewElementNS panics
+// if variadic argument is not one of xml.Attr or *Element
+func NewElementNS(space, local string, els ...interface{}) *Element {
+	xel := NewElement(local, els...)
+	xel.se.Name = xml.Name{Space: space, Local: local}
+	return xel
+}
+
+// Name returns element's namespace and local name. The namespace is empty
+// for elements created without namespace, such elements belong to the
+// default namespace of the document they are added to.
+func (e *Element) Name() xml.Name {
+	return e.se.Name
+}
+
+// MatchName returns true if element has name. The name may be a local
+// name of KML element, name with conventional prefix (gx:Track,
+// atom:link, kml:Placemark) or name in "{namespace}local" form. Prefixes
+// declared in the document are not taken into account, matching is done
+// by namespace.
+func (e *Element) MatchName(name string) bool {
+	return sameName(e.se.Name, parseName(name))
+}
+
+// qualifiedName returns element name for display purposes. Elements in
+// namespaces with conventional prefixes are prefixed and elements in other
+// namespaces are in "{namespace}local" form.
+func (e *Element) qualifiedName() string {
+	n := e.se.Name
+	if isKMLNamespace(n.Space) {
+		return n.Local
+	}
+	if p, ok := knownNamespaces[n.Space]; ok {
+		return p + ":" + n.Local
+	}
+	return "{" + n.Space + "}" + n.Local
+}
+
+// inheritedNS returns declarations of namespace prefixes declared on
+// ancestors of e and bound to namespaces used in the tree rooted at e.
+// Prefixes declared on e and conventional gx and atom prefixes bound to
+// their namespaces are not returned.
+func (e *Element) inheritedNS() []xml.Attr {
+	if e.parent == nil {
+		return nil
+	}
+
+	used := make(map[string]bool)
+	_ = e.Walk(func(el *Element, _ int) error {
+		used[el.se.Name.Space] = true
+		for _, a := range el.se.Attr {
+			used[a.Name.Space] = true
+		}
+		return nil
+	})
+
+	seen := make(map[string]bool)
+	for _, a := range e.se.Attr {
+		if p, ok := nsDeclPrefix(a); ok {
+			seen[p] = true
+		}
+	}
+
+	var decl []xml.Attr
+	for _, anc := range e.Ancestors() {
+		for _, a := range anc.se.Attr {
+			p, ok := nsDeclPrefix(a)
+			if !ok || seen[p] {
+				continue
+			}
+			seen[p] = true
+			if used[a.Value] && knownPrefixes[p] != a.Value {
+				decl = append(decl, xml.Attr{Name: xml.Name{Local: "xmlns:" + p}, Value: a.Value})
+			}
+		}
+	}
+	return decl
+}
+
+// nsDeclPrefix returns prefix declared by attribute a. Returns false if a
+// is not a prefixed namespace declaration.
+func nsDeclPrefix(a xml.Attr) (string, bool) {
+	switch {
+	case a.Name.Space == "" && strings.HasPrefix(a.Name.Local, "xmlns:"):
+		return a.Name.Local[len("xmlns:"):], true
+	case a.Name.Space == "xmlns":
+		return a.Name.Local, true
+	}
+	return "", false
+}
+
+// nsScope represents namespace prefixes in scope while encoding.
+type nsScope struct {
+	def      string            // Default namespace, empty if not declared.
+	prefixes map[string]string // Prefix to namespace.
+	uris     map[string]string // Namespace to prefix.
+}
+
+// newNSScope returns scope with conventional gx and atom prefixes bound.
+// Bindings from the initial scope are never declared in the output, which
+// allows encoding document fragments the way they are written in KML.
+func newNSScope() *nsScope {
+	sc := &nsScope{
+		prefixes: make(map[string]string),
+		uris:     make(map[string]string),
+	}
+	sc.bind("gx", NsGx)
+	sc.bind("atom", NsAtom)
+	return sc
+}
+
+// clone returns copy of the scope.
+func (s *nsScope) clone() *nsScope {
+	sc := &nsScope{
+		def:      s.def,
+		prefixes: make(map[string]string, len(s.prefixes)),
+		uris:     make(map[string]string, len(s.uris)),
+	}
+	for p, ns := range s.prefixes {
+		sc.prefixes[p] = ns
+	}
+	for ns, p := range s.uris {
+		sc.uris[ns] = p
+	}
+	return sc
+}
+
+// bind binds prefix to namespace.
+func (s *nsScope) bind(prefix, ns string) {
+	if old, ok := s.prefixes[prefix]; ok && s.uris[old] == prefix {
+		delete(s.uris, old)
+	}
+	s.prefixes[prefix] = ns
+	s.uris[ns] = prefix
+}
+
+// alloc binds new prefix to namespace and returns it. Conventional prefix
+// is used if it's not bound already.
+func (s *nsScope) alloc(ns string) string {
+	p, ok := knownNamespaces[ns]
+	if _, bound := s.prefixes[p]; !ok || bound {
+		for i := 1; ; i++ {
+			p = "ns" + strconv.Itoa(i)
+			if _, bound := s.prefixes[p]; !bound {
+				break
+			}
+		}
+	}
+	s.bind(p, ns)
+	return p
+}
+
+// start returns start element for e with prefixed names and the scope for
+// its children. Namespaces which are not in scope are declared on the
+// element: the default namespace before other attributes and prefixed
+// namespaces after them. KML namespace is declared as the default
+// namespace when there is none, even if a prefix is bound to it, other
+// namespaces are always prefixed so elements without namespace stay in
+// the default namespace.
+func (s *nsScope) start(e *Element) (xml.StartElement, *nsScope) {
+	sc := s
+	mutable := func() {
+		if sc == s {
+			sc = s.clone()
+		}
+	}
+
+	for _, a := range e.se.Attr {
+		switch {
+		case a.Name.Space == "" && a.Name.Local == "xmlns":
+			mutable()
+			sc.def = a.Value
+		case a.Name.Space == "" && strings.HasPrefix(a.Name.Local, "xmlns:"):
+			mutable()
+			sc.bind(a.Name.Local[len("xmlns:"):], a.Value)
+		case a.Name.Space == "xmlns":
+			mutable()
+			sc.bind(a.Name.Local, a.Value)
+		}
+	}
+
+	var decl, attrs []xml.Attr
+	se := xml.StartElement{Name: xml.Name{Local: e.se.Name.Local}}
+	switch ns := e.se.Name.Space; {
+	case ns == "" || ns == sc.def:
+
+	case sc.def == "" && isKMLNamespace(ns):
+		mutable()
+		sc.def = ns
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "xmlns"}, Value: ns})
+
+	case sc.uris[ns] != "":
+		se.Name.Local = sc.uris[ns] + ":" + e.se.Name.Local
+
+	default:
+		mutable()
+		p := sc.alloc(ns)
+		se.Name.Local = p + ":" + e.se.Name.Local
+		decl = append(decl, xml.Attr{Name: xml.Name{Local: "xmlns:" + p}, Value: ns})
+	}
+
+	for _, a := range e.se.Attr {
+		switch ns := a.Name.Space; {
+		case ns == "":
+
+		case ns == "xmlns":
+			a.Name = xml.Name{Local: "xmlns:" + a.Name.Local}
+
+		case ns == nsXML:
+			a.Name = xml.Name{Local: "xml:" + a.Name.Local}
+
+		case sc.uris[ns] != "":
+			a.Name = xml.Name{Local: sc.uris[ns] + ":" + a.Name.Local}
+
+		default:
+			mutable()
+			p := sc.alloc(ns)
+			decl = append(decl, xml.Attr{Name: xml.Name{Local: "xmlns:" + p}, Value: ns})
+			a.Name = xml.Name{Local: p + ":" + a.Name.Local}
+		}
+		attrs = append(attrs, a)
+	}
+	se.Attr = append(attrs, decl...)
+	return se, sc
+}
@@ -0,0 +1,199 @@
+package kml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	kit "github.com/rzajac/testkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Parse_Namespaces(t *testing.T) {
+	// --- When ---
+	root, err := Parse(kit.OpenFile(t, "testdata/namespaces.kml"))
+
+	// --- Then ---
+	require.NoError(t, err)
+	assert.Exactly(t, xml.Name{Space: NsKML, Local: ElemKML}, root.Name())
+
+	trk := root.FindFirst(ElemGxTrack)
+	require.NotNil(t, trk)
+	assert.Exactly(t, "Track", trk.LocalName())
+	assert.Exactly(t, xml.Name{Space: NsGx, Local: "Track"}, trk.Name())
+	assert.Exactly(t, "/kml/Document/Placemark[pm_0]/gx:Track", trk.Path())
+	assert.True(t, trk.HasChild(ElemGxCoord))
+	assert.True(t, trk.HasChild(ElemWhen))
+	assert.False(t, trk.HasChild("coord"))
+
+	author := root.FindFirst(ElemAtomAuthor)
+	require.NotNil(t, author)
+	assert.Exactly(t, "John Doe", author.ChildByName(ElemAtomName).ContentString())
+	assert.Nil(t, author.ChildByName(ElemName))
+
+	num := root.FindFirst("{http://campsites.example.com}number")
+	require.NotNil(t, num)
+	assert.Exactly(t, "/kml/Document/Placemark[pm_0]/ExtendedData/{http://campsites.example.com}number", num.Path())
+
+	sel, err := root.Select("//Placemark/gx:Track/gx:coord")
+	require.NoError(t, err)
+	assert.Len(t, sel, 1)
+}
+
+func Test_Parse_Namespaces_RoundTrip(t *testing.T) {
+	// --- Given ---
+	data := kit.ReadAll(t, kit.OpenFile(t, "testdata/namespaces.kml"))
+	root, err := Parse(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	// --- When ---
+	buf := &bytes.Buffer{}
+	enc := xml.NewEncoder(buf)
+	enc.Indent("", "  ")
+	require.NoError(t, enc.Encode(root))
+
+	// --- Then ---
+	assert.Exactly(t, string(bytes.TrimSpace(data)), buf.String())
+}
+
+func Test_Element_MarshalXML_DeclaredPrefixes(t *testing.T) {
+	// --- Given ---
+	root, err := Parse(kit.OpenFile(t, "testdata/namespaces.kml"))
+	require.NoError(t, err)
+	pm := root.FindByID("pm_0")
+	pm.RemoveChildren()
+	pm.AddChild(
+		GxTrack(GxCoord("1 2 3")),
+		NewElementNS("http://example.com/ns", "custom"),
+	)
+
+	// --- When ---
+	data, err := xml.Marshal(pm)
+
+	// --- Then ---
+	require.NoError(t, err)
+	exp := `<Placemark xmlns="http://www.opengis.net/kml/2.2" id="pm_0" xmlns:google="http://www.google.com/kml/ext/2.2">` +
+		`<google:Track><google:coord>1 2 3</google:coord></google:Track>` +
+		`<ns1:custom xmlns:ns1="http://example.com/ns"></ns1:custom>` +
+		`</Placemark>`
+	assert.Exactly(t, exp, string(data))
+
+	// --- When ---
+	data, err = xml.Marshal(root)
+
+	// --- Then ---
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `<google:Track><google:coord>1 2 3</google:coord></google:Track>`)
+	assert.Contains(t, string(data), `<ns1:custom xmlns:ns1="http://example.com/ns"></ns1:custom>`)
+}
+
+func Test_Element_MarshalXML_InheritedPrefixes(t *testing.T) {
+	// --- Given ---
+	root, err := ParseLossless(kit.OpenFile(t, "testdata/namespaces.kml"))
+	require.NoError(t, err)
+	pm := root.FindByID("pm_0")
+
+	// --- When ---
+	data, err := xml.Marshal(pm)
+
+	// --- Then ---
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `xmlns:google="http://www.google.com/kml/ext/2.2"`)
+	assert.Contains(t, string(data), `xmlns:camp="http://campsites.example.com"`)
+	assert.NotContains(t, string(data), `xmlns:a=`)
+	assertFragment(t, data)
+
+	// --- When ---
+	data, err = xml.Marshal(pm.Clone())
+
+	// --- Then ---
+	require.NoError(t, err)
+	assertFragment(t, data)
+}
+
+// assertFragment asserts encoded Placemark fragment is namespace well
+// formed.
+func assertFragment(t *testing.T, data []byte) {
+	t.Helper()
+	doc := append(append([]byte("<kml>"), data...), "</kml>"...)
+	got, err := Parse(bytes.NewReader(doc))
+	require.NoError(t, err)
+	trk := got.FindFirst(ElemGxTrack)
+	require.NotNil(t, trk)
+	assert.Exactly(t, NsGx, trk.Name().Space)
+	assert.Exactly(t, "14", got.FindFirst("{http://campsites.example.com}number").ContentString())
+}
+
+func Test_Element_MarshalXML_ConventionalPrefix(t *testing.T) {
+	// --- Given ---
+	root := NewElement("root", Attr("xmlns:gx", "http://example.com/other"), GxTrack(), AtomLink())
+
+	// --- When ---
+	data, err := xml.Marshal(root)
+
+	// --- Then ---
+	require.NoError(t, err)
+	exp := `<root xmlns:gx="http://example.com/other">` +
+		`<ns1:Track xmlns:ns1="http://www.google.com/kml/ext/2.2"></ns1:Track>` +
+		`<atom:link></atom:link>` +
+		`</root>`
+	assert.Exactly(t, exp, string(data))
+}
+
+func Test_Parse_UndeclaredPrefix(t *testing.T) {
+	// --- Given ---
+	doc := `<kml xmlns="http://www.opengis.net/kml/2.2"><Placemark><gx:Track></gx:Track></Placemark></kml>`
+
+	// --- When ---
+	root, err := Parse(bytes.NewReader([]byte(doc)))
+
+	// --- Then ---
+	require.NoError(t, err)
+	trk := root.FindFirst(ElemGxTrack)
+	require.NotNil(t, trk)
+	assert.Exactly(t, NsGx, trk.Name().Space)
+}
+
+func Test_Element_MatchName(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		elm  *Element
+		name string
+		exp  bool
+	}{
+		{"builder local", Placemark(), "Placemark", true},
+		{"builder kml prefix", Placemark(), "kml:Placemark", true},
+		{"builder clark", Placemark(), "{" + NsKML + "}Placemark", true},
+		{"kml namespace local", NewElementNS(NsKML, "Placemark"), "Placemark", true},
+		{"legacy namespace local", NewElementNS("http://earth.google.com/kml/2.1", "Placemark"), "Placemark", true},
+		{"different local", Placemark(), "Folder", false},
+		{"gx prefix", GxTrack(), "gx:Track", true},
+		{"gx local", GxTrack(), "Track", false},
+		{"gx clark", GxTrack(), "{" + NsGx + "}Track", true},
+		{"atom", AtomLink(), "atom:link", true},
+		{"atom as kml", AtomLink(), "link", false},
+		{"other namespace", NewElementNS("http://example.com", "a"), "{http://example.com}a", true},
+		{"other namespace local", NewElementNS("http://example.com", "a"), "a", false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			got := tc.elm.MatchName(tc.name)
+
+			// --- Then ---
+			assert.Exactly(t, tc.exp, got)
+		})
+	}
+}
+
+func Test_NewElement_Prefixed(t *testing.T) {
+	// --- When ---
+	el := NewElement("gx:Track")
+
+	// --- Then ---
+	assert.Exactly(t, xml.Name{Space: NsGx, Local: "Track"}, el.Name())
+	assert.Exactly(t, "Track", el.LocalName())
+}
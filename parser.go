@@ -1,9 +1,11 @@
 package kml
 
 import (
+	"bytes"
 	"encoding/xml"
 	"errors"
 	"io"
+	"strings"
 )
 
 // ErrInvalidKML is returned when invalid KML was passed to the Parser.
@@ -13,18 +15,251 @@ var ErrInvalidKML = errors.New("not KML")
 // start element is passed.
 var ErrUnexpectedElement = errors.New("unexpected element")
 
-// Parse parses KML and returns instance of Parser.
+// ParseError describes error encountered while parsing KML document.
+// ParseErrors caused by XML syntax errors or unexpected elements match
+// ErrInvalidKML when tested with errors.Is, errors returned by the reader
+// don't.
+type ParseError struct {
+	Path  string   // Path of the element being parsed. Empty for errors outside root element.
+	Pos   Position // Position of the offending token.
+	Token string   // Offending token if known.
+	Err   error    // Underlying error.
+}
+
+// newParseError returns ParseError for error err encountered at pos while
+// parsing element el (may be nil). If err is already a *ParseError it is
+// returned as is.
+func newParseError(el *Element, pos Position, tok string, err error) error {
+	if _, ok := err.(*ParseError); ok {
+		return err
+	}
+	pErr := &ParseError{
+		Pos:   pos,
+		Token: tok,
+		Err:   err,
+	}
+	if el != nil {
+		pErr.Path = el.Path()
+	}
+	return pErr
+}
+
+// Error returns error message. The line number of xml.SyntaxError is not
+// repeated since it is part of the position. The position is omitted when
+// it's unknown.
+func (e *ParseError) Error() string {
+	var b strings.Builder
+	if e.Pos.Line > 0 {
+		b.WriteString(e.Pos.String())
+		b.WriteString(": ")
+	}
+	if e.Path != "" {
+		b.WriteString(e.Path)
+		b.WriteString(": ")
+	}
+	if e.Token != "" {
+		b.WriteString("at ")
+		b.WriteString(e.Token)
+		b.WriteString(": ")
+	}
+	if sErr, ok := e.Err.(*xml.SyntaxError); ok {
+		b.WriteString("XML syntax error: ")
+		b.WriteString(sErr.Msg)
+	} else {
+		b.WriteString(e.Err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Is returns true if target is ErrInvalidKML and the error was caused by
+// XML syntax error or unexpected element.
+func (e *ParseError) Is(target error) bool {
+	if target != ErrInvalidKML {
+		return false
+	}
+	var sErr *xml.SyntaxError
+	return errors.As(e.Err, &sErr) || errors.Is(e.Err, ErrUnexpectedElement)
+}
+
+// Parse parses KML and returns its root element. All returned errors are
+// of type *ParseError.
+//
+// Comments, processing instructions, directives and white space between
+// elements are dropped, see ParseLossless for the parser keeping them.
 func Parse(r io.Reader) (*Element, error) {
-	dec := xml.NewDecoder(r)
+	win := &sourceWindow{r: r}
+	return parse(&decoder{Decoder: xml.NewDecoder(win), win: win})
+}
 
-	kml := KML()
-	if err := dec.Decode(kml); err != nil {
-		return nil, err
+// ParseLossless parses KML and returns its root element keeping the source
+// document formatting. All returned errors are of type *ParseError.
+//
+// Comments, processing instructions, directives and white space are kept
+// in the element tree, see Element.Leading and Element.Trailing. Encoding
+// unmodified tree with xml.Encoder reproduces the source byte for byte
+// except for the root element tags, which are always encoded by the
+// encoder, and white space before or after the root element, which the
+// decoder normalizes. Modifying element's content or children causes the
+// element's inner content to be encoded from the tree, unmodified children
+// are still written from the source. Element tags are encoded from the
+// tree only when element's attributes were modified or the element was
+// moved to another parent. The encoder indentation is not applied to
+// elements with kept formatting.
+//
+// Unlike Parse, the whole document is read to memory and elements keep
+// references to their source bytes.
+func ParseLossless(r io.Reader) (*Element, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, newParseError(nil, Position{}, "", err)
 	}
+	return parse(&decoder{Decoder: xml.NewDecoder(bytes.NewReader(src)), src: src})
+}
+
+// decoder represents decoder of a single KML document.
+type decoder struct {
+	*xml.Decoder
+
+	// Source document. When not nil the decoded tree keeps the source
+	// formatting.
+	src []byte
 
-	if kml.se.Name.Local != ElemKML {
-		return nil, ErrInvalidKML
+	// Recently read source bytes used to report syntax errors, may be nil.
+	win *sourceWindow
+}
+
+// lossless returns true if decoded tree keeps the source formatting.
+func (d *decoder) lossless() bool {
+	return d.src != nil
+}
+
+// parse parses KML document from d.
+func parse(d *decoder) (*Element, error) {
+	var lead []xml.Token
+	for {
+		pos := decoderPos(d.Decoder)
+		tok, err := d.Token()
+		if err != nil {
+			if err == io.EOF {
+				err = ErrInvalidKML
+			}
+			return nil, newParseError(nil, pos, d.syntaxToken(pos, err), err)
+		}
+
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			if d.lossless() {
+				lead = append(lead, xml.CopyToken(tok))
+			}
+			continue
+		}
+
+		if se.Name.Local != ElemKML {
+			return nil, newParseError(nil, pos, startTag(se), ErrInvalidKML)
+		}
+
+		kml := KML()
+		kml.pos = pos
+		kml.lead = lead
+		if err := kml.decode(d, se); err != nil {
+			return nil, err
+		}
+		if kml.tail, err = parseTail(d); err != nil {
+			return nil, err
+		}
+		return kml, nil
+	}
+}
+
+// parseTail returns tokens after the root element. Tokens are returned
+// only in lossless mode.
+func parseTail(d *decoder) ([]xml.Token, error) {
+	var toks []xml.Token
+	for {
+		pos := decoderPos(d.Decoder)
+		tok, err := d.Token()
+		if err == io.EOF {
+			return toks, nil
+		}
+		if err != nil {
+			return nil, newParseError(nil, pos, d.syntaxToken(pos, err), err)
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			return nil, newParseError(nil, pos, startTag(se), ErrUnexpectedElement)
+		}
+		if d.lossless() {
+			toks = append(toks, xml.CopyToken(tok))
+		}
+	}
+}
+
+// maxTokenLen is the maximum length of ParseError token taken from the
+// source document.
+const maxTokenLen = 32
+
+// syntaxToken returns source text decoder read from pos until it
+// encountered syntax error err. Returns empty string if err is not
+// *xml.SyntaxError or the source text is not available. Text longer than
+// maxTokenLen bytes is truncated.
+func (d *decoder) syntaxToken(pos Position, err error) string {
+	if _, ok := err.(*xml.SyntaxError); !ok {
+		return ""
+	}
+
+	var src []byte
+	var off int64 // Source offset of src[0].
+	switch {
+	case d.src != nil:
+		src = d.src
+	case d.win != nil:
+		src, off = d.win.buf, d.win.off
+	default:
+		return ""
+	}
+
+	start, end := pos.Offset-off, d.InputOffset()-off
+	if end > int64(len(src)) {
+		end = int64(len(src))
+	}
+	if start < 0 || start >= end {
+		return ""
+	}
+	tok := strings.TrimSpace(string(src[start:end]))
+	if len(tok) > maxTokenLen {
+		tok = tok[:maxTokenLen] + "..."
+	}
+	return tok
+}
+
+// windowSize is the number of recently read bytes kept by sourceWindow. It
+// must be larger than the read buffer of xml.Decoder.
+const windowSize = 8192
+
+// sourceWindow is a reader keeping the most recently read bytes so syntax
+// errors can be reported without reading the whole document to memory.
+type sourceWindow struct {
+	r   io.Reader
+	buf []byte // Recently read bytes.
+	off int64  // Source offset of buf[0].
+}
+
+// Read implements io.Reader interface.
+func (w *sourceWindow) Read(p []byte) (int, error) {
+	n, err := w.r.Read(p)
+	w.buf = append(w.buf, p[:n]...)
+	if over := len(w.buf) - windowSize; over > 0 {
+		w.buf = append(w.buf[:0], w.buf[over:]...)
+		w.off += int64(over)
 	}
+	return n, err
+}
 
-	return kml, nil
+// startTag returns start element tag name in angle brackets.
+func startTag(se xml.StartElement) string {
+	return "<" + se.Name.Local + ">"
 }
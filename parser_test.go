@@ -1,12 +1,192 @@
 package kml
 
 import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	kit "github.com/rzajac/testkit"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+func Test_Parse_Position(t *testing.T) {
+	// --- Given ---
+	f := kit.OpenFile(t, "testdata/example.kml")
+
+	// --- When ---
+	root, err := Parse(f)
+
+	// --- Then ---
+	require.NoError(t, err)
+
+	exp := Position{Offset: 39, EndOffset: 1118, Line: 2, Column: 1}
+	assert.Exactly(t, exp, root.Position())
+
+	exp = Position{Offset: 215, EndOffset: 1111, Line: 3, Column: 3}
+	assert.Exactly(t, exp, root.ChildAtIdx(0).Position())
+
+	exp = Position{Offset: 965, EndOffset: 1015, Line: 29, Column: 13}
+	assert.Exactly(t, exp, root.FindFirst(ElemCoordinates).Position())
+	assert.Exactly(t, "29:13", root.FindFirst(ElemCoordinates).Position().String())
+}
+
+func Test_Parse_NotKML(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		data string
+		exp  string
+	}{
+		{"empty", "", "1:1: not KML"},
+		{"prolog only", `<?xml version="1.0"?>`, "1:22: not KML"},
+		{"other root", "<?xml version=\"1.0\"?>\n<gpx></gpx>", "2:1: at <gpx>: not KML"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			root, err := Parse(strings.NewReader(tc.data))
+
+			// --- Then ---
+			assert.ErrorIs(t, err, ErrInvalidKML)
+			assert.EqualError(t, err, tc.exp)
+			assert.Nil(t, root)
+		})
+	}
+}
+
+func Test_Parse_Errors(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		data string
+		path string
+		pos  Position
+		tok  string
+		exp  string
+	}{
+		{
+			"syntax error before root",
+			"<?xml version=\"1.0\"?>\n<kml",
+			"",
+			Position{Offset: 22, Line: 2, Column: 1},
+			"<kml",
+			"2:1: at <kml: XML syntax error: unexpected EOF",
+		},
+		{
+			"mismatched end tag",
+			"<kml>\n  <Document>\n    <Folder>\n    </Document>\n</kml>",
+			"/kml/Document/Folder",
+			Position{Offset: 36, Line: 4, Column: 5},
+			"</Document>",
+			"4:5: /kml/Document/Folder: at </Document>: XML syntax error: element <Folder> closed by </Document>",
+		},
+		{
+			"truncated",
+			"<kml><Document><Placemark id=\"pm_0\"><name>",
+			"/kml/Document/Placemark[pm_0]/name",
+			Position{Offset: 42, Line: 1, Column: 43},
+			"",
+			"1:43: /kml/Document/Placemark[pm_0]/name: XML syntax error: unexpected EOF",
+		},
+		{
+			"invalid entity",
+			"<kml><name>a &bogus; b</name></kml>",
+			"/kml/name",
+			Position{Offset: 11, Line: 1, Column: 12},
+			"a &bogus;",
+			"1:12: /kml/name: at a &bogus;: XML syntax error: invalid character entity &bogus;",
+		},
+		{
+			"long token",
+			"<kml><name attr_with_long_name_0123456789=1></name></kml>",
+			"/kml",
+			Position{Offset: 5, Line: 1, Column: 6},
+			"<name attr_with_long_name_012345...",
+			"1:6: /kml: at <name attr_with_long_name_012345...: XML syntax error: unquoted or missing attribute value in element",
+		},
+		{
+			"large document",
+			"<kml>" + strings.Repeat(" ", 3*windowSize) + "<name>a &bogus; b</name></kml>",
+			"/kml/name",
+			Position{Offset: 3*windowSize + 11, Line: 1, Column: 3*windowSize + 12},
+			"a &bogus;",
+			"1:24588: /kml/name: at a &bogus;: XML syntax error: invalid character entity &bogus;",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			root, err := Parse(strings.NewReader(tc.data))
+
+			// --- Then ---
+			assert.Nil(t, root)
+			assert.ErrorIs(t, err, ErrInvalidKML)
+			assert.EqualError(t, err, tc.exp)
+
+			var pErr *ParseError
+			require.True(t, errors.As(err, &pErr))
+			assert.Exactly(t, tc.path, pErr.Path)
+			assert.Exactly(t, tc.pos, pErr.Pos)
+			assert.Exactly(t, tc.tok, pErr.Token)
+
+			var sErr *xml.SyntaxError
+			assert.True(t, errors.As(err, &sErr))
+		})
+	}
+}
+
+func Test_Parse_ReadError(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		parse func(io.Reader) (*Element, error)
+		exp   string
+	}{
+		{"Parse", Parse, "1:12: /kml/name: network down"},
+		{"ParseLossless", ParseLossless, "network down"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			rErr := errors.New("network down")
+			r := io.MultiReader(strings.NewReader("<kml><name>"), iotest.ErrReader(rErr))
+
+			// --- When ---
+			root, err := tc.parse(r)
+
+			// --- Then ---
+			assert.Nil(t, root)
+			assert.ErrorIs(t, err, rErr)
+			assert.NotErrorIs(t, err, ErrInvalidKML)
+			assert.EqualError(t, err, tc.exp)
+		})
+	}
+}
+
+func Test_Element_UnmarshalXML_UnexpectedElement(t *testing.T) {
+	// --- Given ---
+	root := KML()
+
+	// --- When ---
+	err := xml.Unmarshal([]byte("<Document></Document>"), root)
+
+	// --- Then ---
+	assert.ErrorIs(t, err, ErrUnexpectedElement)
+	assert.ErrorIs(t, err, ErrInvalidKML)
+	assert.EqualError(t, err, "1:11: /kml: at <Document>: unexpected element")
+
+	var pErr *ParseError
+	require.True(t, errors.As(err, &pErr))
+	assert.Exactly(t, Position{Offset: 10, Line: 1, Column: 11}, pErr.Pos)
+}
+
 func Test_Parse(t *testing.T) {
 	// --- Given ---
 	f := kit.OpenFile(t, "testdata/example.kml")
@@ -19,7 +199,7 @@ func Test_Parse(t *testing.T) {
 
 	// kml.
 	assert.Exactly(t, "kml", root.LocalName())
-	assert.Exactly(t, int64(0), root.Offset())
+	assert.Exactly(t, int64(39), root.Offset())
 	assert.Exactly(t, 3, root.AttributeCnt())
 	assert.Exactly(t, 1, root.ChildCnt())
 	assert.True(t, root.HasChild(ElemDocument))
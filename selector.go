@@ -0,0 +1,382 @@
+package kml
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// ErrInvalidSelector is returned when selector expression is malformed.
+var ErrInvalidSelector = errors.New("invalid selector")
+
+// Selector represents compiled selector expression.
+//
+// Selector expression is a list of steps separated by "/" (child axis) or
+// "//" (descendant axis). Each step is an element name (KML element local
+// name or name with gx, atom or kml prefix) or "*" followed by zero or
+// more predicates in square brackets:
+//
+//	[@id='fld_0']  - attribute id equals fld_0,
+//	[@id]          - attribute id exists,
+//	[name='x']     - child element name has content x,
+//	[name]         - child element name exists,
+//	[2]            - second matching child (1 based).
+//
+// Expression starting with "//" matches descendants of the element
+// the selector is run against, expression starting with "/" requires the
+// first step to match the element itself, otherwise the first step matches
+// its children. Predicates are applied in order so positional predicates
+// count only elements matched by preceding predicates. Positions are
+// counted among children of the same parent, "//Placemark[1]" matches the
+// first Placemark of every parent.
+//
+// Example:
+//
+//	Document/Folder[@id='fld_0']//Placemark[name='x']/styleUrl
+type Selector struct {
+	expr  string
+	abs   bool
+	steps []selStep
+}
+
+// selStep represents single selector step.
+type selStep struct {
+	desc  bool   // Descendant axis.
+	name  string // Element local name or "*".
+	preds []selPred
+}
+
+// selPred represents single step predicate.
+type selPred struct {
+	attr   bool   // Attribute predicate.
+	name   string // Attribute or child element name.
+	value  string // Expected value.
+	hasVal bool   // Predicate compares value.
+	pos    int    // Position (1 based) for positional predicates.
+}
+
+// CompileSelector parses selector expression.
+func CompileSelector(expr string) (*Selector, error) {
+	p := &selParser{s: expr}
+	sel, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return sel, nil
+}
+
+// MustCompileSelector is like CompileSelector but panics if expression
+// cannot be parsed.
+func MustCompileSelector(expr string) *Selector {
+	sel, err := CompileSelector(expr)
+	if err != nil {
+		panic(err)
+	}
+	return sel
+}
+
+// String returns selector source expression.
+func (s *Selector) String() string {
+	return s.expr
+}
+
+// Select returns all elements matching selector in the tree rooted at el.
+// Returns nil if nothing matches.
+func (s *Selector) Select(el *Element) []*Element {
+	// Document order of elements.
+	order := make(map[*Element]int)
+	_ = el.Walk(func(el *Element, _ int) error {
+		order[el] = len(order)
+		return nil
+	})
+
+	steps := s.steps
+	ctx := []*Element{el}
+	if s.abs {
+		ctx = steps[0].filter(ctx)
+		steps = steps[1:]
+	}
+	for _, st := range steps {
+		if len(ctx) == 0 {
+			return nil
+		}
+		ctx = st.apply(ctx)
+		sort.Slice(ctx, func(i, j int) bool {
+			return order[ctx[i]] < order[ctx[j]]
+		})
+	}
+	if len(ctx) == 0 {
+		return nil
+	}
+	return ctx
+}
+
+// Select returns all elements matching selector expression in the tree
+// rooted at e. See Selector for expression syntax.
+func (e *Element) Select(expr string) ([]*Element, error) {
+	sel, err := CompileSelector(expr)
+	if err != nil {
+		return nil, err
+	}
+	return sel.Select(e), nil
+}
+
+// SelectFirst returns first element matching selector expression in the
+// tree rooted at e or nil if nothing matches.
+func (e *Element) SelectFirst(expr string) (*Element, error) {
+	els, err := e.Select(expr)
+	if err != nil || len(els) == 0 {
+		return nil, err
+	}
+	return els[0], nil
+}
+
+// apply returns elements matched by step in the context of ctx elements.
+func (st selStep) apply(ctx []*Element) []*Element {
+	var out []*Element
+	seen := make(map[*Element]bool)
+	for _, c := range ctx {
+		parents := []*Element{c}
+		if st.desc {
+			parents = parents[:0]
+			_ = c.Walk(func(el *Element, _ int) error {
+				parents = append(parents, el)
+				return nil
+			})
+		}
+		for _, p := range parents {
+			for _, el := range st.filter(p.children) {
+				if !seen[el] {
+					seen[el] = true
+					out = append(out, el)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// filter returns elements matching step name and predicates.
+func (st selStep) filter(els []*Element) []*Element {
+	var out []*Element
+	for _, el := range els {
+		if st.name == "*" || el.MatchName(st.name) {
+			out = append(out, el)
+		}
+	}
+	for _, pr := range st.preds {
+		out = pr.filter(out)
+	}
+	return out
+}
+
+// filter returns elements matching predicate.
+func (pr selPred) filter(els []*Element) []*Element {
+	if pr.pos > 0 {
+		if pr.pos > len(els) {
+			return nil
+		}
+		return els[pr.pos-1 : pr.pos]
+	}
+
+	var out []*Element
+	for _, el := range els {
+		if pr.match(el) {
+			out = append(out, el)
+		}
+	}
+	return out
+}
+
+// match returns true if element matches attribute or child predicate.
+func (pr selPred) match(el *Element) bool {
+	if pr.attr {
+		if !el.HasAttribute(pr.name) {
+			return false
+		}
+		return !pr.hasVal || el.Attribute(pr.name).Value == pr.value
+	}
+
+	for _, ch := range el.children {
+		if !ch.MatchName(pr.name) {
+			continue
+		}
+		if !pr.hasVal || ch.ContentString() == pr.value {
+			return true
+		}
+	}
+	return false
+}
+
+// selParser parses selector expressions.
+type selParser struct {
+	s string
+	i int
+}
+
+// errorf returns ErrInvalidSelector wrapped with message and position.
+func (p *selParser) errorf(format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	return fmt.Errorf("%w %q: %s at position %d", ErrInvalidSelector, p.s, msg, p.i)
+}
+
+// parse parses whole expression.
+func (p *selParser) parse() (*Selector, error) {
+	sel := &Selector{expr: p.s}
+	if p.s == "" {
+		return nil, p.errorf("empty expression")
+	}
+
+	desc := false
+	switch {
+	case p.consume("//"):
+		desc = true
+	case p.consume("/"):
+		sel.abs = true
+	}
+
+	for {
+		st, err := p.step()
+		if err != nil {
+			return nil, err
+		}
+		st.desc = desc
+		sel.steps = append(sel.steps, st)
+
+		if p.i == len(p.s) {
+			return sel, nil
+		}
+
+		switch {
+		case p.consume("//"):
+			desc = true
+		case p.consume("/"):
+			desc = false
+		default:
+			return nil, p.errorf("unexpected character %q", p.s[p.i])
+		}
+	}
+}
+
+// step parses single step.
+func (p *selParser) step() (selStep, error) {
+	var st selStep
+	if p.consume("*") {
+		st.name = "*"
+	} else {
+		st.name = p.name()
+		if st.name == "" {
+			return st, p.errorf("expected element name")
+		}
+	}
+
+	for p.consume("[") {
+		pr, err := p.pred()
+		if err != nil {
+			return st, err
+		}
+		st.preds = append(st.preds, pr)
+	}
+	return st, nil
+}
+
+// pred parses predicate after opening bracket.
+func (p *selParser) pred() (selPred, error) {
+	var pr selPred
+	p.space()
+
+	if start := p.i; p.i < len(p.s) && isDigit(p.s[p.i]) {
+		for p.i < len(p.s) && isDigit(p.s[p.i]) {
+			p.i++
+		}
+		pos, err := strconv.Atoi(p.s[start:p.i])
+		if err != nil || pos == 0 {
+			p.i = start
+			return pr, p.errorf("invalid position")
+		}
+		pr.pos = pos
+		return pr, p.predEnd()
+	}
+
+	pr.attr = p.consume("@")
+	pr.name = p.name()
+	if pr.name == "" {
+		if pr.attr {
+			return pr, p.errorf("expected attribute name")
+		}
+		return pr, p.errorf("expected element name or position")
+	}
+	p.space()
+
+	if p.consume("=") {
+		p.space()
+		val, err := p.quoted()
+		if err != nil {
+			return pr, err
+		}
+		pr.value = val
+		pr.hasVal = true
+	}
+	return pr, p.predEnd()
+}
+
+// predEnd consumes optional whitespace and closing bracket.
+func (p *selParser) predEnd() error {
+	p.space()
+	if !p.consume("]") {
+		return p.errorf("expected ]")
+	}
+	return nil
+}
+
+// quoted parses single or double quoted string.
+func (p *selParser) quoted() (string, error) {
+	if p.i == len(p.s) || (p.s[p.i] != '\'' && p.s[p.i] != '"') {
+		return "", p.errorf("expected quoted value")
+	}
+	q := p.s[p.i]
+	start := p.i + 1
+	for j := start; j < len(p.s); j++ {
+		if p.s[j] == q {
+			p.i = j + 1
+			return p.s[start:j], nil
+		}
+	}
+	return "", p.errorf("unterminated quoted value")
+}
+
+// name parses XML name.
+func (p *selParser) name() string {
+	start := p.i
+	for p.i < len(p.s) {
+		ch := p.s[p.i]
+		if ch == '/' || ch == '[' || ch == ']' || ch == '=' || ch == '@' ||
+			ch == '*' || ch == '\'' || ch == '"' || isSpace(ch) {
+			break
+		}
+		p.i++
+	}
+	return p.s[start:p.i]
+}
+
+// space skips whitespace.
+func (p *selParser) space() {
+	for p.i < len(p.s) && isSpace(p.s[p.i]) {
+		p.i++
+	}
+}
+
+// consume consumes tok if it's at the current position.
+func (p *selParser) consume(tok string) bool {
+	if len(p.s)-p.i >= len(tok) && p.s[p.i:p.i+len(tok)] == tok {
+		p.i += len(tok)
+		return true
+	}
+	return false
+}
+
+// isDigit returns true for ASCII digits.
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
@@ -0,0 +1,145 @@
+package kml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// selectorTree returns tree used in selector tests.
+func selectorTree() *Element {
+	return KML(
+		Document(
+			Name("doc"),
+			Folder(
+				AttrID("fld_0"),
+				Name("folder 0"),
+				Placemark(AttrID("pm_0"), Name("x"), StyleURL("#sty_0")),
+				Folder(
+					AttrID("fld_1"),
+					Placemark(AttrID("pm_1"), Name("y"), StyleURL("#sty_1")),
+					Placemark(AttrID("pm_2"), Name("x"), StyleURL("#sty_2")),
+				),
+			),
+			Folder(
+				AttrID("fld_2"),
+				Placemark(AttrID("pm_3"), Name("x"), StyleURL("#sty_3")),
+			),
+		),
+	)
+}
+
+// ids returns IDs of elements.
+func ids(els []*Element) []string {
+	var out []string
+	for _, el := range els {
+		out = append(out, el.ID()+el.ContentString())
+	}
+	return out
+}
+
+func Test_Element_Select(t *testing.T) {
+	tt := []struct {
+		expr string
+		exp  []string
+	}{
+		{"Document", []string{""}},
+		{"Document/Folder", []string{"fld_0", "fld_2"}},
+		{"Document/Folder[@id='fld_0']//Placemark[name='x']/styleUrl", []string{"#sty_0", "#sty_2"}},
+		{"Document/Folder[@id=\"fld_2\"]/Placemark", []string{"pm_3"}},
+		{"//Placemark", []string{"pm_0", "pm_1", "pm_2", "pm_3"}},
+		{"//Placemark[name='x']", []string{"pm_0", "pm_2", "pm_3"}},
+		{"//Placemark[2]", []string{"pm_2"}},
+		{"//Folder[@id='fld_1']/Placemark[name='x'][1]", []string{"pm_2"}},
+		{"//Placemark[1]", []string{"pm_0", "pm_1", "pm_3"}},
+		{"//Folder//Placemark", []string{"pm_0", "pm_1", "pm_2", "pm_3"}},
+		{"//Folder[@id]", []string{"fld_0", "fld_1", "fld_2"}},
+		{"//Folder[name]", []string{"fld_0"}},
+		{"//Folder[ @id = 'fld_1' ]/*", []string{"pm_1", "pm_2"}},
+		{"Document/*[2]", []string{"fld_0"}},
+		{"/kml/Document/name", []string{"doc"}},
+		{"/Document", nil},
+		{"Folder", nil},
+		{"//Placemark[5]", nil},
+		{"//Placemark[name='z']", nil},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.expr, func(t *testing.T) {
+			// --- Given ---
+			root := selectorTree()
+
+			// --- When ---
+			got, err := root.Select(tc.expr)
+
+			// --- Then ---
+			assert.NoError(t, err)
+			assert.Exactly(t, tc.exp, ids(got))
+		})
+	}
+}
+
+func Test_Element_Select_Errors(t *testing.T) {
+	tt := []struct {
+		expr string
+		exp  string
+	}{
+		{"", `invalid selector "": empty expression at position 0`},
+		{"Document/", `invalid selector "Document/": expected element name at position 9`},
+		{"Document///Folder", `invalid selector "Document///Folder": expected element name at position 10`},
+		{"Document[", `invalid selector "Document[": expected element name or position at position 9`},
+		{"Document[@]", `invalid selector "Document[@]": expected attribute name at position 10`},
+		{"Document[0]", `invalid selector "Document[0]": invalid position at position 9`},
+		{"Document[@id='x'", `invalid selector "Document[@id='x'": expected ] at position 16`},
+		{"Document[@id=x]", `invalid selector "Document[@id=x]": expected quoted value at position 13`},
+		{"Document[@id='x]", `invalid selector "Document[@id='x]": unterminated quoted value at position 13`},
+		{"Document]", `invalid selector "Document]": unexpected character ']' at position 8`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.expr, func(t *testing.T) {
+			// --- Given ---
+			root := selectorTree()
+
+			// --- When ---
+			got, err := root.Select(tc.expr)
+
+			// --- Then ---
+			assert.ErrorIs(t, err, ErrInvalidSelector)
+			assert.EqualError(t, err, tc.exp)
+			assert.Nil(t, got)
+		})
+	}
+}
+
+func Test_Element_SelectFirst(t *testing.T) {
+	// --- Given ---
+	root := selectorTree()
+
+	// --- When ---
+	got, err := root.SelectFirst("//Placemark[name='x']")
+
+	// --- Then ---
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Exactly(t, "pm_0", got.ID())
+}
+
+func Test_Selector_Reuse(t *testing.T) {
+	// --- Given ---
+	sel := MustCompileSelector("Folder/name")
+
+	// --- When ---
+	got0 := sel.Select(selectorTree().ChildAtIdx(0))
+	got1 := sel.Select(selectorTree())
+
+	// --- Then ---
+	assert.Exactly(t, "Folder/name", sel.String())
+	assert.Exactly(t, []string{"folder 0"}, ids(got0))
+	assert.Nil(t, got1)
+}
+
+func Test_MustCompileSelector_Panics(t *testing.T) {
+	assert.Panics(t, func() { MustCompileSelector("[") })
+}
@@ -0,0 +1,154 @@
+package kml
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// KML feature element names which are yielded by FeatureReader.
+var streamFeatures = map[string]bool{
+	ElemPlacemark:   true,
+	"GroundOverlay": true,
+	"NetworkLink":   true,
+	"PhotoOverlay":  true,
+	"ScreenOverlay": true,
+	"Tour":          true, // gx:Tour
+}
+
+// Container describes Document or Folder enclosing a feature.
+type Container struct {
+	Kind string // Container element local name (Document or Folder).
+	ID   string // Value of the id attribute.
+	Name string // Content of the name child element.
+}
+
+// Feature represents feature element read by FeatureReader.
+type Feature struct {
+	// Fully built feature element.
+	Element *Element
+
+	// Containers enclosing the feature starting from the outermost one.
+	Path []Container
+}
+
+// FeatureReader reads KML features one by one without building the
+// whole document tree in memory.
+type FeatureReader struct {
+	dec  *xml.Decoder
+	path []Container
+	root bool // Set to true when kml root element was read.
+}
+
+// NewFeatureReader returns new instance of FeatureReader reading from r.
+func NewFeatureReader(r io.Reader) *FeatureReader {
+	return &FeatureReader{
+		dec: xml.NewDecoder(r),
+	}
+}
+
+// Next returns next feature from the stream. Returns io.EOF when there are
+// no more features.
+func (fr *FeatureReader) Next() (*Feature, error) {
+	for {
+		pos := decoderPos(fr.dec)
+		tok, err := fr.dec.Token()
+		if err != nil {
+			if err == io.EOF {
+				return nil, err
+			}
+			return nil, fr.parseError(pos, "", err)
+		}
+
+		switch el := tok.(type) {
+		case xml.StartElement:
+			name := el.Name.Local
+			switch {
+			case !fr.root:
+				if name != ElemKML {
+					return nil, newParseError(nil, pos, startTag(el), ErrInvalidKML)
+				}
+				fr.root = true
+
+			case name == ElemDocument || name == ElemFolder:
+				fr.path = append(fr.path, Container{
+					Kind: name,
+					ID:   attrValue(el.Attr, "id"),
+				})
+
+			case streamFeatures[name]:
+				ch := NewElementNS(el.Name.Space, name)
+				ch.pos = pos
+				if err := ch.UnmarshalXML(fr.dec, el); err != nil {
+					if pErr, ok := err.(*ParseError); ok {
+						pErr.Path = fr.containerPath() + pErr.Path
+					}
+					return nil, err
+				}
+				path := make([]Container, len(fr.path))
+				copy(path, fr.path)
+				return &Feature{Element: ch, Path: path}, nil
+
+			case name == ElemName && len(fr.path) > 0:
+				ch := NewElementNS(el.Name.Space, name)
+				if err := ch.UnmarshalXML(fr.dec, el); err != nil {
+					return nil, fr.parseError(pos, "", err)
+				}
+				fr.path[len(fr.path)-1].Name = ch.ContentString()
+
+			default:
+				if err := fr.dec.Skip(); err != nil {
+					return nil, fr.parseError(pos, startTag(el), err)
+				}
+			}
+
+		case xml.EndElement:
+			name := el.Name.Local
+			if (name == ElemDocument || name == ElemFolder) && len(fr.path) > 0 {
+				fr.path = fr.path[:len(fr.path)-1]
+			}
+		}
+	}
+}
+
+// attrValue returns value of the attribute with local name or empty
+// string if attribute does not exist.
+func attrValue(attrs []xml.Attr, name string) string {
+	for _, atr := range attrs {
+		if atr.Name.Local == name {
+			return atr.Value
+		}
+	}
+	return ""
+}
+
+// parseError returns ParseError with path of currently open containers.
+func (fr *FeatureReader) parseError(pos Position, tok string, err error) error {
+	if pErr, ok := err.(*ParseError); ok {
+		return pErr
+	}
+	return &ParseError{
+		Path:  fr.containerPath(),
+		Pos:   pos,
+		Token: tok,
+		Err:   err,
+	}
+}
+
+// containerPath returns path of currently open containers.
+func (fr *FeatureReader) containerPath() string {
+	if !fr.root {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("/")
+	b.WriteString(ElemKML)
+	for _, c := range fr.path {
+		b.WriteString("/")
+		b.WriteString(c.Kind)
+		if c.ID != "" {
+			b.WriteString("[" + c.ID + "]")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,100 @@
+package kml
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	kit "github.com/rzajac/testkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_FeatureReader_Next(t *testing.T) {
+	// --- Given ---
+	fr := NewFeatureReader(kit.OpenFile(t, "testdata/features.kml"))
+
+	// --- When ---
+	var got []*Feature
+	for {
+		f, err := fr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		got = append(got, f)
+	}
+
+	// --- Then ---
+	require.Len(t, got, 4)
+
+	doc := Container{Kind: ElemDocument, ID: "doc_0", Name: "document name"}
+
+	assert.Exactly(t, ElemPlacemark, got[0].Element.LocalName())
+	assert.Exactly(t, "pm_0", got[0].Element.ID())
+	assert.Exactly(t, int64(286), got[0].Element.Offset())
+	assert.Exactly(t, "top placemark", got[0].Element.ChildByName(ElemName).ContentString())
+	assert.Exactly(t, "1,2", got[0].Element.ChildByName(ElemPoint).ChildByName(ElemCoordinates).ContentString())
+	assert.Exactly(t, []Container{doc}, got[0].Path)
+
+	assert.Exactly(t, "pm_1", got[1].Element.ID())
+	assert.Exactly(t, []Container{
+		doc,
+		{Kind: ElemFolder, ID: "fld_0", Name: "folder 0"},
+		{Kind: ElemFolder, ID: "fld_1", Name: "folder 1"},
+	}, got[1].Path)
+
+	assert.Exactly(t, "GroundOverlay", got[2].Element.LocalName())
+	assert.Exactly(t, "go_0", got[2].Element.ID())
+	assert.Exactly(t, []Container{
+		doc,
+		{Kind: ElemFolder, ID: "fld_0", Name: "folder 0"},
+	}, got[2].Path)
+
+	assert.Exactly(t, "pm_2", got[3].Element.ID())
+	assert.Exactly(t, 0, got[3].Element.ChildCnt())
+	assert.Exactly(t, []Container{
+		doc,
+		{Kind: ElemFolder, Name: "folder 2"},
+	}, got[3].Path)
+}
+
+func Test_FeatureReader_Next_NotKML(t *testing.T) {
+	// --- Given ---
+	fr := NewFeatureReader(strings.NewReader("<gpx></gpx>"))
+
+	// --- When ---
+	f, err := fr.Next()
+
+	// --- Then ---
+	assert.Nil(t, f)
+	assert.ErrorIs(t, err, ErrInvalidKML)
+	assert.EqualError(t, err, "1:1: at <gpx>: not KML")
+}
+
+func Test_FeatureReader_Next_Empty(t *testing.T) {
+	// --- Given ---
+	fr := NewFeatureReader(strings.NewReader("<kml><Document></Document></kml>"))
+
+	// --- When ---
+	f, err := fr.Next()
+
+	// --- Then ---
+	assert.Nil(t, f)
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func Test_FeatureReader_Next_Truncated(t *testing.T) {
+	// --- Given ---
+	fr := NewFeatureReader(strings.NewReader(`<kml><Document id="doc"><Folder><Placemark><name>`))
+
+	// --- When ---
+	f, err := fr.Next()
+
+	// --- Then ---
+	assert.Nil(t, f)
+	assert.ErrorIs(t, err, ErrInvalidKML)
+	assert.NotErrorIs(t, err, io.EOF)
+	exp := "1:50: /kml/Document[doc]/Folder/Placemark/name: XML syntax error: unexpected EOF"
+	assert.EqualError(t, err, exp)
+}
@@ -0,0 +1,204 @@
+package kml
+
+import (
+	"embed"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/rzajac/kml/internal/xsd"
+)
+
+// ErrSchemaViolation is returned when element violates KML schema.
+var ErrSchemaViolation = errors.New("schema violation")
+
+//go:embed xsd/*.xsd
+var xsdFS embed.FS
+
+var (
+	schemaOnce sync.Once
+	schemaSet  *xsd.Set
+)
+
+// kmlSchema returns the bundled OGC KML 2.2 and Google extensions
+// schemas. It panics if bundled schemas cannot be loaded.
+func kmlSchema() *xsd.Set {
+	schemaOnce.Do(func() {
+		var err error
+		schemaSet, err = xsd.Load(
+			xsdFS,
+			"xsd/atom-author-link.xsd",
+			"xsd/ogckml22.xsd",
+			"xsd/kml22gx.xsd",
+		)
+		if err != nil {
+			panic(err)
+		}
+	})
+	return schemaSet
+}
+
+// ValidationError describes schema violation.
+type ValidationError struct {
+	Name string   // Local name of the offending element.
+	Path string   // Absolute path of the offending element.
+	Pos  Position // Position of the offending element.
+	Err  error    // Violation.
+}
+
+// newValidationError returns ValidationError for element el.
+func newValidationError(el *Element, format string, args ...interface{}) ValidationError {
+	return ValidationError{
+		Name: el.LocalName(),
+		Path: el.Path(),
+		Pos:  el.Position(),
+		Err:  fmt.Errorf("%w: %s", ErrSchemaViolation, fmt.Sprintf(format, args...)),
+	}
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", e.Pos, e.Path, e.Err)
+}
+
+// Unwrap returns underlying error.
+func (e ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Validate validates the element tree rooted at el against the bundled
+// OGC KML 2.2, Google extensions and Atom schemas. It checks that elements
+// are allowed in their parents, appear in the order required by the schema
+// and do not exceed allowed number of occurrences, required elements and
+// attributes are present and values are valid for their types. Elements
+// matched by lax wildcards or from namespaces without bundled schema are
+// not validated. Returns nil when element tree is valid.
+func Validate(el *Element) []ValidationError {
+	set := kmlSchema()
+	decl := set.Elements[schemaName(el)]
+	if decl == nil {
+		return []ValidationError{newValidationError(el, "unknown element %s", el.qualifiedName())}
+	}
+	var errs []ValidationError
+	validateElement(set, el, decl, &errs)
+	return errs
+}
+
+// schemaName returns qualified schema name of the element. Elements
+// without namespace and elements from KML versions before 2.2 are
+// validated as OGC KML 2.2 elements.
+func schemaName(el *Element) xml.Name {
+	n := el.se.Name
+	if isKMLNamespace(n.Space) {
+		n.Space = NsKML
+	}
+	return n
+}
+
+// schemaSlot returns position of child element ch in the content model of
+// element el or -1 if the position is not known.
+func schemaSlot(el, ch *Element) int {
+	set := kmlSchema()
+	decl := set.Elements[schemaName(el)]
+	if decl == nil || decl.Complex == nil {
+		return -1
+	}
+	return set.Slot(decl.Complex, schemaName(ch))
+}
+
+// validateElement validates element el against its declaration and
+// appends violations to errs.
+func validateElement(set *xsd.Set, el *Element, decl *xsd.Element, errs *[]ValidationError) {
+	if decl.Abstract {
+		*errs = append(*errs, newValidationError(el, "element %s is abstract", el.LocalName()))
+		return
+	}
+
+	validateAttributes(set, el, decl, errs)
+
+	switch {
+	case decl.Simple != nil:
+		validateSimpleContent(set, el, decl.Simple, errs)
+
+	case decl.Complex == nil:
+		// Any type.
+
+	case decl.Complex.Simple != nil:
+		validateSimpleContent(set, el, decl.Complex.Simple, errs)
+
+	default:
+		if len(el.content) > 0 {
+			*errs = append(*errs, newValidationError(el, "element %s must not have text content", el.LocalName()))
+		}
+
+		names := make([]xml.Name, len(el.children))
+		for i, ch := range el.children {
+			names[i] = schemaName(ch)
+		}
+
+		decls, cErrs := set.Match(decl.Complex, names)
+		for _, cErr := range cErrs {
+			target := el
+			if cErr.Index < len(el.children) {
+				target = el.children[cErr.Index]
+			}
+			*errs = append(*errs, newValidationError(target, "%s", cErr.Msg))
+		}
+
+		for i, ch := range el.children {
+			if decls[i] != nil {
+				validateElement(set, ch, decls[i], errs)
+			}
+		}
+	}
+}
+
+// validateSimpleContent validates content of element with simple type.
+func validateSimpleContent(set *xsd.Set, el *Element, st *xsd.SimpleType, errs *[]ValidationError) {
+	if len(el.children) > 0 {
+		*errs = append(*errs, newValidationError(el, "element %s must not have child elements", el.LocalName()))
+		return
+	}
+	if err := set.ValidateValue(st, string(el.content)); err != nil {
+		*errs = append(*errs, newValidationError(el, "%s", err))
+	}
+}
+
+// validateAttributes validates element attributes. Namespace declarations
+// and attributes with namespace prefix are ignored.
+func validateAttributes(set *xsd.Set, el *Element, decl *xsd.Element, errs *[]ValidationError) {
+	var attrs []*xsd.Attribute
+	if decl.Complex != nil {
+		attrs = decl.Complex.Attrs
+	}
+
+	for _, a := range el.se.Attr {
+		if a.Name.Space != "" || a.Name.Local == "xmlns" || strings.Contains(a.Name.Local, ":") {
+			continue
+		}
+		var ad *xsd.Attribute
+		for _, d := range attrs {
+			if d.Name == a.Name.Local {
+				ad = d
+				break
+			}
+		}
+		if ad == nil {
+			*errs = append(*errs, newValidationError(el, "attribute %s is not allowed", a.Name.Local))
+			continue
+		}
+		if ad.Type == nil {
+			continue
+		}
+		if err := set.ValidateValue(ad.Type, a.Value); err != nil {
+			*errs = append(*errs, newValidationError(el, "attribute %s: %s", a.Name.Local, err))
+		}
+	}
+
+	for _, d := range attrs {
+		if d.Required && !el.HasAttribute(d.Name) {
+			*errs = append(*errs, newValidationError(el, "missing required attribute %s", d.Name))
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package kml
+
+import (
+	"encoding/xml"
+	"errors"
+	"os"
+	"testing"
+
+	kit "github.com/rzajac/testkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Validate_Example(t *testing.T) {
+	// --- Given ---
+	fil, err := os.Open("testdata/example.kml")
+	require.NoError(t, err)
+	defer fil.Close()
+
+	el, err := Parse(fil)
+	require.NoError(t, err)
+
+	// --- When ---
+	errs := Validate(el)
+
+	// --- Then ---
+	assert.Nil(t, errs)
+}
+
+func Test_Validate_Builders(t *testing.T) {
+	// --- Given ---
+	doc := KML(
+		Document(
+			Name("doc"),
+			Style("sty_0", LabelStyle(Color("ff0000ff"), Scale(1.5))),
+			Placemark(
+				Name("pm"),
+				StyleURL("#sty_0"),
+				Point(Extrude(true), AltitudeMode("absolute"), Coordinates("1,2,3")),
+			),
+		),
+	)
+
+	// --- When ---
+	errs := Validate(doc)
+
+	// --- Then ---
+	assert.Nil(t, errs)
+}
+
+func Test_Validate_Violations(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		elm *Element
+		exp string
+	}{
+		{
+			"invalid enumeration",
+			Placemark(Point(AltitudeMode("floating"), Coordinates("1,2"))),
+			"0:0: /kml/Placemark/Point/altitudeMode: schema violation: " +
+				"value \"floating\" is not one of clampToGround, relativeToGround, absolute",
+		},
+		{
+			"invalid color",
+			Placemark(Style("sty_0", LabelStyle(Color("red")))),
+			"0:0: /kml/Placemark/Style[sty_0]/LabelStyle/color: schema violation: " +
+				"invalid hexBinary value \"red\"",
+		},
+		{
+			"wrong order",
+			Placemark(StyleURL("#sty_0"), Name("pm")),
+			"0:0: /kml/Placemark/name: schema violation: element name must appear before element styleUrl",
+		},
+		{
+			"element not allowed",
+			Placemark(Document()),
+			"0:0: /kml/Placemark/Document: schema violation: element Document is not allowed",
+		},
+		{
+			"too many elements",
+			Placemark(Point(Coordinates("1,2"), Coordinates("3,4"))),
+			"0:0: /kml/Placemark/Point/coordinates[2]: schema violation: too many coordinates elements",
+		},
+		{
+			"unknown attribute",
+			Placemark(Point(xml.Attr{Name: xml.Name{Local: "size"}, Value: "1"}, Coordinates("1,2"))),
+			"0:0: /kml/Placemark/Point: schema violation: attribute size is not allowed",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			errs := Validate(KML(tc.elm))
+
+			// --- Then ---
+			require.Len(t, errs, 1)
+			assert.True(t, errors.Is(errs[0], ErrSchemaViolation))
+			assert.EqualError(t, errs[0], tc.exp)
+		})
+	}
+}
+
+func Test_Validate_TextInElementOnlyContent(t *testing.T) {
+	// --- Given ---
+	pnt := Point()
+	pnt.SetContent([]byte("text"))
+
+	// --- When ---
+	errs := Validate(KML(Placemark(pnt)))
+
+	// --- Then ---
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "0:0: /kml/Placemark/Point: schema violation: element Point must not have text content")
+}
+
+func Test_Validate_UnknownRoot(t *testing.T) {
+	// --- When ---
+	errs := Validate(NewElement("gpx"))
+
+	// --- Then ---
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "0:0: /gpx: schema violation: unknown element gpx")
+}
+
+func Test_Validate_Namespaces(t *testing.T) {
+	// --- Given ---
+	root, err := Parse(kit.OpenFile(t, "testdata/namespaces.kml"))
+	require.NoError(t, err)
+
+	// --- When ---
+	errs := Validate(root)
+
+	// --- Then ---
+	assert.Nil(t, errs)
+}
+
+func Test_Validate_GxElements(t *testing.T) {
+	// --- Given ---
+	valid := GxTrack(GxAltitudeMode(GxAltitudeModeClampToSeaFloor))
+	invalid := GxTrack(AltitudeMode(GxAltitudeModeClampToSeaFloor))
+
+	// --- When ---
+	errs := Validate(KML(Document(Placemark(valid), Placemark(invalid))))
+
+	// --- Then ---
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "0:0: /kml/Document/Placemark[2]/gx:Track/altitudeMode: schema violation: "+
+		"value \"clampToSeaFloor\" is not one of clampToGround, relativeToGround, absolute")
+}
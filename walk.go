@@ -0,0 +1,89 @@
+package kml
+
+import (
+	"errors"
+)
+
+// SkipSubtree is used as a return value from WalkFunc to indicate that
+// children of the element passed to the call are to be skipped.
+var SkipSubtree = errors.New("skip subtree")
+
+// StopWalk is used as a return value from WalkFunc to indicate that
+// the walk should stop. Walk returns nil in this case.
+var StopWalk = errors.New("stop walk")
+
+// WalkFunc is the type of the function called by Walk to visit each
+// element. The depth is zero for the element Walk was called on.
+type WalkFunc func(el *Element, depth int) error
+
+// Walk walks the element tree rooted at e in document order calling fn for
+// each element including e. If fn returns SkipSubtree the children of the
+// element are not visited, if it returns StopWalk or any other error the
+// walk stops. Walk returns error returned by fn except StopWalk.
+func (e *Element) Walk(fn WalkFunc) error {
+	if err := e.walk(fn, 0); err != nil && err != StopWalk {
+		return err
+	}
+	return nil
+}
+
+// walk recursively visits elements.
+func (e *Element) walk(fn WalkFunc, depth int) error {
+	if err := fn(e, depth); err != nil {
+		if err == SkipSubtree {
+			return nil
+		}
+		return err
+	}
+	for _, ch := range e.children {
+		if err := ch.walk(fn, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindByID returns first element in the tree rooted at e with ID. Returns
+// nil if element does not exist or id is empty.
+func (e *Element) FindByID(id string) *Element {
+	if id == "" {
+		return nil
+	}
+	return e.findFirst(func(el *Element) bool { return el.ID() == id })
+}
+
+// FindFirst returns first element in the tree rooted at e with name.
+// Returns nil if element does not exist. See MatchName for supported name
+// forms.
+func (e *Element) FindFirst(name string) *Element {
+	qn := parseName(name)
+	return e.findFirst(func(el *Element) bool { return sameName(el.se.Name, qn) })
+}
+
+// FindAll returns all elements in the tree rooted at e with name in
+// document order. See MatchName for supported name forms.
+func (e *Element) FindAll(name string) []*Element {
+	qn := parseName(name)
+	var els []*Element
+	_ = e.Walk(func(el *Element, _ int) error {
+		if sameName(el.se.Name, qn) {
+			els = append(els, el)
+		}
+		return nil
+	})
+	return els
+}
+
+// findFirst returns first element in the tree rooted at e matching
+// predicate or nil.
+func (e *Element) findFirst(match func(el *Element) bool) *Element {
+	var found *Element
+	_ = e.Walk(func(el *Element, _ int) error {
+		if match(el) {
+			found = el
+			return StopWalk
+		}
+		return nil
+	})
+	return found
+}
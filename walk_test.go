@@ -0,0 +1,161 @@
+package kml
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// walkTree returns tree used in walk tests.
+func walkTree() *Element {
+	return KML(
+		Document(
+			AttrID("doc"),
+			Name("doc name"),
+			Style("sty_0"),
+			Folder(
+				AttrID("fld_0"),
+				Name("fld name"),
+				Placemark(AttrID("pm_0"), Name("pm 0")),
+				Folder(
+					AttrID("fld_1"),
+					Placemark(AttrID("pm_1"), Name("pm 1")),
+				),
+			),
+			Placemark(AttrID("pm_2")),
+		),
+	)
+}
+
+func Test_Element_Walk(t *testing.T) {
+	// --- Given ---
+	root := walkTree()
+
+	// --- When ---
+	var got []string
+	err := root.Walk(func(el *Element, depth int) error {
+		got = append(got, fmt.Sprintf("%s:%s:%d", el.LocalName(), el.ID(), depth))
+		return nil
+	})
+
+	// --- Then ---
+	assert.NoError(t, err)
+	exp := []string{
+		"kml::0",
+		"Document:doc:1",
+		"name::2",
+		"Style:sty_0:2",
+		"Folder:fld_0:2",
+		"name::3",
+		"Placemark:pm_0:3",
+		"name::4",
+		"Folder:fld_1:3",
+		"Placemark:pm_1:4",
+		"name::5",
+		"Placemark:pm_2:2",
+	}
+	assert.Exactly(t, exp, got)
+}
+
+func Test_Element_Walk_SkipSubtree(t *testing.T) {
+	// --- Given ---
+	root := walkTree()
+
+	// --- When ---
+	var got []string
+	err := root.Walk(func(el *Element, depth int) error {
+		got = append(got, el.ID())
+		if el.LocalName() == ElemFolder || el.LocalName() == ElemName {
+			return SkipSubtree
+		}
+		return nil
+	})
+
+	// --- Then ---
+	assert.NoError(t, err)
+	assert.Exactly(t, []string{"", "doc", "", "sty_0", "fld_0", "pm_2"}, got)
+}
+
+func Test_Element_Walk_StopWalk(t *testing.T) {
+	// --- Given ---
+	root := walkTree()
+
+	// --- When ---
+	var got []string
+	err := root.Walk(func(el *Element, depth int) error {
+		got = append(got, el.ID())
+		if el.ID() == "pm_0" {
+			return StopWalk
+		}
+		return nil
+	})
+
+	// --- Then ---
+	assert.NoError(t, err)
+	assert.Exactly(t, []string{"", "doc", "", "sty_0", "fld_0", "", "pm_0"}, got)
+}
+
+func Test_Element_Walk_Error(t *testing.T) {
+	// --- Given ---
+	root := walkTree()
+	myErr := errors.New("my error")
+
+	// --- When ---
+	var cnt int
+	err := root.Walk(func(el *Element, depth int) error {
+		cnt++
+		if depth == 2 {
+			return myErr
+		}
+		return nil
+	})
+
+	// --- Then ---
+	assert.ErrorIs(t, err, myErr)
+	assert.Exactly(t, 3, cnt)
+}
+
+func Test_Element_FindByID(t *testing.T) {
+	// --- Given ---
+	root := walkTree()
+
+	// --- When ---
+	got := root.FindByID("pm_1")
+
+	// --- Then ---
+	require.NotNil(t, got)
+	assert.Exactly(t, "pm 1", got.ChildByName(ElemName).ContentString())
+	assert.Nil(t, root.FindByID("not_existing"))
+	assert.Nil(t, root.FindByID(""))
+}
+
+func Test_Element_FindFirst(t *testing.T) {
+	// --- Given ---
+	root := walkTree()
+
+	// --- When ---
+	got := root.FindFirst(ElemPlacemark)
+
+	// --- Then ---
+	require.NotNil(t, got)
+	assert.Exactly(t, "pm_0", got.ID())
+	assert.Nil(t, root.FindFirst(ElemPoint))
+}
+
+func Test_Element_FindAll(t *testing.T) {
+	// --- Given ---
+	root := walkTree()
+
+	// --- When ---
+	got := root.FindAll(ElemPlacemark)
+
+	// --- Then ---
+	require.Len(t, got, 3)
+	assert.Exactly(t, "pm_0", got[0].ID())
+	assert.Exactly(t, "pm_1", got[1].ID())
+	assert.Exactly(t, "pm_2", got[2].ID())
+	assert.Nil(t, root.FindAll(ElemPoint))
+}
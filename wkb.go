@@ -0,0 +1,425 @@
+package kml
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrInvalidWKB is returned when Well-Known Binary cannot be parsed.
+var ErrInvalidWKB = errors.New("invalid WKB")
+
+// WKB geometry type codes.
+const (
+	wkbPoint              = 1
+	wkbLineString         = 2
+	wkbPolygon            = 3
+	wkbMultiPoint         = 4
+	wkbMultiLineString    = 5
+	wkbMultiPolygon       = 6
+	wkbGeometryCollection = 7
+)
+
+// EWKB type flags.
+const (
+	ewkbZ    = 0x80000000
+	ewkbM    = 0x40000000
+	ewkbSRID = 0x20000000
+)
+
+// wkbTypes maps GeoJSON geometry types to WKB type codes.
+var wkbTypes = map[string]uint32{
+	GeoJSONPoint:              wkbPoint,
+	GeoJSONLineString:         wkbLineString,
+	GeoJSONPolygon:            wkbPolygon,
+	GeoJSONMultiPoint:         wkbMultiPoint,
+	GeoJSONMultiLineString:    wkbMultiLineString,
+	GeoJSONMultiPolygon:       wkbMultiPolygon,
+	GeoJSONGeometryCollection: wkbGeometryCollection,
+}
+
+// WKB returns OGC Well-Known Binary representation of geometry element in
+// little endian byte order. Geometries are converted the same way WKT
+// does, Z variants use ISO type codes (1001, 1002, ...).
+func WKB(el *Element) ([]byte, error) {
+	g, err := simpleFeature(el)
+	if err != nil {
+		return nil, err
+	}
+	w := &wkbWriter{}
+	w.geometry(g, -1, hasZ(g))
+	return w.buf, nil
+}
+
+// EWKB returns PostGIS Extended Well-Known Binary representation of
+// geometry element in little endian byte order. Z variants use the EWKB
+// Z flag. The SRID is included when it's not zero.
+func EWKB(el *Element, srid int) ([]byte, error) {
+	g, err := simpleFeature(el)
+	if err != nil {
+		return nil, err
+	}
+	w := &wkbWriter{ewkb: true}
+	w.geometry(g, srid, hasZ(g))
+	return w.buf, nil
+}
+
+// wkbWriter represents WKB encoder.
+type wkbWriter struct {
+	buf  []byte
+	ewkb bool // Use EWKB type flags instead of ISO type codes.
+}
+
+// uint32 appends unsigned integer.
+func (w *wkbWriter) uint32(v uint32) {
+	w.buf = binary.LittleEndian.AppendUint32(w.buf, v)
+}
+
+// float appends float.
+func (w *wkbWriter) float(v float64) {
+	w.buf = binary.LittleEndian.AppendUint64(w.buf, math.Float64bits(v))
+}
+
+// header appends byte order, type code and SRID. The SRID is appended for
+// EWKB when it's greater than zero.
+func (w *wkbWriter) header(typ uint32, z bool, srid int) {
+	w.buf = append(w.buf, 1) // Little endian.
+	switch {
+	case w.ewkb:
+		if z {
+			typ |= ewkbZ
+		}
+		if srid > 0 {
+			typ |= ewkbSRID
+		}
+		w.uint32(typ)
+		if srid > 0 {
+			w.uint32(uint32(srid))
+		}
+	case z:
+		w.uint32(typ + 1000)
+	default:
+		w.uint32(typ)
+	}
+}
+
+// geometry appends geometry. Positions have altitude when z is true. Sub
+// geometries are written with srid -1 and the same dimension as g.
+func (w *wkbWriter) geometry(g *GeoJSONGeometry, srid int, z bool) {
+	w.header(wkbTypes[g.Type], z, srid)
+
+	switch crs := g.Coordinates.(type) {
+	case []float64:
+		w.position(crs, z)
+
+	case [][]float64:
+		w.uint32(uint32(len(crs)))
+		for _, pos := range crs {
+			if g.Type == GeoJSONMultiPoint {
+				w.header(wkbPoint, z, -1)
+			}
+			w.position(pos, z)
+		}
+
+	case [][][]float64:
+		w.uint32(uint32(len(crs)))
+		for _, line := range crs {
+			if g.Type == GeoJSONMultiLineString {
+				w.header(wkbLineString, z, -1)
+			}
+			w.positions(line, z)
+		}
+
+	case [][][][]float64:
+		w.uint32(uint32(len(crs)))
+		for _, poly := range crs {
+			w.header(wkbPolygon, z, -1)
+			w.uint32(uint32(len(poly)))
+			for _, ring := range poly {
+				w.positions(ring, z)
+			}
+		}
+
+	case nil:
+		w.uint32(uint32(len(g.Geometries)))
+		for _, sub := range g.Geometries {
+			w.geometry(sub, -1, z)
+		}
+	}
+}
+
+// position appends position. Altitude is appended only when z is true.
+func (w *wkbWriter) position(pos []float64, z bool) {
+	w.float(pos[0])
+	w.float(pos[1])
+	if z {
+		var alt float64
+		if len(pos) > 2 {
+			alt = pos[2]
+		}
+		w.float(alt)
+	}
+}
+
+// positions appends number of positions followed by positions.
+func (w *wkbWriter) positions(pos [][]float64, z bool) {
+	w.uint32(uint32(len(pos)))
+	for _, p := range pos {
+		w.position(p, z)
+	}
+}
+
+// ParseWKB parses OGC Well-Known Binary or PostGIS Extended Well-Known
+// Binary and returns KML geometry element and SRID. The SRID is zero when
+// not present. Both byte orders and ISO and EWKB Z, M and ZM variants are
+// supported, M values are dropped. Geometries are converted the same way
+// ParseWKT does.
+func ParseWKB(data []byte) (*Element, int, error) {
+	r := &wkbReader{data: data}
+	g, srid, err := r.geometry(0)
+	if err != nil {
+		return nil, 0, err
+	}
+	if r.i != len(data) {
+		return nil, 0, r.errorf("unexpected %d bytes after geometry", len(data)-r.i)
+	}
+	el, err := kmlGeometry(g)
+	if err != nil {
+		return nil, 0, err
+	}
+	return el, srid, nil
+}
+
+// wkbReader represents WKB decoder.
+type wkbReader struct {
+	data []byte
+	i    int
+	bo   binary.ByteOrder
+}
+
+// errorf returns ErrInvalidWKB wrapped with message and current offset.
+func (r *wkbReader) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: at %d: %s", ErrInvalidWKB, r.i, fmt.Sprintf(format, args...))
+}
+
+// uint32 reads unsigned integer.
+func (r *wkbReader) uint32() (uint32, error) {
+	if len(r.data)-r.i < 4 {
+		return 0, r.errorf("unexpected end of data")
+	}
+	v := r.bo.Uint32(r.data[r.i:])
+	r.i += 4
+	return v, nil
+}
+
+// count reads number of items. Each item takes at least size bytes.
+func (r *wkbReader) count(size int) (int, error) {
+	n, err := r.uint32()
+	if err != nil {
+		return 0, err
+	}
+	if int64(n)*int64(size) > int64(len(r.data)-r.i) {
+		return 0, r.errorf("invalid number of items %d", n)
+	}
+	return int(n), nil
+}
+
+// position reads position with dims values and returns longitude,
+// latitude and altitude when z is true.
+func (r *wkbReader) position(dims int, z bool) ([]float64, error) {
+	if len(r.data)-r.i < dims*8 {
+		return nil, r.errorf("unexpected end of data")
+	}
+	vs := make([]float64, dims)
+	for j := range vs {
+		vs[j] = math.Float64frombits(r.bo.Uint64(r.data[r.i:]))
+		r.i += 8
+	}
+	if z {
+		return vs[:3], nil
+	}
+	return vs[:2], nil
+}
+
+// positions reads number of positions followed by positions.
+func (r *wkbReader) positions(dims int, z bool) ([][]float64, error) {
+	n, err := r.count(dims * 8)
+	if err != nil {
+		return nil, err
+	}
+	pos := make([][]float64, n)
+	for j := range pos {
+		if pos[j], err = r.position(dims, z); err != nil {
+			return nil, err
+		}
+	}
+	return pos, nil
+}
+
+// header reads byte order, type code and optional SRID. Returns base
+// type code, number of values in positions and whether positions have Z.
+func (r *wkbReader) header() (typ uint32, dims int, z bool, srid int, err error) {
+	if r.i >= len(r.data) {
+		return 0, 0, false, 0, r.errorf("unexpected end of data")
+	}
+	switch r.data[r.i] {
+	case 0:
+		r.bo = binary.BigEndian
+	case 1:
+		r.bo = binary.LittleEndian
+	default:
+		return 0, 0, false, 0, r.errorf("invalid byte order %d", r.data[r.i])
+	}
+	r.i++
+
+	code, err := r.uint32()
+	if err != nil {
+		return 0, 0, false, 0, err
+	}
+	z = code&ewkbZ != 0
+	m := code&ewkbM != 0
+	if code&ewkbSRID != 0 {
+		v, err := r.uint32()
+		if err != nil {
+			return 0, 0, false, 0, err
+		}
+		srid = int(int32(v))
+	}
+
+	code &^= ewkbZ | ewkbM | ewkbSRID
+	switch code / 1000 {
+	case 1:
+		z = true
+	case 2:
+		m = true
+	case 3:
+		z, m = true, true
+	}
+	typ = code % 1000
+
+	dims = 2
+	if z {
+		dims++
+	}
+	if m {
+		dims++
+	}
+	return typ, dims, z, srid, nil
+}
+
+// geometry reads geometry. Depth is used to protect against deeply nested
+// geometry collections.
+func (r *wkbReader) geometry(depth int) (*GeoJSONGeometry, int, error) {
+	if depth > 32 {
+		return nil, 0, r.errorf("geometry nested too deep")
+	}
+	typ, dims, z, srid, err := r.header()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	g := &GeoJSONGeometry{}
+	switch typ {
+	case wkbPoint:
+		g.Type = GeoJSONPoint
+		pos, err := r.position(dims, z)
+		if err != nil {
+			return nil, 0, err
+		}
+		if math.IsNaN(pos[0]) && math.IsNaN(pos[1]) {
+			return nil, 0, fmt.Errorf("%w: empty POINT", ErrUnsupportedGeometry)
+		}
+		g.Coordinates = pos
+
+	case wkbLineString:
+		g.Type = GeoJSONLineString
+		if g.Coordinates, err = r.positions(dims, z); err != nil {
+			return nil, 0, err
+		}
+
+	case wkbPolygon:
+		g.Type = GeoJSONPolygon
+		if g.Coordinates, err = r.polygon(dims, z); err != nil {
+			return nil, 0, err
+		}
+
+	case wkbMultiPoint, wkbMultiLineString, wkbMultiPolygon, wkbGeometryCollection:
+		n, err := r.count(5)
+		if err != nil {
+			return nil, 0, err
+		}
+		var subs []*GeoJSONGeometry
+		for j := 0; j < n; j++ {
+			sub, _, err := r.geometry(depth + 1)
+			if err != nil {
+				return nil, 0, err
+			}
+			subs = append(subs, sub)
+		}
+		if g, err = r.multi(typ, subs); err != nil {
+			return nil, 0, err
+		}
+
+	default:
+		return nil, 0, fmt.Errorf("%w: WKB type %d", ErrUnsupportedGeometry, typ)
+	}
+	return g, srid, nil
+}
+
+// polygon reads polygon rings.
+func (r *wkbReader) polygon(dims int, z bool) ([][][]float64, error) {
+	n, err := r.count(4)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("%w: empty POLYGON", ErrUnsupportedGeometry)
+	}
+	rings := make([][][]float64, n)
+	for j := range rings {
+		if rings[j], err = r.positions(dims, z); err != nil {
+			return nil, err
+		}
+	}
+	return rings, nil
+}
+
+// multi returns multi geometry or geometry collection of sub geometries.
+// Sub geometries of multi geometries must have matching type.
+func (r *wkbReader) multi(typ uint32, subs []*GeoJSONGeometry) (*GeoJSONGeometry, error) {
+	want := map[uint32]string{
+		wkbMultiPoint:      GeoJSONPoint,
+		wkbMultiLineString: GeoJSONLineString,
+		wkbMultiPolygon:    GeoJSONPolygon,
+	}[typ]
+	for _, sub := range subs {
+		if want != "" && sub.Type != want {
+			return nil, r.errorf("unexpected %s in multi geometry", sub.Type)
+		}
+	}
+
+	switch typ {
+	case wkbMultiPoint:
+		crs := make([][]float64, len(subs))
+		for j, sub := range subs {
+			crs[j] = sub.Coordinates.([]float64)
+		}
+		return &GeoJSONGeometry{Type: GeoJSONMultiPoint, Coordinates: crs}, nil
+
+	case wkbMultiLineString:
+		crs := make([][][]float64, len(subs))
+		for j, sub := range subs {
+			crs[j] = sub.Coordinates.([][]float64)
+		}
+		return &GeoJSONGeometry{Type: GeoJSONMultiLineString, Coordinates: crs}, nil
+
+	case wkbMultiPolygon:
+		crs := make([][][][]float64, len(subs))
+		for j, sub := range subs {
+			crs[j] = sub.Coordinates.([][][]float64)
+		}
+		return &GeoJSONGeometry{Type: GeoJSONMultiPolygon, Coordinates: crs}, nil
+	}
+	return &GeoJSONGeometry{Type: GeoJSONGeometryCollection, Geometries: subs}, nil
+}
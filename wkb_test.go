@@ -0,0 +1,208 @@
+package kml
+
+import (
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WKB(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		el  *Element
+		exp string
+	}{
+		{"point", Point(Coordinates("1,2")),
+			"0101000000000000000000f03f0000000000000040"},
+		{"point z", Point(Coordinates("1,2,3")),
+			"01e9030000000000000000f03f00000000000000400000000000000840"},
+		{"line", LineString(Coordinates("1,2 3,4")),
+			"010200000002000000000000000000f03f000000000000004000000000000008400000000000001040"},
+		{"multi point", MultiGeometry(Point(Coordinates("1,2"))),
+			"0104000000010000000101000000000000000000f03f0000000000000040"},
+		{"empty", MultiGeometry(), "010700000000000000"},
+		{"collection z", MultiGeometry(Point(Coordinates("1,2,3")), LineString(Coordinates("1,2 3,4"))),
+			"01ef03000002000000" +
+				"01e9030000000000000000f03f00000000000000400000000000000840" +
+				"01ea03000002000000" +
+				"000000000000f03f00000000000000400000000000000000" +
+				"000000000000084000000000000010400000000000000000"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			got, err := WKB(tc.el)
+
+			// --- Then ---
+			require.NoError(t, err)
+			assert.Exactly(t, tc.exp, hex.EncodeToString(got))
+		})
+	}
+}
+
+func Test_EWKB(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		el   *Element
+		srid int
+		exp  string
+	}{
+		{"point", Point(Coordinates("1,2")), 0,
+			"0101000000000000000000f03f0000000000000040"},
+		{"point srid", Point(Coordinates("1,2")), 4326,
+			"0101000020e6100000000000000000f03f0000000000000040"},
+		{"point z srid", Point(Coordinates("1,2,3")), 4326,
+			"01010000a0e6100000000000000000f03f00000000000000400000000000000840"},
+		{"multi point srid", MultiGeometry(Point(Coordinates("1,2"))), 4326,
+			"0104000020e6100000010000000101000000000000000000f03f0000000000000040"},
+		{"collection z srid", MultiGeometry(Point(Coordinates("1,2")), LineString(Coordinates("1,2,3"))), 4326,
+			"01070000a0e610000002000000" +
+				"0101000080000000000000f03f00000000000000400000000000000000" +
+				"010200008001000000000000000000f03f00000000000000400000000000000840"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			got, err := EWKB(tc.el, tc.srid)
+
+			// --- Then ---
+			require.NoError(t, err)
+			assert.Exactly(t, tc.exp, hex.EncodeToString(got))
+		})
+	}
+}
+
+func Test_WKB_Unsupported(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		el *Element
+	}{
+		{"model", Model()},
+		{"track", GxTrack(GxCoord("1 2 3"))},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			got, err := WKB(tc.el)
+
+			// --- Then ---
+			assert.Nil(t, got)
+			assert.ErrorIs(t, err, ErrUnsupportedGeometry)
+			var ge *GeometryError
+			assert.True(t, errors.As(err, &ge), err)
+
+			got, err = EWKB(tc.el, 4326)
+			assert.Nil(t, got)
+			assert.ErrorIs(t, err, ErrUnsupportedGeometry)
+		})
+	}
+}
+
+func Test_ParseWKB(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		wkb  string
+		srid int
+		exp  string
+	}{
+		{"point", "0101000000000000000000f03f0000000000000040", 0,
+			"<Point><coordinates>1,2</coordinates></Point>"},
+		{"point big endian", "00000000013ff00000000000004000000000000000", 0,
+			"<Point><coordinates>1,2</coordinates></Point>"},
+		{"point iso z", "01e9030000000000000000f03f00000000000000400000000000000840", 0,
+			"<Point><coordinates>1,2,3</coordinates></Point>"},
+		{"point iso m", "01d1070000000000000000f03f00000000000000400000000000002240", 0,
+			"<Point><coordinates>1,2</coordinates></Point>"},
+		{"point iso zm", "01b90b0000000000000000f03f000000000000004000000000000008400000000000002240", 0,
+			"<Point><coordinates>1,2,3</coordinates></Point>"},
+		{"point ewkb z srid", "01010000a0e6100000000000000000f03f00000000000000400000000000000840", 4326,
+			"<Point><coordinates>1,2,3</coordinates></Point>"},
+		{"line", "010200000002000000000000000000f03f000000000000004000000000000008400000000000001040", 0,
+			"<LineString><coordinates>1,2 3,4</coordinates></LineString>"},
+		{"multi point", "0104000020e6100000010000000101000000000000000000f03f0000000000000040", 4326,
+			"<MultiGeometry><Point><coordinates>1,2</coordinates></Point></MultiGeometry>"},
+		{"empty", "010700000000000000", 0, "<MultiGeometry></MultiGeometry>"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			data, err := hex.DecodeString(tc.wkb)
+			require.NoError(t, err)
+
+			// --- When ---
+			el, srid, err := ParseWKB(data)
+
+			// --- Then ---
+			require.NoError(t, err)
+			assert.Exactly(t, tc.srid, srid)
+			got, err := xml.Marshal(el)
+			require.NoError(t, err)
+			assert.Exactly(t, tc.exp, string(got))
+		})
+	}
+}
+
+func Test_ParseWKB_Errors(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		wkb string
+		err error
+	}{
+		{"empty data", "", ErrInvalidWKB},
+		{"byte order", "0201000000", ErrInvalidWKB},
+		{"short", "0101000000000000000000f03f", ErrInvalidWKB},
+		{"trailing", "0101000000000000000000f03f000000000000004000", ErrInvalidWKB},
+		{"count", "0102000000ffffffff", ErrInvalidWKB},
+		{"type", "0108000000", ErrUnsupportedGeometry},
+		{"multi type", "01040000000100000001020000000000000000", ErrInvalidWKB},
+		{"empty point", "0101000000000000000000f87f000000000000f87f", ErrUnsupportedGeometry},
+		{"empty polygon", "010300000000000000", ErrUnsupportedGeometry},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- Given ---
+			data, err := hex.DecodeString(tc.wkb)
+			require.NoError(t, err)
+
+			// --- When ---
+			el, srid, err := ParseWKB(data)
+
+			// --- Then ---
+			assert.Nil(t, el)
+			assert.Exactly(t, 0, srid)
+			assert.True(t, errors.Is(err, tc.err), err)
+		})
+	}
+}
+
+func Test_WKB_RoundTrip(t *testing.T) {
+	// --- Given ---
+	el, err := ParseWKT("GEOMETRYCOLLECTION (POINT Z (1 2 3), " +
+		"MULTIPOLYGON (((0 0, 1 0, 1 1, 0 0), (0.1 0.1, 0.2 0.1, 0.2 0.2, 0.1 0.1)), ((5 5, 6 5, 6 6, 5 5))), " +
+		"MULTILINESTRING ((1 2, 3 4)))")
+	require.NoError(t, err)
+
+	// --- When ---
+	data, err := EWKB(el, 4326)
+	require.NoError(t, err)
+	got, srid, err := ParseWKB(data)
+
+	// --- Then ---
+	require.NoError(t, err)
+	assert.Exactly(t, 4326, srid)
+	assert.True(t, el.Equal(got, EqualOptions{}))
+}
@@ -0,0 +1,458 @@
+package kml
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidWKT is returned when Well-Known Text cannot be parsed.
+var ErrInvalidWKT = errors.New("invalid WKT")
+
+// WKT returns OGC Well-Known Text representation of geometry element.
+// Point, LineString, LinearRing, Polygon and MultiGeometry are supported.
+// LinearRing is converted to POLYGON. MultiGeometry with only Points,
+// LineStrings or Polygons is converted to MULTIPOINT, MULTILINESTRING or
+// MULTIPOLYGON, other MultiGeometry to GEOMETRYCOLLECTION. Geometries with
+// non-zero altitudes have Z variants, GEOMETRYCOLLECTION is Z when any of
+// its members is and then all members are Z. For other geometries (Model,
+// gx:Track, gx:MultiTrack) returns *GeometryError wrapping
+// ErrUnsupportedGeometry.
+func WKT(el *Element) (string, error) {
+	g, err := simpleFeature(el)
+	if err != nil {
+		return "", err
+	}
+	return string(appendWKT(nil, g, hasZ(g))), nil
+}
+
+// simpleFeature converts geometry element to geometry representable as
+// OGC simple feature.
+func simpleFeature(el *Element) (*GeoJSONGeometry, error) {
+	var unsupported *Element
+	_ = el.Walk(func(el *Element, _ int) error {
+		switch el.qualifiedName() {
+		case ElemPoint, ElemLineString, ElemLinearRing, ElemPolygon:
+			return SkipSubtree
+		case ElemMultiGeometry:
+			return nil
+		}
+		if isGeometry(el) {
+			unsupported = el
+			return StopWalk
+		}
+		return SkipSubtree
+	})
+	if unsupported != nil {
+		return nil, newGeometryError(unsupported, fmt.Errorf("%w %s", ErrUnsupportedGeometry, unsupported.LocalName()))
+	}
+	return geoJSONGeometry(el)
+}
+
+// hasZ returns true if any of the geometry positions, including positions
+// of geometry collection members, has altitude.
+func hasZ(g *GeoJSONGeometry) bool {
+	for _, sub := range g.Geometries {
+		if hasZ(sub) {
+			return true
+		}
+	}
+	switch crs := g.Coordinates.(type) {
+	case []float64:
+		return len(crs) > 2
+	case [][]float64:
+		for _, pos := range crs {
+			if len(pos) > 2 {
+				return true
+			}
+		}
+	case [][][]float64:
+		for _, line := range crs {
+			if hasZ(&GeoJSONGeometry{Coordinates: line}) {
+				return true
+			}
+		}
+	case [][][][]float64:
+		for _, poly := range crs {
+			if hasZ(&GeoJSONGeometry{Coordinates: poly}) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// wktTypes maps GeoJSON geometry types to WKT geometry types.
+var wktTypes = map[string]string{
+	GeoJSONPoint:              "POINT",
+	GeoJSONMultiPoint:         "MULTIPOINT",
+	GeoJSONLineString:         "LINESTRING",
+	GeoJSONMultiLineString:    "MULTILINESTRING",
+	GeoJSONPolygon:            "POLYGON",
+	GeoJSONMultiPolygon:       "MULTIPOLYGON",
+	GeoJSONGeometryCollection: "GEOMETRYCOLLECTION",
+}
+
+// appendWKT appends WKT representation of geometry to buf. Positions have
+// altitude when z is true, geometry collection members are appended with
+// the same dimension as the collection.
+func appendWKT(buf []byte, g *GeoJSONGeometry, z bool) []byte {
+	buf = append(buf, wktTypes[g.Type]...)
+	if z {
+		buf = append(buf, " Z"...)
+	}
+
+	if g.Type == GeoJSONGeometryCollection {
+		if len(g.Geometries) == 0 {
+			return append(buf, " EMPTY"...)
+		}
+		buf = append(buf, " ("...)
+		for i, sub := range g.Geometries {
+			if i > 0 {
+				buf = append(buf, ", "...)
+			}
+			buf = appendWKT(buf, sub, z)
+		}
+		return append(buf, ')')
+	}
+
+	buf = append(buf, ' ')
+	switch crs := g.Coordinates.(type) {
+	case []float64:
+		buf = append(buf, '(')
+		buf = appendWKTPosition(buf, crs, z)
+		buf = append(buf, ')')
+	case [][]float64:
+		buf = appendWKTPositions(buf, crs, z, g.Type == GeoJSONMultiPoint)
+	case [][][]float64:
+		buf = appendWKTLines(buf, crs, z)
+	case [][][][]float64:
+		buf = append(buf, '(')
+		for i, poly := range crs {
+			if i > 0 {
+				buf = append(buf, ", "...)
+			}
+			buf = appendWKTLines(buf, poly, z)
+		}
+		buf = append(buf, ')')
+	}
+	return buf
+}
+
+// appendWKTPosition appends position values separated by spaces to buf.
+// Altitude is appended only when z is true.
+func appendWKTPosition(buf []byte, pos []float64, z bool) []byte {
+	buf = strconv.AppendFloat(buf, pos[0], 'f', -1, 64)
+	buf = append(buf, ' ')
+	buf = strconv.AppendFloat(buf, pos[1], 'f', -1, 64)
+	if z {
+		var alt float64
+		if len(pos) > 2 {
+			alt = pos[2]
+		}
+		buf = append(buf, ' ')
+		buf = strconv.AppendFloat(buf, alt, 'f', -1, 64)
+	}
+	return buf
+}
+
+// appendWKTPositions appends parenthesized list of positions to buf. When
+// wrap is true each position is parenthesized as well.
+func appendWKTPositions(buf []byte, pos [][]float64, z, wrap bool) []byte {
+	if len(pos) == 0 {
+		return append(buf, "EMPTY"...)
+	}
+	buf = append(buf, '(')
+	for i, p := range pos {
+		if i > 0 {
+			buf = append(buf, ", "...)
+		}
+		if wrap {
+			buf = append(buf, '(')
+		}
+		buf = appendWKTPosition(buf, p, z)
+		if wrap {
+			buf = append(buf, ')')
+		}
+	}
+	return append(buf, ')')
+}
+
+// appendWKTLines appends parenthesized list of position lists to buf.
+func appendWKTLines(buf []byte, lines [][][]float64, z bool) []byte {
+	if len(lines) == 0 {
+		return append(buf, "EMPTY"...)
+	}
+	buf = append(buf, '(')
+	for i, line := range lines {
+		if i > 0 {
+			buf = append(buf, ", "...)
+		}
+		buf = appendWKTPositions(buf, line, z, false)
+	}
+	return append(buf, ')')
+}
+
+// ParseWKT parses OGC Well-Known Text and returns KML geometry element.
+// POINT, LINESTRING and POLYGON are converted to Point, LineString and
+// Polygon, multi geometries and GEOMETRYCOLLECTION to MultiGeometry. Z, M
+// and ZM variants are supported, M values are dropped. The EWKT SRID
+// prefix (SRID=4326;) is accepted and ignored.
+func ParseWKT(s string) (*Element, error) {
+	p := &wktParser{s: s}
+	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(s)), "SRID=") {
+		i := strings.IndexByte(s, ';')
+		if i < 0 {
+			return nil, p.errorf("missing ; after SRID")
+		}
+		p.i = i + 1
+	}
+
+	g, err := p.geometry()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.next(); tok != "" {
+		return nil, p.errorf("unexpected %q", tok)
+	}
+	return kmlGeometry(g)
+}
+
+// wktParser represents WKT parser.
+type wktParser struct {
+	s string // Parsed string.
+	i int    // Current position.
+}
+
+// errorf returns ErrInvalidWKT wrapped with message and current position.
+func (p *wktParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: at %d: %s", ErrInvalidWKT, p.i, fmt.Sprintf(format, args...))
+}
+
+// next returns next token. Tokens are words, numbers and single
+// characters "(", ")" and ",". Returns empty string at the end of input.
+func (p *wktParser) next() string {
+	p.i = skipSpace(p.s, p.i)
+	if p.i == len(p.s) {
+		return ""
+	}
+	start := p.i
+	switch p.s[p.i] {
+	case '(', ')', ',':
+		p.i++
+	default:
+		for p.i < len(p.s) && !isSpace(p.s[p.i]) && !strings.ContainsRune("(),", rune(p.s[p.i])) {
+			p.i++
+		}
+	}
+	return p.s[start:p.i]
+}
+
+// peek returns next token without consuming it.
+func (p *wktParser) peek() string {
+	i := p.i
+	tok := p.next()
+	p.i = i
+	return tok
+}
+
+// expect consumes next token and returns error if it's not tok.
+func (p *wktParser) expect(tok string) error {
+	if got := p.next(); got != tok {
+		return p.errorf("expected %q got %q", tok, got)
+	}
+	return nil
+}
+
+// wktDims describes coordinate dimensions of WKT geometry.
+type wktDims struct {
+	z, m bool
+}
+
+// geometry parses tagged geometry.
+func (p *wktParser) geometry() (*GeoJSONGeometry, error) {
+	word := strings.ToUpper(p.next())
+	var typ string
+	for gt, wt := range wktTypes {
+		if wt == word {
+			typ = gt
+		}
+	}
+	if typ == "" {
+		return nil, p.errorf("unknown geometry type %q", word)
+	}
+
+	var dims wktDims
+	switch strings.ToUpper(p.peek()) {
+	case "Z":
+		dims.z = true
+	case "M":
+		dims.m = true
+	case "ZM":
+		dims.z, dims.m = true, true
+	}
+	if dims.z || dims.m {
+		p.next()
+	}
+
+	g := &GeoJSONGeometry{Type: typ}
+	if strings.ToUpper(p.peek()) == "EMPTY" {
+		p.next()
+		switch typ {
+		case GeoJSONPoint, GeoJSONPolygon:
+			return nil, fmt.Errorf("%w: empty %s", ErrUnsupportedGeometry, word)
+		case GeoJSONMultiPoint, GeoJSONLineString:
+			g.Coordinates = [][]float64{}
+		case GeoJSONMultiLineString:
+			g.Coordinates = [][][]float64{}
+		case GeoJSONMultiPolygon:
+			g.Coordinates = [][][][]float64{}
+		}
+		return g, nil
+	}
+
+	var err error
+	switch typ {
+	case GeoJSONPoint:
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		if g.Coordinates, err = p.position(dims); err != nil {
+			return nil, err
+		}
+		err = p.expect(")")
+
+	case GeoJSONLineString:
+		g.Coordinates, err = p.positions(dims)
+
+	case GeoJSONMultiPoint:
+		g.Coordinates, err = p.multiPoint(dims)
+
+	case GeoJSONPolygon, GeoJSONMultiLineString:
+		g.Coordinates, err = p.lines(dims, typ == GeoJSONPolygon)
+
+	case GeoJSONMultiPolygon:
+		var polys [][][][]float64
+		err = p.list(func() error {
+			poly, err := p.lines(dims, true)
+			polys = append(polys, poly)
+			return err
+		})
+		g.Coordinates = polys
+
+	case GeoJSONGeometryCollection:
+		err = p.list(func() error {
+			sub, err := p.geometry()
+			g.Geometries = append(g.Geometries, sub)
+			return err
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// list parses parenthesized comma separated list calling fn for each item.
+func (p *wktParser) list(fn func() error) error {
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	for {
+		if err := fn(); err != nil {
+			return err
+		}
+		switch tok := p.next(); tok {
+		case ",":
+		case ")":
+			return nil
+		default:
+			return p.errorf("expected \",\" or \")\" got %q", tok)
+		}
+	}
+}
+
+// position parses position values. The M value is dropped.
+func (p *wktParser) position(dims wktDims) ([]float64, error) {
+	var vs []float64
+	for {
+		tok := p.peek()
+		if tok == "" || tok == "," || tok == ")" || tok == "(" {
+			break
+		}
+		p.next()
+		v, err := strconv.ParseFloat(tok, 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %q", tok)
+		}
+		vs = append(vs, v)
+	}
+
+	exp := 2
+	if dims.z {
+		exp++
+	}
+	if dims.m {
+		exp++
+	}
+	if !dims.z && !dims.m && (len(vs) == 3 || len(vs) == 4) {
+		// Untagged Z and ZM positions.
+		exp = len(vs)
+		dims.z, dims.m = true, len(vs) == 4
+	}
+	if len(vs) != exp {
+		return nil, p.errorf("expected %d values got %d", exp, len(vs))
+	}
+	if dims.z {
+		return vs[:3], nil
+	}
+	return vs[:2], nil
+}
+
+// positions parses parenthesized list of positions.
+func (p *wktParser) positions(dims wktDims) ([][]float64, error) {
+	var pos [][]float64
+	err := p.list(func() error {
+		v, err := p.position(dims)
+		pos = append(pos, v)
+		return err
+	})
+	return pos, err
+}
+
+// multiPoint parses MULTIPOINT positions which may be parenthesized.
+func (p *wktParser) multiPoint(dims wktDims) ([][]float64, error) {
+	var pos [][]float64
+	err := p.list(func() error {
+		wrapped := p.peek() == "("
+		if wrapped {
+			p.next()
+		}
+		v, err := p.position(dims)
+		if err != nil {
+			return err
+		}
+		pos = append(pos, v)
+		if wrapped {
+			return p.expect(")")
+		}
+		return nil
+	})
+	return pos, err
+}
+
+// lines parses parenthesized list of position lists. Polygon rings must
+// not be empty.
+func (p *wktParser) lines(dims wktDims, rings bool) ([][][]float64, error) {
+	var lines [][][]float64
+	err := p.list(func() error {
+		if rings && strings.ToUpper(p.peek()) == "EMPTY" {
+			return fmt.Errorf("%w: empty ring", ErrUnsupportedGeometry)
+		}
+		line, err := p.positions(dims)
+		lines = append(lines, line)
+		return err
+	})
+	return lines, err
+}
@@ -0,0 +1,189 @@
+package kml
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WKT(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		el  *Element
+		exp string
+	}{
+		{"point", Point(Coordinates("1.5,2.5")), "POINT (1.5 2.5)"},
+		{"point z", Point(Coordinates("1,2,3")), "POINT Z (1 2 3)"},
+		{"line", LineString(Coordinates("1,2 3,4")), "LINESTRING (1 2, 3 4)"},
+		{"ring", LinearRing(Coordinates("0,0 1,0 1,1 0,0")), "POLYGON ((0 0, 1 0, 1 1, 0 0))"},
+		{"polygon", Polygon(
+			OuterBoundaryIs(LinearRing(Coordinates("0,0 10,0 10,10 0,0"))),
+			InnerBoundaryIs(LinearRing(Coordinates("1,1 2,1 2,2 1,1"))),
+		), "POLYGON ((0 0, 10 0, 10 10, 0 0), (1 1, 2 1, 2 2, 1 1))"},
+		{"multi point", MultiGeometry(
+			Point(Coordinates("1,2")),
+			Point(Coordinates("3,4,5")),
+		), "MULTIPOINT Z ((1 2 0), (3 4 5))"},
+		{"multi line", MultiGeometry(
+			LineString(Coordinates("1,2 3,4")),
+			LineString(Coordinates("5,6 7,8")),
+		), "MULTILINESTRING ((1 2, 3 4), (5 6, 7 8))"},
+		{"multi polygon", MultiGeometry(
+			Polygon(OuterBoundaryIs(LinearRing(Coordinates("0,0 1,0 1,1 0,0")))),
+			Polygon(OuterBoundaryIs(LinearRing(Coordinates("5,5 6,5 6,6 5,5")))),
+		), "MULTIPOLYGON (((0 0, 1 0, 1 1, 0 0)), ((5 5, 6 5, 6 6, 5 5)))"},
+		{"collection", MultiGeometry(
+			Point(Coordinates("1,1")),
+			LineString(Coordinates("1,1 2,2")),
+		), "GEOMETRYCOLLECTION (POINT (1 1), LINESTRING (1 1, 2 2))"},
+		{"collection z", MultiGeometry(
+			Point(Coordinates("1,1,5")),
+			LineString(Coordinates("1,1 2,2")),
+		), "GEOMETRYCOLLECTION Z (POINT Z (1 1 5), LINESTRING Z (1 1 0, 2 2 0))"},
+		{"nested collection z", MultiGeometry(
+			MultiGeometry(Point(Coordinates("1,1,5")), LineString(Coordinates("1,1 2,2"))),
+			Point(Coordinates("3,3")),
+		), "GEOMETRYCOLLECTION Z (GEOMETRYCOLLECTION Z (POINT Z (1 1 5), LINESTRING Z (1 1 0, 2 2 0)), POINT Z (3 3 0))"},
+		{"empty", MultiGeometry(), "GEOMETRYCOLLECTION EMPTY"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			got, err := WKT(tc.el)
+
+			// --- Then ---
+			require.NoError(t, err)
+			assert.Exactly(t, tc.exp, got)
+		})
+	}
+}
+
+func Test_WKT_Unsupported(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		el   *Element
+		name string
+	}{
+		{"model", Model(), ElemModel},
+		{"track", GxTrack(GxCoord("1 2 3")), "Track"},
+		{"nested", MultiGeometry(Point(Coordinates("1,2")), GxMultiTrack()), "MultiTrack"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			got, err := WKT(tc.el)
+
+			// --- Then ---
+			assert.Exactly(t, "", got)
+			assert.ErrorIs(t, err, ErrUnsupportedGeometry)
+			var ge *GeometryError
+			require.True(t, errors.As(err, &ge), err)
+			assert.Exactly(t, tc.name, ge.Name)
+		})
+	}
+}
+
+func Test_ParseWKT(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		wkt string
+		exp string
+	}{
+		{"point", "POINT (1.5 2.5)",
+			"<Point><coordinates>1.5,2.5</coordinates></Point>"},
+		{"point z", "point z(1 2 3)",
+			"<Point><coordinates>1,2,3</coordinates></Point>"},
+		{"point m", "POINT M (1 2 9)",
+			"<Point><coordinates>1,2</coordinates></Point>"},
+		{"point zm", "POINT ZM (1 2 3 9)",
+			"<Point><coordinates>1,2,3</coordinates></Point>"},
+		{"point untagged z", "POINT (1 2 3)",
+			"<Point><coordinates>1,2,3</coordinates></Point>"},
+		{"ewkt", "SRID=4326;POINT (1 2)",
+			"<Point><coordinates>1,2</coordinates></Point>"},
+		{"line", "LINESTRING (1 2, 3 4)",
+			"<LineString><coordinates>1,2 3,4</coordinates></LineString>"},
+		{"polygon", "POLYGON ((0 0, 1 0, 1 1, 0 0), (0.1 0.1, 0.2 0.1, 0.2 0.2, 0.1 0.1))",
+			"<Polygon>" +
+				"<outerBoundaryIs><LinearRing><coordinates>0,0 1,0 1,1 0,0</coordinates></LinearRing></outerBoundaryIs>" +
+				"<innerBoundaryIs><LinearRing><coordinates>0.1,0.1 0.2,0.1 0.2,0.2 0.1,0.1</coordinates></LinearRing></innerBoundaryIs>" +
+				"</Polygon>"},
+		{"multi point", "MULTIPOINT (1 2, (3 4))",
+			"<MultiGeometry>" +
+				"<Point><coordinates>1,2</coordinates></Point>" +
+				"<Point><coordinates>3,4</coordinates></Point>" +
+				"</MultiGeometry>"},
+		{"collection", "GEOMETRYCOLLECTION (POINT (1 1), MULTILINESTRING ((1 1, 2 2)))",
+			"<MultiGeometry>" +
+				"<Point><coordinates>1,1</coordinates></Point>" +
+				"<MultiGeometry><LineString><coordinates>1,1 2,2</coordinates></LineString></MultiGeometry>" +
+				"</MultiGeometry>"},
+		{"empty", "MULTIPOLYGON EMPTY", "<MultiGeometry></MultiGeometry>"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			el, err := ParseWKT(tc.wkt)
+
+			// --- Then ---
+			require.NoError(t, err)
+			got, err := xml.Marshal(el)
+			require.NoError(t, err)
+			assert.Exactly(t, tc.exp, string(got))
+		})
+	}
+}
+
+func Test_ParseWKT_Errors(t *testing.T) {
+	tt := []struct {
+		testN string
+
+		wkt string
+		err error
+	}{
+		{"empty string", "", ErrInvalidWKT},
+		{"type", "CIRCLE (1 2)", ErrInvalidWKT},
+		{"missing paren", "POINT (1 2", ErrInvalidWKT},
+		{"number", "POINT (1 x)", ErrInvalidWKT},
+		{"dimensions", "POINT Z (1 2)", ErrInvalidWKT},
+		{"trailing", "POINT (1 2) x", ErrInvalidWKT},
+		{"srid", "SRID=4326 POINT (1 2)", ErrInvalidWKT},
+		{"empty point", "POINT EMPTY", ErrUnsupportedGeometry},
+		{"empty polygon", "POLYGON EMPTY", ErrUnsupportedGeometry},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.testN, func(t *testing.T) {
+			// --- When ---
+			el, err := ParseWKT(tc.wkt)
+
+			// --- Then ---
+			assert.Nil(t, el)
+			assert.True(t, errors.Is(err, tc.err), err)
+		})
+	}
+}
+
+func Test_WKT_RoundTrip(t *testing.T) {
+	// --- Given ---
+	exp := "GEOMETRYCOLLECTION Z (POINT Z (1 2 3), " +
+		"MULTIPOLYGON Z (((0 0 0, 1 0 0, 1 1 0, 0 0 0), (0.1 0.1 0, 0.2 0.1 0, 0.2 0.2 0, 0.1 0.1 0)), ((5 5 0, 6 5 0, 6 6 0, 5 5 0))))"
+
+	// --- When ---
+	el, err := ParseWKT(exp)
+	require.NoError(t, err)
+	got, err := WKT(el)
+
+	// --- Then ---
+	require.NoError(t, err)
+	assert.Exactly(t, exp, got)
+}
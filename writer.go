@@ -0,0 +1,156 @@
+package kml
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+)
+
+// ErrUnbalanced is returned by Writer when containers are not balanced.
+var ErrUnbalanced = errors.New("unbalanced containers")
+
+// ErrWriterClosed is returned when writing to closed Writer.
+var ErrWriterClosed = errors.New("writer closed")
+
+// ErrNilElement is returned when nil element is passed to Writer.
+var ErrNilElement = errors.New("nil element")
+
+// Writer writes KML document incrementally. Features are encoded as soon
+// as they are passed to the Writer so the whole document never has to be
+// kept in memory.
+type Writer struct {
+	enc    *xml.Encoder
+	root   *Element
+	rootSE xml.StartElement // Encoded root start element.
+	open   []openContainer
+	start  bool // Set to true when prolog and root element were written.
+	closed bool // Set to true when Writer was closed.
+}
+
+// openContainer represents container written by OpenContainer.
+type openContainer struct {
+	se    xml.StartElement // Encoded start element.
+	scope *nsScope         // Namespace scope of container's children.
+}
+
+// NewWriter returns new instance of Writer writing to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		enc:  xml.NewEncoder(w),
+		root: KML(),
+	}
+}
+
+// Indent sets the indentation the same way xml.Encoder.Indent does.
+// It must be called before anything is written.
+func (w *Writer) Indent(prefix, indent string) {
+	w.enc.Indent(prefix, indent)
+}
+
+// writeStart writes XML prolog and kml root start element if they were
+// not written yet.
+func (w *Writer) writeStart() error {
+	if w.closed {
+		return ErrWriterClosed
+	}
+	if w.start {
+		return nil
+	}
+	w.start = true
+	if err := encodeProlog(w.enc); err != nil {
+		return err
+	}
+	var scope *nsScope
+	w.rootSE, scope = newNSScope().start(w.root)
+	w.open = []openContainer{{se: w.rootSE, scope: scope}}
+	return w.enc.EncodeToken(w.rootSE)
+}
+
+// scope returns namespace scope of the most recently opened container.
+func (w *Writer) scope() *nsScope {
+	return w.open[len(w.open)-1].scope
+}
+
+// OpenContainer writes start element of container (Document or Folder)
+// and all its child elements. Container stays open until CloseContainer
+// is called. Child elements are encoded the same way Encode does.
+func (w *Writer) OpenContainer(el *Element) error {
+	if el == nil {
+		return ErrNilElement
+	}
+	if err := w.writeStart(); err != nil {
+		return err
+	}
+	se, scope := w.scope().start(el)
+	if err := w.enc.EncodeToken(se); err != nil {
+		return err
+	}
+	for _, ch := range el.children {
+		if err := ch.encodeKept(w.enc, scope); err != nil {
+			return err
+		}
+	}
+	w.open = append(w.open, openContainer{se: se, scope: scope})
+	return w.enc.Flush()
+}
+
+// CloseContainer writes end element of the most recently opened container.
+func (w *Writer) CloseContainer() error {
+	if err := w.writeStart(); err != nil {
+		return err
+	}
+	if len(w.open) < 2 {
+		return ErrUnbalanced
+	}
+	oc := w.open[len(w.open)-1]
+	w.open = w.open[:len(w.open)-1]
+	if err := w.enc.EncodeToken(oc.se.End()); err != nil {
+		return err
+	}
+	return w.enc.Flush()
+}
+
+// Encode writes element to the currently open container. Comments and
+// formatting kept in the element tree and unmodified elements returned by
+// ParseLossless are written the same way MarshalXML writes them, tokens
+// before and after the element itself are not written.
+func (w *Writer) Encode(el *Element) error {
+	if el == nil {
+		return ErrNilElement
+	}
+	if err := w.writeStart(); err != nil {
+		return err
+	}
+	if err := el.encodeKept(w.enc, w.scope()); err != nil {
+		return err
+	}
+	return w.enc.Flush()
+}
+
+// Close closes all open containers and the kml root element. It returns
+// ErrUnbalanced if any containers were left open. Writer cannot be used
+// after Close is called.
+func (w *Writer) Close() error {
+	if err := w.writeStart(); err != nil {
+		return err
+	}
+
+	var err error
+	if len(w.open) > 1 {
+		err = ErrUnbalanced
+	}
+	for len(w.open) > 1 {
+		if cErr := w.CloseContainer(); cErr != nil {
+			return cErr
+		}
+	}
+	w.closed = true
+
+	if eErr := w.enc.EncodeToken(w.rootSE.End()); eErr != nil {
+		return eErr
+	}
+	if fErr := w.enc.Flush(); fErr != nil {
+		return fErr
+	}
+	return err
+}
@@ -0,0 +1,146 @@
+package kml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	kit "github.com/rzajac/testkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Writer(t *testing.T) {
+	// --- Given ---
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	w.Indent("", "  ")
+
+	// --- When ---
+	require.NoError(t, w.OpenContainer(Document(
+		Name("document name"),
+		Style(
+			"sty_0",
+			LabelStyle(Color("01020304"), Scale(1)),
+			LineStyle(Color("05060708"), Width(3.5)),
+			PolyStyle(Color("090a0b0c"), Outline(true)),
+		),
+	)))
+	require.NoError(t, w.OpenContainer(Folder(
+		AttrID("fld_0"),
+		Name("folder name"),
+		Snippet("snip", AttrMaxLines(1)),
+	)))
+	require.NoError(t, w.Encode(Placemark(
+		AttrID("pm_0"),
+		Name("placemark name"),
+		Description("<b>placemark description</b>"),
+		StyleURL("#sty_0"),
+		MultiGeometry(
+			LineString(
+				AttrID("ls_0"),
+				Tessellate(true),
+				Coordinates("0.1,0.2,0.3 1.1,1.2,1.3"),
+			),
+		),
+	)))
+	require.NoError(t, w.CloseContainer())
+	require.NoError(t, w.CloseContainer())
+	err := w.Close()
+
+	// --- Then ---
+	assert.NoError(t, err)
+	exp := kit.ReadAll(t, kit.OpenFile(t, "testdata/example.kml"))
+	assert.Exactly(t, string(exp), buf.String())
+}
+
+func Test_Writer_SameAsMarshal(t *testing.T) {
+	// --- Given ---
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+
+	// --- When ---
+	require.NoError(t, w.OpenContainer(Document()))
+	require.NoError(t, w.Encode(Placemark(Name("pm"))))
+	require.NoError(t, w.CloseContainer())
+	require.NoError(t, w.Close())
+
+	// --- Then ---
+	exp, err := xml.Marshal(KML(Document(Placemark(Name("pm")))))
+	require.NoError(t, err)
+	assert.Exactly(t, string(exp), buf.String())
+}
+
+func Test_Writer_Encode_KeptTokens(t *testing.T) {
+	// --- Given ---
+	src := `<kml><Document><Placemark><!-- pin --><name>pm</name></Placemark></Document></kml>`
+	root, err := ParseLossless(bytes.NewReader([]byte(src)))
+	require.NoError(t, err)
+	pm := root.FindFirst(ElemPlacemark)
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+
+	// --- When ---
+	require.NoError(t, w.OpenContainer(Document()))
+	require.NoError(t, w.Encode(pm))
+	require.NoError(t, w.CloseContainer())
+	require.NoError(t, w.Close())
+
+	// --- Then ---
+	exp, err := xml.Marshal(KML(Document()))
+	require.NoError(t, err)
+	want := strings.Replace(string(exp), "</Document>", `<Placemark><!-- pin --><name>pm</name></Placemark></Document>`, 1)
+	assert.Exactly(t, want, buf.String())
+}
+
+func Test_Writer_NilElement(t *testing.T) {
+	// --- Given ---
+	w := NewWriter(&bytes.Buffer{})
+
+	// --- Then ---
+	assert.ErrorIs(t, w.Encode(nil), ErrNilElement)
+	assert.ErrorIs(t, w.OpenContainer(nil), ErrNilElement)
+	assert.NoError(t, w.Close())
+}
+
+func Test_Writer_Close_Unbalanced(t *testing.T) {
+	// --- Given ---
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	require.NoError(t, w.OpenContainer(Document()))
+	require.NoError(t, w.OpenContainer(Folder()))
+
+	// --- When ---
+	err := w.Close()
+
+	// --- Then ---
+	assert.ErrorIs(t, err, ErrUnbalanced)
+	exp := `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2" xmlns:gx="http://www.google.com/kml/ext/2.2" xmlns:kml="http://www.opengis.net/kml/2.2" xmlns:atom="http://www.w3.org/2005/Atom"><Document><Folder></Folder></Document></kml>`
+	assert.Exactly(t, exp, buf.String())
+}
+
+func Test_Writer_CloseContainer_Unbalanced(t *testing.T) {
+	// --- Given ---
+	w := NewWriter(&bytes.Buffer{})
+
+	// --- When ---
+	err := w.CloseContainer()
+
+	// --- Then ---
+	assert.ErrorIs(t, err, ErrUnbalanced)
+}
+
+func Test_Writer_Closed(t *testing.T) {
+	// --- Given ---
+	w := NewWriter(&bytes.Buffer{})
+	require.NoError(t, w.Close())
+
+	// --- When ---
+	err := w.Encode(Placemark())
+
+	// --- Then ---
+	assert.ErrorIs(t, err, ErrWriterClosed)
+	assert.ErrorIs(t, w.Close(), ErrWriterClosed)
+}